@@ -61,12 +61,17 @@ void app_main(void)
         esp_err_t recv_ret = twai_receive(&message, pdMS_TO_TICKS(1000));
         if (recv_ret == ESP_OK) {
             // Send raw message: [4 bytes ID][1 byte DLC][0-8 bytes data]
+            // Bit 31 of the ID marks a 29-bit extended frame (DBC convention).
+            uint32_t id = message.identifier;
+            if (message.extd) {
+                id |= 0x80000000;
+            }
             uint8_t tx_buf[13];  // max 4 + 1 + 8
             memset(tx_buf, 0, sizeof(tx_buf));
-            tx_buf[0] = (message.identifier >> 24) & 0xFF;
-            tx_buf[1] = (message.identifier >> 16) & 0xFF;
-            tx_buf[2] = (message.identifier >> 8) & 0xFF;
-            tx_buf[3] = (message.identifier) & 0xFF;
+            tx_buf[0] = (id >> 24) & 0xFF;
+            tx_buf[1] = (id >> 16) & 0xFF;
+            tx_buf[2] = (id >> 8) & 0xFF;
+            tx_buf[3] = (id) & 0xFF;
             tx_buf[4] = message.data_length_code;
             memcpy(&tx_buf[5], message.data, message.data_length_code);
 
@@ -9,6 +9,7 @@ import (
 	"math"
 	"os"
 	"strings"
+	"time"
 
 	"telem-system/internal/config"
 	"telem-system/pkg/candecoder"
@@ -19,6 +20,10 @@ import (
 
 var seq uint64 = 0
 
+// batchSize is the number of frames per message when the receiver accepts
+// the batched frame subprotocol.
+const batchSize = 32
+
 func main() {
 	// Load configuration
 	cfg, err := config.LoadConfig("../../configs/", "config", "yaml")
@@ -30,8 +35,13 @@ func main() {
 	telemetryURL := fmt.Sprintf("ws://%s:%d/telemetry", cfg.WebSocket.IP, cfg.WebSocket.Port)
 	log.Printf("Simulated data sender connecting to %s in mode: %s", telemetryURL, cfg.Mode)
 
-	// Dial the receiver's telemetry WebSocket endpoint.
-	conn, _, err := websocket.DefaultDialer.Dial(telemetryURL, nil)
+	// Dial the receiver's telemetry WebSocket endpoint. Live frames are sent
+	// in batches if the receiver accepts the subprotocol, as hex otherwise.
+	dialer := *websocket.DefaultDialer
+	if cfg.Mode == "live" {
+		dialer.Subprotocols = []string{candecoder.FrameBatchSubprotocol}
+	}
+	conn, _, err := dialer.Dial(telemetryURL, nil)
 	if err != nil {
 		log.Fatalf("Dial error: %v", err)
 	}
@@ -63,10 +73,8 @@ func sendCSV(conn *websocket.Conn) {
 	for scanner.Scan() {
 		line := scanner.Text()
 		lineCount++
-		// Skip header lines if needed (e.g., first 8 lines)
-		if lineCount <= 8 {
-			continue
-		}
+		// Header and preamble lines are sent too; the receiver reads the
+		// column layout from the header row and skips lines it cannot parse.
 		if err := conn.WriteMessage(websocket.TextMessage, []byte(line)); err != nil {
 			log.Printf("Error sending CSV line: %v", err)
 			return
@@ -84,10 +92,15 @@ func sendCSV(conn *websocket.Conn) {
 
 // sendLive sends simulated live CAN packets over the WebSocket connection.
 func sendLive(conn *websocket.Conn, cfg *config.Config) {
-	// Load JSON definitions.
-	messages, _, err := candecoder.LoadJSONDefinitions(cfg.JSONFile)
+	// Load CAN definitions.
+	messages, _, err := candecoder.LoadDefinitions(cfg.DefinitionsFile())
 	if err != nil {
-		log.Fatalf("Error loading JSON definitions: %v", err)
+		log.Fatalf("Error loading CAN definitions: %v", err)
+	}
+
+	if conn.Subprotocol() == candecoder.FrameBatchSubprotocol {
+		sendLiveBatches(conn, messages)
+		return
 	}
 
 	// Round-robin loop over all message definitions.
@@ -105,6 +118,31 @@ func sendLive(conn *websocket.Conn, cfg *config.Config) {
 	}
 }
 
+// sendLiveBatches sends the same simulated frames as CANFrameBatch messages
+// of batchSize frames, timestamped as they are generated.
+func sendLiveBatches(conn *websocket.Conn, messages []types.Message) {
+	var buf []byte
+	frames := make([]candecoder.Frame, 0, batchSize)
+	i := 0
+	for batch := uint64(0); ; batch++ {
+		frames = frames[:0]
+		for len(frames) < batchSize {
+			packet := generateValidCANPacket(messages[i])
+			frames = append(frames, candecoder.Frame{Time: time.Now(), ID: messages[i].Key(), Data: packet[4:]})
+			i = (i + 1) % len(messages)
+		}
+		var err error
+		if buf, err = candecoder.AppendFrameBatch(buf[:0], batch, frames); err != nil {
+			log.Printf("Error encoding CAN frame batch: %v", err)
+			return
+		}
+		if err := conn.WriteMessage(websocket.BinaryMessage, buf); err != nil {
+			log.Printf("Error sending CAN frame batch: %v", err)
+			return
+		}
+	}
+}
+
 // generateValidCANPacket creates a CAN packet with sequential values.
 func generateValidCANPacket(msg types.Message) []byte {
 	data := make([]byte, msg.Length)
@@ -132,9 +170,9 @@ func generateValidCANPacket(msg types.Message) []byte {
 		packBits(data, uint64(signal.Start), uint64(signal.Length), rawValue, signal.ByteOrder)
 	}
 
-	// Prepend the frame ID (4 bytes in big-endian)
+	// Prepend the frame ID (4 bytes in big-endian, bit 31 set for extended frames)
 	packet := make([]byte, 4+msg.Length)
-	binary.BigEndian.PutUint32(packet[:4], msg.FrameID)
+	binary.BigEndian.PutUint32(packet[:4], uint32(msg.Key()))
 	copy(packet[4:], data)
 	return packet
 }
@@ -166,22 +204,18 @@ func packBitsLittleEndian(data []byte, startBit, length, value uint64) {
 	}
 }
 
+// packBitsBigEndian writes value as a Motorola signal whose MSB sits at DBC
+// bit startBit, following the same sawtooth bit order the decoder reads.
 func packBitsBigEndian(data []byte, startBit, length, value uint64) {
-	bitsRemaining := length
-	currentBit := startBit
-	for bitsRemaining > 0 {
-		byteIndex := currentBit / 8
-		bitOffset := currentBit % 8
-		availableBits := 8 - bitOffset
-		bitsToWrite := availableBits
-		if bitsToWrite > bitsRemaining {
-			bitsToWrite = bitsRemaining
+	pos := startBit
+	for i := length; i > 0; i-- {
+		bit := (value >> (i - 1)) & 1
+		data[pos/8] |= byte(bit << (pos % 8))
+		if pos%8 == 0 {
+			pos += 15
+		} else {
+			pos--
 		}
-		mask := uint64((1 << bitsToWrite) - 1)
-		shiftedValue := (value >> (length - bitsRemaining)) & mask
-		data[byteIndex] |= byte(shiftedValue << (availableBits - bitsToWrite - bitOffset))
-		currentBit += bitsToWrite
-		bitsRemaining -= bitsToWrite
 	}
 }
 
@@ -0,0 +1,61 @@
+// main.go
+// dbc-lint checks CAN definitions (DBC or JSON) for structural problems and
+// verifies that every frame ID routed by processdata.HandleDataInsertions is
+// defined. It exits with status 1 if any problem is found.
+//
+// Usage: dbc-lint [-handled=false] [definitions file]
+// Without a file argument the definitions configured in configs/config.yaml
+// are checked.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"telem-system/internal/config"
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/processdata"
+)
+
+func main() {
+	log.SetFlags(0)
+	checkHandled := flag.Bool("handled", true, "check that every frame ID routed by processdata is defined")
+	flag.Parse()
+
+	path := flag.Arg(0)
+	if path == "" {
+		cfg, err := config.LoadConfig("../../configs/", "config", "yaml")
+		if err != nil {
+			log.Fatalf("Error loading config: %v", err)
+		}
+		path = cfg.DefinitionsFile()
+	}
+
+	messages, messageMap, err := candecoder.LoadDefinitions(path)
+	if err != nil {
+		log.Fatalf("Failed to load CAN definitions: %v", err)
+	}
+
+	var problems []string
+	for _, issue := range candecoder.Lint(messages) {
+		problems = append(problems, issue.String())
+	}
+	if *checkHandled {
+		for _, id := range processdata.HandledFrameIDs() {
+			if _, ok := messageMap[id]; !ok {
+				problems = append(problems, fmt.Sprintf("frame ID %d (%s) is handled in HandleDataInsertions but not defined", id.ID(), id))
+			}
+		}
+	}
+
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%s: %d problem(s) in %d messages\n", path, len(problems), len(messages))
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d messages OK\n", path, len(messages))
+}
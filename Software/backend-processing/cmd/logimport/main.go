@@ -0,0 +1,203 @@
+// main.go
+// logimport decodes recorded CAN logs and stores them through the same
+// processdata path as live telemetry. Records are stamped with the log's own
+// timestamps, and every frame is decoded with the definitions version that was
+// in effect for the vehicle when it was recorded.
+//
+// Usage: logimport [-speed factor] [-vehicle name] file...
+// Supported formats: candump -l logs (.log), Vector ASCII (.asc) and binary
+// (.blf) logs, and Kvaser CSV exports (.csv). ASC logs without a date header
+// are timed from the moment of the import. With
+// -speed 0 frames are imported as fast as possible; -speed 1 replays them at
+// the recorded rate.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"telem-system/internal/config"
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/db"
+	"telem-system/pkg/processdata"
+	"telem-system/pkg/types"
+)
+
+// logFormat returns the source name of a log file from its extension.
+func logFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".log":
+		return "candump", nil
+	case ".asc":
+		return "asc", nil
+	case ".blf":
+		return "blf", nil
+	case ".csv":
+		return "kvaser", nil
+	}
+	return "", fmt.Errorf("unsupported log format '%s'", filepath.Ext(path))
+}
+
+// openLog returns a frame reader for the log in r.
+func openLog(format string, r io.Reader) (candecoder.FrameReader, error) {
+	switch format {
+	case "candump":
+		return candecoder.NewCandumpReader(r), nil
+	case "asc":
+		a := candecoder.NewASCReader(r)
+		a.Fallback = time.Now()
+		return a, nil
+	case "blf":
+		return candecoder.NewBLFReader(r)
+	case "kvaser":
+		return candecoder.NewKvaserReader(r), nil
+	}
+	return nil, fmt.Errorf("unsupported log format '%s'", format)
+}
+
+// importer feeds log frames through processdata and records a session per
+// imported file.
+type importer struct {
+	vehicle         string
+	speed           float64
+	current         *candecoder.Definitions
+	versions        *candecoder.Versions
+	cellDataBuffers map[float64]*types.Cell_Data
+	frame           candecoder.DecodedFrame
+}
+
+// importFile decodes and stores every frame of one log file.
+func (im *importer) importFile(path string) error {
+	format, err := logFormat(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader, err := openLog(format, file)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	sessionHash := im.current.Hash
+	sessionID, err := db.StartSession(ctx, im.vehicle, format, sessionHash)
+	if err != nil {
+		return fmt.Errorf("record session: %v", err)
+	}
+	defer db.EndSession(ctx, sessionID)
+
+	var frames, stored, unknown, malformed int
+	var first, start time.Time
+	for {
+		f, err := reader.ReadFrame()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, candecoder.ErrLogSyntax) {
+			malformed++
+			if malformed <= 10 {
+				log.Printf("%s: %v", path, err)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		frames++
+
+		if im.speed > 0 {
+			if first.IsZero() {
+				first, start = f.Time, time.Now()
+			}
+			time.Sleep(time.Until(start.Add(time.Duration(float64(f.Time.Sub(first)) / im.speed))))
+		}
+
+		defs := im.versions.Resolve(im.vehicle, f.Time, im.current)
+		if defs.Hash != sessionHash {
+			sessionHash = defs.Hash
+			if err := db.SetSessionDefinitions(ctx, sessionID, sessionHash); err != nil {
+				log.Printf("Failed to update session definitions: %v", err)
+			}
+		}
+		plan, exists := defs.Plans[f.ID]
+		if !exists {
+			unknown++
+			continue
+		}
+		if err := plan.Decode(f.Data, &im.frame); err != nil {
+			continue
+		}
+		im.frame.Timestamp = f.Time
+		processdata.HandleDataInsertions(f.ID, &im.frame, im.cellDataBuffers, 0, format)
+		stored++
+	}
+	processdata.HandleRemainingCellData(im.cellDataBuffers)
+	clear(im.cellDataBuffers)
+
+	log.Printf("%s: %d frames, %d decoded, %d unknown ID, %d malformed lines (session %d, definitions %.12s)",
+		path, frames, stored, unknown, malformed, sessionID, sessionHash)
+	return nil
+}
+
+func main() {
+	speed := flag.Float64("speed", 0, "replay rate relative to the recording (0 imports as fast as possible)")
+	vehicle := flag.String("vehicle", "", "vehicle the logs were recorded on (default from config)")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: logimport [-speed factor] [-vehicle name] file...")
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig("../../configs/", "config", "yaml")
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if *vehicle == "" {
+		*vehicle = cfg.Vehicle
+	}
+
+	dbPool, err := db.Connect(cfg.Database.ConnectionString)
+	if err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+	defer dbPool.Close()
+
+	current, err := candecoder.LoadDefinitionSet(cfg.DefinitionsFile())
+	if err != nil {
+		log.Fatalf("Failed to load CAN definitions: %v", err)
+	}
+	history, err := db.New(dbPool).FetchDefinitionVersions(context.Background(), *vehicle)
+	if err != nil {
+		log.Fatalf("Failed to load definition versions: %v", err)
+	}
+	rangePolicy, err := candecoder.ParseRangePolicy(cfg.RangePolicy)
+	if err != nil {
+		log.Fatalf("Invalid range_policy: %v", err)
+	}
+	candecoder.SetRangePolicy(rangePolicy)
+
+	im := &importer{
+		vehicle:         *vehicle,
+		speed:           *speed,
+		current:         current,
+		versions:        candecoder.NewVersions(history),
+		cellDataBuffers: make(map[float64]*types.Cell_Data),
+	}
+	for _, path := range flag.Args() {
+		if err := im.importFile(path); err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/types"
+)
+
+func TestOpenLogSkipsNonDataFrames(t *testing.T) {
+	logs := map[string]string{
+		"bench.log": "(1731759855.316080) can0 065#01\n(1731759855.316090) can0 065#R\n(1731759855.316100) can0 20000080#00\n(1731759855.317080) can0 066#02\n",
+		"trace.ASC": "base hex timestamps absolute\n0.5 1 65 Rx d 1 01\n0.6 1 ErrorFrame\n0.7 1 65 Rx r\n1.5 1 66 Rx d 1 02\n",
+	}
+	base := time.Unix(1731759855, 0)
+	for name, src := range logs {
+		format, err := logFormat(name)
+		if err != nil {
+			t.Fatalf("logFormat(%s): %v", name, err)
+		}
+		reader, err := openLog(format, strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("openLog(%s): %v", name, err)
+		}
+		if a, ok := reader.(*candecoder.ASCReader); ok {
+			a.Fallback = base
+		}
+		var ids []types.CANID
+		var times []time.Time
+		for {
+			f, err := reader.ReadFrame()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			ids = append(ids, f.ID)
+			times = append(times, f.Time)
+		}
+		if len(ids) != 2 || ids[0] != 0x65 || ids[1] != 0x66 {
+			t.Errorf("%s: frames %v", name, ids)
+			continue
+		}
+		if gap := times[1].Sub(times[0]); gap != time.Millisecond && gap != time.Second {
+			t.Errorf("%s: frames %v apart", name, gap)
+		}
+	}
+	if _, err := logFormat("data.mf4"); err == nil {
+		t.Error("logFormat accepted an MDF file")
+	}
+}
@@ -0,0 +1,132 @@
+// main.go
+// sessionexport writes a session or time range of stored telemetry to a
+// measurement file, with signal names, units and choices taken from the CAN
+// definitions the data was decoded with.
+//
+// Usage: sessionexport mdf4|ld [-session id | -from time -to time] [-vehicle name] [-rate Hz] [-o file]
+// mdf4 writes ASAM MDF4 with every stored sample; ld writes a MoTeC i2 log
+// with every channel resampled at -rate (default ld_sample_rate) and the
+// driver, venue and event of the session in its header. Times are RFC 3339.
+// Without -o the file is named after the session or the start of the range.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"telem-system/internal/config"
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/db"
+	"telem-system/pkg/export"
+)
+
+const usage = "usage: sessionexport mdf4|ld [-session id | -from time -to time] [-vehicle name] [-rate Hz] [-o file]"
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	format := os.Args[1]
+	var ext string
+	switch format {
+	case "mdf4":
+		ext = ".mf4"
+	case "ld":
+		ext = ".ld"
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format '%s'\n%s\n", format, usage)
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(format, flag.ExitOnError)
+	session := fs.Int64("session", 0, "session to export")
+	fromStr := fs.String("from", "", "start of the time range (RFC 3339)")
+	toStr := fs.String("to", "", "end of the time range (RFC 3339)")
+	vehicle := fs.String("vehicle", "", "vehicle whose definitions name the signals (default from config)")
+	rate := fs.Int("rate", 0, "ld sample rate in Hz (default ld_sample_rate from config)")
+	out := fs.String("o", "", "output file")
+	fs.Parse(os.Args[2:])
+
+	cfg, err := config.LoadConfig("../../configs/", "config", "yaml")
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if *vehicle == "" {
+		*vehicle = cfg.Vehicle
+	}
+	if *rate == 0 {
+		*rate = cfg.LDSampleRate
+	}
+	if *rate == 0 {
+		*rate = export.DefaultLDSampleRate
+	}
+	dbPool, err := db.Connect(cfg.Database.ConnectionString)
+	if err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+	defer dbPool.Close()
+	queries := db.New(dbPool)
+
+	ctx := context.Background()
+	current, err := candecoder.LoadDefinitionSet(cfg.DefinitionsFile())
+	if err != nil {
+		log.Fatalf("Failed to load CAN definitions: %v", err)
+	}
+	history, err := queries.FetchDefinitionVersions(ctx, *vehicle)
+	if err != nil {
+		log.Fatalf("Failed to load definition versions: %v", err)
+	}
+	versions := candecoder.NewVersions(history)
+
+	var sel export.Selection
+	if *session != 0 {
+		sel, err = export.SelectSession(ctx, queries, versions, *session, current)
+	} else {
+		var from, to time.Time
+		if from, err = time.Parse(time.RFC3339, *fromStr); err != nil {
+			log.Fatalf("Invalid -from: %v", err)
+		}
+		if to, err = time.Parse(time.RFC3339, *toStr); err != nil {
+			log.Fatalf("Invalid -to: %v", err)
+		}
+		sel, err = export.SelectRange(versions, *vehicle, from, to, current)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var groups []export.Group
+	if format == "ld" {
+		groups, err = export.LoadResampled(ctx, queries, sel, *rate)
+	} else {
+		groups, err = export.Load(ctx, queries, sel)
+	}
+	if err != nil {
+		log.Fatalf("Failed to read telemetry: %v", err)
+	}
+	if *out == "" {
+		*out = sel.FileName(ext)
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if format == "ld" {
+		err = export.WriteLD(file, sel.LDInfo(*vehicle), sel.To, *rate, groups)
+	} else {
+		err = export.WriteMDF4(file, sel.From, groups)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write %s: %v", *out, err)
+	}
+	if err := file.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %d message groups from %s to %s (definitions %.12s) to %s",
+		len(groups), sel.From.Format(time.RFC3339), sel.To.Format(time.RFC3339), sel.Defs.Hash, *out)
+}
@@ -0,0 +1,87 @@
+// bridge.go
+// Raw TCP ingest for the ESP32 CAN bridge. The bridge connects with a plain
+// TCP socket and streams binary frames ([4-byte ID][1-byte DLC][data]), which
+// are decoded and stored exactly like frames from the live WebSocket.
+package main
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net"
+	"time"
+
+	"telem-system/internal/config"
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/processdata"
+	"telem-system/pkg/types"
+)
+
+// bridgeReportInterval is how often an open bridge connection logs its counts.
+const bridgeReportInterval = time.Minute
+
+// bridgeCounts are the per-connection frame counts of a bridge connection.
+type bridgeCounts struct {
+	decoded uint64
+	unknown uint64 // frames whose ID has no definition
+}
+
+// serveBridge accepts bridge connections on addr until the listener fails.
+func serveBridge(addr string, cfg *config.Config, defs *candecoder.Registry) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go handleBridgeConn(conn, cfg, defs)
+	}
+}
+
+// handleBridgeConn decodes frames from one bridge connection and logs its
+// frame counts periodically and when the connection closes.
+func handleBridgeConn(conn net.Conn, cfg *config.Config, defs *candecoder.Registry) {
+	defer conn.Close()
+	remote := conn.RemoteAddr().String()
+	log.Printf("Bridge %s connected", remote)
+
+	session := startLiveSession(cfg.Vehicle, "tcp", defs.Current().Hash)
+	defer session.end()
+
+	reader := candecoder.NewBridgeReader(conn)
+	reader.LegacyIDs = cfg.BridgeLegacyIDs
+	var counts bridgeCounts
+	report := func(state string) {
+		log.Printf("Bridge %s %s: %d frames (%d decoded, %d unknown ID), %d resyncs, %d bytes skipped",
+			remote, state, reader.Frames, counts.decoded, counts.unknown, reader.Resyncs, reader.Skipped)
+	}
+	defer report("closed")
+
+	var frame candecoder.DecodedFrame
+	cellDataBuffers := make(map[float64]*types.Cell_Data)
+	lastReport := time.Now()
+	for {
+		f, err := reader.Next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				log.Printf("Bridge %s read error: %v", remote, err)
+			}
+			return
+		}
+		plan, exists := session.current(defs).Plans[f.ID]
+		if !exists {
+			counts.unknown++
+		} else if err := plan.Decode(f.Data, &frame); err == nil {
+			counts.decoded++
+			processdata.HandleDataInsertions(f.ID, &frame, cellDataBuffers, 0, "tcp")
+		}
+		if time.Since(lastReport) >= bridgeReportInterval {
+			lastReport = time.Now()
+			report("open")
+		}
+	}
+}
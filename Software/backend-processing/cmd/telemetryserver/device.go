@@ -0,0 +1,107 @@
+// device.go
+// Direct ingest from CAN hardware attached to the server: a SocketCAN
+// interface in "socketcan" mode or an SLCAN serial adapter in "slcan" mode.
+// Frames are stored with the device's receive times where it supplies them,
+// exactly like frames from the live WebSocket.
+package main
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"telem-system/internal/config"
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/processdata"
+	"telem-system/pkg/types"
+)
+
+// deviceRetryInterval is how long to wait before reopening a device after it
+// fails to open or goes away (e.g. an unplugged USB adapter).
+const deviceRetryInterval = 5 * time.Second
+
+// frameDevice is an open CAN device.
+type frameDevice interface {
+	candecoder.FrameReader
+	Close() error
+}
+
+// openDevice returns the opener for the device of cfg.Mode and its name, or
+// false if the mode reads no device.
+func openDevice(cfg *config.Config) (func() (frameDevice, error), string, bool) {
+	switch cfg.Mode {
+	case "socketcan":
+		return func() (frameDevice, error) {
+			r, err := candecoder.OpenSocketCAN(cfg.SocketCANInterface)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		}, cfg.SocketCANInterface, true
+	case "slcan":
+		return func() (frameDevice, error) {
+			r, err := candecoder.OpenSLCAN(cfg.SLCANDevice, cfg.SLCANBitrate, cfg.SLCANTimestamps)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		}, cfg.SLCANDevice, true
+	}
+	return nil, "", false
+}
+
+// serveDevice reads frames from a device for as long as the server runs,
+// reopening it whenever reading fails.
+func serveDevice(open func() (frameDevice, error), name string, cfg *config.Config, defs *candecoder.Registry) {
+	for {
+		if err := readDevice(open, name, cfg, defs); err != nil {
+			log.Printf("%s %s: %v", cfg.Mode, name, err)
+		}
+		time.Sleep(deviceRetryInterval)
+	}
+}
+
+// readDevice opens the device once and decodes frames until a read fails.
+// Each open is recorded as a session.
+func readDevice(open func() (frameDevice, error), name string, cfg *config.Config, defs *candecoder.Registry) error {
+	reader, err := open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	log.Printf("%s reading from %s", cfg.Mode, name)
+
+	session := startLiveSession(cfg.Vehicle, cfg.Mode, defs.Current().Hash)
+	defer session.end()
+
+	var counts bridgeCounts
+	var malformed uint64
+	defer func() {
+		log.Printf("%s %s closed: %d decoded, %d unknown ID, %d malformed",
+			cfg.Mode, name, counts.decoded, counts.unknown, malformed)
+	}()
+
+	var frame candecoder.DecodedFrame
+	cellDataBuffers := make(map[float64]*types.Cell_Data)
+	for {
+		f, err := reader.ReadFrame()
+		if errors.Is(err, candecoder.ErrLogSyntax) {
+			malformed++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		plan, exists := session.current(defs).Plans[f.ID]
+		if !exists {
+			counts.unknown++
+			continue
+		}
+		if err := plan.Decode(f.Data, &frame); err != nil {
+			continue
+		}
+		counts.decoded++
+		frame.Timestamp = f.Time
+		processdata.HandleDataInsertions(f.ID, &frame, cellDataBuffers, 0, cfg.Mode)
+	}
+}
@@ -0,0 +1,67 @@
+// framebatch.go
+// Batched binary framing of the /telemetry WebSocket. Clients that negotiate
+// candecoder.FrameBatchSubprotocol send CANFrameBatch protobufs instead of one
+// hex-encoded frame per message; frames keep the sender's capture times.
+package main
+
+import (
+	"log"
+
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/processdata"
+	"telem-system/pkg/types"
+
+	"github.com/gorilla/websocket"
+)
+
+// readFrameBatches decodes CANFrameBatch messages until the connection fails.
+// Gaps in the batch sequence numbers are counted as lost batches.
+func readFrameBatches(conn *websocket.Conn, defs *candecoder.Registry, session *liveSession, cellDataBuffers map[float64]*types.Cell_Data) {
+	var counts bridgeCounts
+	var batches, lost, malformed uint64
+	defer func() {
+		log.Printf("Telemetry batches closed: %d batches, %d lost, %d decoded, %d unknown ID, %d malformed",
+			batches, lost, counts.decoded, counts.unknown, malformed)
+	}()
+
+	var frame candecoder.DecodedFrame
+	var next uint64
+	for {
+		kind, msg, err := conn.ReadMessage()
+		if err != nil {
+			log.Println("Telemetry batch read error:", err)
+			return
+		}
+		if kind != websocket.BinaryMessage {
+			malformed++
+			continue
+		}
+		frames, seq, skipped, err := candecoder.ParseFrameBatch(msg)
+		if err != nil {
+			malformed++
+			if malformed == 1 {
+				log.Printf("Telemetry batch: %v", err)
+			}
+			continue
+		}
+		malformed += uint64(skipped)
+		if batches > 0 && seq > next {
+			lost += seq - next
+		}
+		batches++
+		next = seq + 1
+		for _, f := range frames {
+			plan, exists := session.current(defs).Plans[f.ID]
+			if !exists {
+				counts.unknown++
+				continue
+			}
+			if err := plan.Decode(f.Data, &frame); err != nil {
+				continue
+			}
+			counts.decoded++
+			frame.Timestamp = f.Time
+			processdata.HandleDataInsertions(f.ID, &frame, cellDataBuffers, 0, "live")
+		}
+	}
+}
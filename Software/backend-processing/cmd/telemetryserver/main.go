@@ -3,11 +3,12 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -16,6 +17,8 @@ import (
 	"telem-system/internal/wsserver"
 	"telem-system/pkg/candecoder"
 	"telem-system/pkg/db"
+	"telem-system/pkg/export"
+	"telem-system/pkg/linkstats"
 	"telem-system/pkg/processdata"
 	"telem-system/pkg/types"
 
@@ -37,10 +40,24 @@ func isRowEmpty(record []string) bool {
 	return true
 }
 
+// logLengthMismatches reports how many frames per ID disagreed with their
+// definition's length during a connection.
+func logLengthMismatches(mismatches map[types.CANID]int) {
+	for id, n := range mismatches {
+		log.Printf("Telemetry CSV: %d frames with ID %s had an unexpected length", n, id)
+	}
+}
+
 // telemetryHandler upgrades an HTTP connection to WebSocket and immediately listens for telemetry data.
-func telemetryHandler(w http.ResponseWriter, r *http.Request, cfg *config.Config, messageMap map[uint32]types.Message, cellDataBuffers map[float64]*types.Cell_Data) {
+// Each connection is recorded as a session with the hash of the definitions it
+// is decoded with, and a hot reload starts a new session. CSV rows carrying an AbsTime are decoded with the stored
+// version that was in effect at that time, falling back to the current set.
+// Clients that negotiate the batched frame subprotocol send binary batches
+// whatever the mode; all others use the mode's text format.
+func telemetryHandler(w http.ResponseWriter, r *http.Request, cfg *config.Config, defs *candecoder.Registry, versions *candecoder.Versions) {
 	upgrader := websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool { return true },
+		CheckOrigin:  func(r *http.Request) bool { return true },
+		Subprotocols: []string{candecoder.FrameBatchSubprotocol},
 	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -49,8 +66,22 @@ func telemetryHandler(w http.ResponseWriter, r *http.Request, cfg *config.Config
 	}
 	defer conn.Close()
 
-	// Process incoming messages based on the mode.
-	if cfg.Mode == "csv" {
+	session := startLiveSession(cfg.Vehicle, cfg.Mode, defs.Current().Hash)
+	defer session.end()
+
+	// frame is reused for every decoded message on this connection, and cell
+	// voltages are aggregated per connection so streams never share a row.
+	var frame candecoder.DecodedFrame
+	cellDataBuffers := make(map[float64]*types.Cell_Data)
+
+	// Process incoming messages based on the negotiated framing and the mode.
+	if conn.Subprotocol() == candecoder.FrameBatchSubprotocol {
+		readFrameBatches(conn, defs, session, cellDataBuffers)
+	} else if cfg.Mode == "csv" {
+		// Rows use the default Kvaser column order until a header row arrives.
+		layout := candecoder.DefaultKvaserLayout()
+		mismatches := make(map[types.CANID]int)
+		defer logLengthMismatches(mismatches)
 		for {
 			_, msg, err := conn.ReadMessage()
 			if err != nil {
@@ -58,59 +89,40 @@ func telemetryHandler(w http.ResponseWriter, r *http.Request, cfg *config.Config
 				break
 			}
 			csvReader := csv.NewReader(strings.NewReader(string(msg)))
+			csvReader.FieldsPerRecord = -1
 			record, err := csvReader.Read()
 			if err != nil || isRowEmpty(record) {
 				continue
 			}
-			if len(record) < 3 {
+			if l, ok := candecoder.ParseKvaserHeader(record); ok {
+				layout = l
 				continue
 			}
-			frameID, err := strconv.Atoi(record[2])
-			if err != nil {
+			row, err := layout.ParseRow(record)
+			if err != nil || row.IsErrorFrame() || row.IsRemote() {
 				continue
 			}
-			msgDef, exists := messageMap[uint32(frameID)]
-			if !exists {
-				continue
+			current := versions.Resolve(cfg.Vehicle, row.AbsTime, defs.Current())
+			if current.Hash != session.hash {
+				log.Printf("Telemetry CSV: decoding with definitions %.12s", current.Hash)
+				session.setDefinitions(current.Hash)
 			}
-			dataLen := msgDef.Length
-			if len(record) < 5+dataLen {
+			plan, exists := current.Plans[row.ID]
+			if !exists {
 				continue
 			}
-			dataFields := record[5 : 5+dataLen]
-			dataBytes := make([]byte, dataLen)
-			for i, field := range dataFields {
-				field = strings.TrimSpace(field)
-				if field == "" {
-					dataBytes[i] = 0
-					continue
-				}
-				b, err := strconv.ParseUint(field, 16, 8)
-				if err != nil {
-					continue
+			// Length mismatches are flagged; signals beyond a short payload
+			// decode as invalid.
+			if err := candecoder.CheckLength(plan.Message(), len(row.Data)); err != nil {
+				mismatches[row.ID]++
+				if mismatches[row.ID] == 1 {
+					log.Printf("Telemetry CSV: %v", err)
 				}
-				dataBytes[i] = byte(b)
 			}
-			if frameID >= 50 && frameID <= 57 {
-				decoded, err := candecoder.DecodeMessage(dataBytes, msgDef)
-				if err != nil {
-					continue
-				}
-				offset := (frameID - 50) * len(msgDef.Signals)
-				adjusted := make(map[string]string)
-				for i, sig := range msgDef.Signals {
-					if val, ok := decoded[sig.Name]; ok {
-						adjusted["Cell"+strconv.Itoa(offset+i+1)] = val
-					}
-				}
-				processdata.HandleDataInsertions(uint32(frameID), adjusted, cellDataBuffers, 0, "csv")
-			} else {
-				decoded, err := candecoder.DecodeMessage(dataBytes, msgDef)
-				if err != nil {
-					continue
-				}
-				processdata.HandleDataInsertions(uint32(frameID), decoded, cellDataBuffers, 0, "csv")
+			if err := plan.Decode(row.Data, &frame); err != nil {
+				continue
 			}
+			processdata.HandleDataInsertions(row.ID, &frame, cellDataBuffers, 0, "csv")
 		}
 	} else if cfg.Mode == "live" {
 		for {
@@ -119,44 +131,27 @@ func telemetryHandler(w http.ResponseWriter, r *http.Request, cfg *config.Config
 				log.Println("Telemetry Live read error:", err)
 				break
 			}
-			packetStr := string(msg)
-			data, err := candecoder.ParseLiveCANPacket(packetStr)
-			if err != nil || len(data) < 4 {
+			// First 4 bytes contain the CAN ID, with bit 31 set for extended frames.
+			frameID, data, err := candecoder.ParseLiveFrame(string(msg))
+			if err != nil {
 				continue
 			}
-			// First 4 bytes contain the frameID.
-			frameID := uint32(data[0])<<24 | uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3])
-			msgDef, exists := messageMap[frameID]
+			plan, exists := session.current(defs).Plans[frameID]
 			if !exists {
 				continue
 			}
-			// Pad data if shorter than expected.
-			if len(data) < msgDef.Length {
-				pad := make([]byte, msgDef.Length-len(data))
-				data = append(data, pad...)
-			}
-			decoded, err := candecoder.DecodeMessage(data, msgDef)
-			if err != nil {
+			// Signals past the end of a short payload decode as invalid.
+			if err := plan.Decode(data, &frame); err != nil {
 				continue
 			}
-			if frameID >= 50 && frameID <= 57 {
-				offset := int(frameID-50) * len(msgDef.Signals)
-				adjusted := make(map[string]string)
-				for i, sig := range msgDef.Signals {
-					if val, ok := decoded[sig.Name]; ok {
-						adjusted["Cell"+strconv.Itoa(offset+i+1)] = val
-					}
-				}
-				decoded = adjusted
-			}
-			processdata.HandleDataInsertions(frameID, decoded, cellDataBuffers, 0, "live")
+			processdata.HandleDataInsertions(frameID, &frame, cellDataBuffers, 0, "live")
 		}
 	}
 }
 
 func main() {
 	start := time.Now()
-	defer log.Printf("Telemetry Server started in %s", time.Since(start))
+	defer func() { log.Printf("Telemetry Server started in %s", time.Since(start)) }()
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
 	// Load configuration.
@@ -176,18 +171,51 @@ func main() {
 	queries := db.New(dbPool)
 
 	// Load CAN definitions.
-	messages, messageMap, err := candecoder.LoadJSONDefinitions(cfg.JSONFile)
+	defs, err := candecoder.NewRegistry(cfg.DefinitionsFile())
+	if err != nil {
+		log.Fatalf("Failed to load CAN definitions: %v", err)
+	}
+	log.Printf("Loaded %d messages from %s (%.12s)", len(defs.Current().Messages), cfg.DefinitionsFile(), defs.Current().Hash)
+
+	// Store each definition set activated for the vehicle and keep the
+	// vehicle's version history for replaying older logs.
+	if cfg.Vehicle == "" {
+		base := filepath.Base(cfg.DefinitionsFile())
+		cfg.Vehicle = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	versions := candecoder.NewVersions(nil)
+	recordVersion := func(d *candecoder.Definitions) {
+		ctx := context.Background()
+		if _, err := db.ActivateDefinitionVersion(ctx, d.Version(cfg.Vehicle, time.Now())); err != nil {
+			log.Printf("Failed to store definitions %.12s: %v", d.Hash, err)
+			return
+		}
+		history, err := queries.FetchDefinitionVersions(ctx, cfg.Vehicle)
+		if err != nil {
+			log.Printf("Failed to load definition versions: %v", err)
+			return
+		}
+		versions.Update(history)
+	}
+	recordVersion(defs.Current())
+	defs.OnReload = recordVersion
+
+	// Reload the definitions when the file changes, without dropping clients.
+	go func() {
+		if err := defs.Watch(context.Background()); err != nil {
+			log.Printf("CAN definitions hot-reload disabled: %v", err)
+		}
+	}()
+
+	rangePolicy, err := candecoder.ParseRangePolicy(cfg.RangePolicy)
 	if err != nil {
-		log.Fatalf("Failed to load JSON definitions: %v", err)
+		log.Fatalf("Invalid range_policy: %v", err)
 	}
-	log.Printf("Loaded %d messages", len(messages))
+	candecoder.SetRangePolicy(rangePolicy)
 
 	// Start the WebSocket hub.
 	go wsserver.WsHub.Run()
 
-	// Create a persistent aggregator for cell data.
-	cellDataBuffers := make(map[float64]*types.Cell_Data)
-
 	// Initialize the broadcast throttler.
 	processdata.InitThrottler(cfg.ThrottlerInterval)
 	processdata.BroadcastFunc = processdata.ThrottledBroadcast
@@ -209,6 +237,19 @@ func main() {
 
 	// Register additional API endpoints.
 	handlers.RegisterRoutes(apiRouter, queries)
+	ldSampleRate := cfg.LDSampleRate
+	if ldSampleRate == 0 {
+		ldSampleRate = export.DefaultLDSampleRate
+	}
+	udpLink := linkstats.NewTracker()
+	handlers.RegisterLinkRoutes(apiRouter, udpLink)
+	handlers.RegisterExportRoutes(apiRouter, &handlers.Exporter{
+		Queries:      queries,
+		Vehicle:      cfg.Vehicle,
+		Defs:         defs,
+		Versions:     versions,
+		LDSampleRate: ldSampleRate,
+	})
 
 	go func() {
 		apiAddr := ":" + cfg.APIPort
@@ -223,7 +264,7 @@ func main() {
 	// ---------------------
 	telemetryMux := http.NewServeMux()
 	telemetryMux.HandleFunc("/telemetry", func(w http.ResponseWriter, r *http.Request) {
-		telemetryHandler(w, r, cfg, messageMap, cellDataBuffers)
+		telemetryHandler(w, r, cfg, defs, versions)
 	})
 	telemetryAddr := fmt.Sprintf(":%d", cfg.WebSocket.Port)
 	log.Printf("Raw Telemetry WS server listening on %s", telemetryAddr)
@@ -233,6 +274,47 @@ func main() {
 		}
 	}()
 
+	// ---------------------
+	// Raw TCP ingest from the ESP32 CAN bridge on port cfg.BridgeTCPPort (e.g., 5000)
+	// ---------------------
+	if cfg.BridgeTCPPort != 0 {
+		bridgeAddr := fmt.Sprintf(":%d", cfg.BridgeTCPPort)
+		log.Printf("CAN bridge TCP server listening on %s", bridgeAddr)
+		go func() {
+			if err := serveBridge(bridgeAddr, cfg, defs); err != nil {
+				log.Fatalf("CAN bridge TCP server error: %v", err)
+			}
+		}()
+	}
+
+	// ---------------------
+	// UDP ingest over the car's Wi-Fi link on port cfg.UDPPort (e.g., 5001)
+	// ---------------------
+	if cfg.UDPPort != 0 {
+		udpAddr := fmt.Sprintf(":%d", cfg.UDPPort)
+		log.Printf("UDP telemetry listening on %s", udpAddr)
+		go func() {
+			if err := serveUDP(udpAddr, cfg, defs, udpLink); err != nil {
+				log.Fatalf("UDP telemetry error: %v", err)
+			}
+		}()
+	}
+
+	// ---------------------
+	// Direct ingest from cfg.SocketCANInterface (e.g., can0) or an SLCAN
+	// adapter at cfg.SLCANDevice (e.g., /dev/ttyACM0)
+	// ---------------------
+	if open, name, ok := openDevice(cfg); ok {
+		go serveDevice(open, name, cfg, defs)
+	}
+
+	// ---------------------
+	// MQTT ingest from cfg.MQTT.Topics on cfg.MQTT.Broker (e.g., tcp://localhost:1883)
+	// ---------------------
+	if cfg.Mode == "mqtt" {
+		go serveMQTT(cfg, defs)
+	}
+
 	// ---------------------
 	// Live Data WebSocket Server on port cfg.LiveWSPort (e.g., 9094)
 	// ---------------------
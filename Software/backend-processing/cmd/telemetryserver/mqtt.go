@@ -0,0 +1,119 @@
+// mqtt.go
+// MQTT ingest in "mqtt" mode. The server subscribes to the configured topics
+// on an external broker, decodes the raw frames published there and stores
+// them like frames from the live WebSocket, optionally republishing every
+// decoded signal to its own topic.
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"telem-system/internal/config"
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/mqttbridge"
+	"telem-system/pkg/processdata"
+	"telem-system/pkg/types"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// mqttTimeout bounds connecting, subscribing and publishing.
+const mqttTimeout = 10 * time.Second
+
+// serveMQTT keeps a broker connection for as long as the server runs,
+// reconnecting whenever it fails.
+func serveMQTT(cfg *config.Config, defs *candecoder.Registry) {
+	for {
+		if err := runMQTT(cfg, defs); err != nil {
+			log.Printf("MQTT %s: %v", cfg.MQTT.Broker, err)
+		}
+		time.Sleep(deviceRetryInterval)
+	}
+}
+
+// mqttBrokerURL adds the tcp scheme to a bare host:port broker address.
+func mqttBrokerURL(broker string) string {
+	if strings.Contains(broker, "://") {
+		return broker
+	}
+	return "tcp://" + broker
+}
+
+// waitToken waits for an MQTT operation to complete.
+func waitToken(t mqtt.Token) error {
+	if !t.WaitTimeout(mqttTimeout) {
+		return fmt.Errorf("no reply from broker within %v", mqttTimeout)
+	}
+	return t.Error()
+}
+
+// runMQTT connects and subscribes once and decodes messages until the
+// connection fails. Each connection is recorded as a session. Messages are
+// decoded on this goroutine, in the order the broker delivered them.
+func runMQTT(cfg *config.Config, defs *candecoder.Registry) error {
+	lost := make(chan error, 1)
+	opts := mqtt.NewClientOptions().
+		AddBroker(mqttBrokerURL(cfg.MQTT.Broker)).
+		SetClientID(cfg.MQTT.ClientID).
+		SetUsername(cfg.MQTT.Username).
+		SetPassword(cfg.MQTT.Password).
+		SetConnectTimeout(mqttTimeout).
+		SetAutoReconnect(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) { lost <- err })
+	client := mqtt.NewClient(opts)
+	if err := waitToken(client.Connect()); err != nil {
+		return err
+	}
+	defer client.Disconnect(250)
+
+	messages := make(chan mqtt.Message, 64)
+	done := make(chan struct{})
+	defer close(done)
+	filters := make(map[string]byte, len(cfg.MQTT.Topics))
+	for _, topic := range cfg.MQTT.Topics {
+		filters[topic] = byte(cfg.MQTT.QoS)
+	}
+	err := waitToken(client.SubscribeMultiple(filters, func(_ mqtt.Client, m mqtt.Message) {
+		select {
+		case messages <- m:
+		case <-done:
+		}
+	}))
+	if err != nil {
+		return err
+	}
+	log.Printf("MQTT subscribed to %v on %s", cfg.MQTT.Topics, cfg.MQTT.Broker)
+
+	session := startLiveSession(cfg.Vehicle, "mqtt", defs.Current().Hash)
+	defer session.end()
+
+	cellDataBuffers := make(map[float64]*types.Cell_Data)
+	bridge := &mqttbridge.Bridge{
+		Defs:            sessionDefinitions{session, defs},
+		Format:          cfg.MQTT.Format,
+		RepublishPrefix: cfg.MQTT.RepublishPrefix,
+		Handle: func(id types.CANID, frame *candecoder.DecodedFrame) {
+			processdata.HandleDataInsertions(id, frame, cellDataBuffers, 0, "mqtt")
+		},
+		Publish: func(topic string, payload []byte) error {
+			return waitToken(client.Publish(topic, 0, false, payload))
+		},
+	}
+	defer func() {
+		log.Printf("MQTT %s closed: %d messages, %d frames decoded, %d unknown ID, %d malformed",
+			cfg.MQTT.Broker, bridge.Messages, bridge.Decoded, bridge.Unknown, bridge.Malformed)
+	}()
+	for {
+		select {
+		case m := <-messages:
+			if err := bridge.Receive(m.Topic(), m.Payload()); err != nil {
+				return err
+			}
+		case err := <-lost:
+			return err
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// session.go
+// Recorded sessions of the ingest connections. A session stores the hash of
+// the definitions its frames are decoded with, so when a hot reload changes
+// the definitions during a connection the session is ended and a new one is
+// started with the new hash. Frames stored before and after the reload then
+// reference the version that actually decoded them.
+package main
+
+import (
+	"context"
+	"log"
+
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/db"
+)
+
+// Session storage, replaced in tests.
+var (
+	startSession          = db.StartSession
+	endSession            = db.EndSession
+	setSessionDefinitions = db.SetSessionDefinitions
+)
+
+// liveSession is the recorded session of one connection. It is not safe for
+// concurrent use.
+type liveSession struct {
+	vehicle string
+	source  string
+	id      int64 // 0 when the session could not be recorded
+	hash    string
+}
+
+// startLiveSession records a session decoded with the definitions of hash.
+func startLiveSession(vehicle, source, hash string) *liveSession {
+	s := &liveSession{vehicle: vehicle, source: source}
+	s.start(hash)
+	return s
+}
+
+func (s *liveSession) start(hash string) {
+	s.hash = hash
+	id, err := startSession(context.Background(), s.vehicle, s.source, hash)
+	if err != nil {
+		log.Printf("Failed to record %s session: %v", s.source, err)
+	}
+	s.id = id
+}
+
+// current returns the definitions to decode the next frame with, first
+// starting a new session if they were reloaded since the session started.
+func (s *liveSession) current(defs *candecoder.Registry) *candecoder.Definitions {
+	d := defs.Current()
+	if d.Hash != s.hash {
+		log.Printf("%s session %d: definitions reloaded (%.12s); starting a new session", s.source, s.id, d.Hash)
+		s.end()
+		s.start(d.Hash)
+	}
+	return d
+}
+
+// sessionDefinitions is a registry whose definitions are read through a
+// session, for decoders that take a mqttbridge.Definitions.
+type sessionDefinitions struct {
+	session *liveSession
+	defs    *candecoder.Registry
+}
+
+func (d sessionDefinitions) Current() *candecoder.Definitions {
+	return d.session.current(d.defs)
+}
+
+// setDefinitions records hash as the definitions of the running session, for
+// replayed logs that switch to a stored version part way through.
+func (s *liveSession) setDefinitions(hash string) {
+	s.hash = hash
+	if s.id == 0 {
+		return
+	}
+	if err := setSessionDefinitions(context.Background(), s.id, hash); err != nil {
+		log.Printf("Failed to update session definitions: %v", err)
+	}
+}
+
+// end records the end of the session.
+func (s *liveSession) end() {
+	if s.id != 0 {
+		endSession(context.Background(), s.id)
+		s.id = 0
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"telem-system/internal/config"
+	"telem-system/pkg/candecoder"
+)
+
+// recordSessions replaces the session storage with an in-memory log.
+func recordSessions(t *testing.T) (started *[]string, ended *[]int64) {
+	t.Helper()
+	started, ended = new([]string), new([]int64)
+	oldStart, oldEnd := startSession, endSession
+	t.Cleanup(func() { startSession, endSession = oldStart, oldEnd })
+	startSession = func(_ context.Context, vehicle, source, hash string) (int64, error) {
+		*started = append(*started, hash)
+		return int64(len(*started)), nil
+	}
+	endSession = func(_ context.Context, id int64) error {
+		*ended = append(*ended, id)
+		return nil
+	}
+	return started, ended
+}
+
+// scriptedDevice returns each frame in turn, calling before ahead of it.
+type scriptedDevice struct {
+	frames []candecoder.Frame
+	before func(i int)
+	next   int
+}
+
+func (d *scriptedDevice) ReadFrame() (candecoder.Frame, error) {
+	if d.next == len(d.frames) {
+		return candecoder.Frame{}, io.EOF
+	}
+	d.before(d.next)
+	d.next++
+	return d.frames[d.next-1], nil
+}
+
+func (d *scriptedDevice) Close() error { return nil }
+
+func TestReloadDuringSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "defs.dbc")
+	write := func(src string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("BO_ 1 A: 1 ECU\n SG_ X : 0|8@1+ (1,0) [0|0] \"\" Vector__XXX\n")
+	defs, err := candecoder.NewRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := defs.Current().Hash
+	started, ended := recordSessions(t)
+
+	// Frames use an undefined ID so nothing reaches the database; the
+	// definitions are reloaded ahead of the third frame.
+	dev := &scriptedDevice{frames: make([]candecoder.Frame, 4)}
+	for i := range dev.frames {
+		dev.frames[i] = candecoder.Frame{ID: 99, Data: []byte{1}}
+	}
+	dev.before = func(i int) {
+		if i != 2 {
+			return
+		}
+		write("BO_ 2 B: 1 ECU\n SG_ Y : 0|8@1+ (1,0) [0|0] \"\" Vector__XXX\n")
+		if _, err := defs.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{Mode: "socketcan", Vehicle: "test"}
+	err = readDevice(func() (frameDevice, error) { return dev, nil }, "test0", cfg, defs)
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("readDevice = %v, want EOF", err)
+	}
+
+	after := defs.Current().Hash
+	if after == before {
+		t.Fatal("reload did not change the hash")
+	}
+	if want := []string{before, after}; !reflect.DeepEqual(*started, want) {
+		t.Errorf("sessions started with %q, want %q", *started, want)
+	}
+	if want := []int64{1, 2}; !reflect.DeepEqual(*ended, want) {
+		t.Errorf("sessions ended %v, want %v", *ended, want)
+	}
+}
+
+func TestSessionSetDefinitions(t *testing.T) {
+	started, ended := recordSessions(t)
+	var set []string
+	old := setSessionDefinitions
+	t.Cleanup(func() { setSessionDefinitions = old })
+	setSessionDefinitions = func(_ context.Context, id int64, hash string) error {
+		set = append(set, hash)
+		return nil
+	}
+
+	s := startLiveSession("test", "csv", "a")
+	s.setDefinitions("b")
+	s.end()
+	if !reflect.DeepEqual(*started, []string{"a"}) || !reflect.DeepEqual(set, []string{"b"}) ||
+		!reflect.DeepEqual(*ended, []int64{1}) {
+		t.Errorf("started %q, set %q, ended %v", *started, set, *ended)
+	}
+}
@@ -0,0 +1,125 @@
+// udp.go
+// UDP ingest for the car's Wi-Fi link. Each datagram carries a batch of
+// frames with the sender's ID, sequence number and timestamp (see
+// candecoder/udp.go), so a dropout loses only the datagrams sent during it
+// instead of stalling the stream like TCP. Link statistics are tracked per
+// sender, served at /api/udpLink and broadcast on the live WebSocket.
+package main
+
+import (
+	"errors"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"telem-system/internal/config"
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/linkstats"
+	"telem-system/pkg/processdata"
+	"telem-system/pkg/types"
+)
+
+const (
+	// udpStatsInterval is how often link statistics are broadcast.
+	udpStatsInterval = time.Second
+	// udpSessionIdle ends a sender's session after this long without data.
+	udpSessionIdle = 30 * time.Second
+)
+
+// udpSession is the recorded session of one sender, with its own cell
+// voltage aggregator.
+type udpSession struct {
+	session  *liveSession
+	lastSeen time.Time
+	cells    map[float64]*types.Cell_Data
+}
+
+// serveUDP decodes datagrams received on addr until the socket fails.
+func serveUDP(addr string, cfg *config.Config, defs *candecoder.Registry, tracker *linkstats.Tracker) error {
+	pc, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer pc.Close()
+
+	sessions := make(map[uint16]*udpSession)
+	defer func() {
+		for _, s := range sessions {
+			s.session.end()
+		}
+	}()
+
+	buf := make([]byte, 65536)
+	var frame candecoder.DecodedFrame
+	lastStats := time.Now()
+	for {
+		pc.SetReadDeadline(time.Now().Add(udpStatsInterval))
+		n, from, err := pc.ReadFrom(buf)
+		now := time.Now()
+		if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			return err
+		}
+		if err == nil {
+			handleDatagram(buf[:n], from.String(), now, cfg, defs, tracker, sessions, &frame)
+		}
+		if now.Sub(lastStats) >= udpStatsInterval {
+			lastStats = now
+			if stats, _ := tracker.Stats(); len(stats) > 0 {
+				processdata.BroadcastLinkStats(stats)
+			}
+			endIdleUDPSessions(sessions, now)
+		}
+	}
+}
+
+// handleDatagram decodes and stores the frames of one datagram, dropping
+// duplicates and datagrams that arrive too late.
+func handleDatagram(b []byte, from string, arrival time.Time, cfg *config.Config, defs *candecoder.Registry,
+	tracker *linkstats.Tracker, sessions map[uint16]*udpSession, frame *candecoder.DecodedFrame) {
+	d, err := candecoder.ParseUDPDatagram(b)
+	if err != nil {
+		tracker.Malformed()
+		return
+	}
+	base, ok := tracker.Observe(d, from, arrival)
+	if !ok {
+		return
+	}
+
+	s, ok := sessions[d.Sender]
+	if !ok {
+		s = &udpSession{
+			session: startLiveSession(cfg.Vehicle, "udp", defs.Current().Hash),
+			cells:   make(map[float64]*types.Cell_Data),
+		}
+		sessions[d.Sender] = s
+		log.Printf("UDP sender %d at %s connected", d.Sender, from)
+	}
+	s.lastSeen = arrival
+
+	current := s.session.current(defs)
+	for _, f := range d.Frames {
+		plan, exists := current.Plans[f.ID]
+		if !exists {
+			continue
+		}
+		if err := plan.Decode(f.Data, frame); err != nil {
+			continue
+		}
+		frame.Timestamp = base.Add(time.Duration(f.Offset) * time.Microsecond)
+		processdata.HandleDataInsertions(f.ID, frame, s.cells, 0, "udp")
+	}
+}
+
+// endIdleUDPSessions ends the sessions of senders not heard from recently.
+func endIdleUDPSessions(sessions map[uint16]*udpSession, now time.Time) {
+	for sender, s := range sessions {
+		if now.Sub(s.lastSeen) < udpSessionIdle {
+			continue
+		}
+		log.Printf("UDP sender %d idle for %s; session ended", sender, udpSessionIdle)
+		s.session.end()
+		delete(sessions, sender)
+	}
+}
@@ -38,6 +38,8 @@ DROP TABLE IF EXISTS ins_imu           CASCADE;
 DROP TABLE IF EXISTS bamo_car_re_transmit CASCADE;
 DROP TABLE IF EXISTS pdm_current       CASCADE;
 DROP TABLE IF EXISTS pdm_re_transmit   CASCADE;
+DROP TABLE IF EXISTS sessions          CASCADE;
+DROP TABLE IF EXISTS definition_versions CASCADE;
 
 -- =============================================================
 -- Create Consolidated Tables for Batch Inserts
@@ -237,7 +239,8 @@ CREATE TABLE IF NOT EXISTS gps_best_pos (
     std_latitude DOUBLE PRECISION,
     std_longitude DOUBLE PRECISION,
     std_altitude DOUBLE PRECISION,
-    gps_status   INTEGER
+    gps_status   INTEGER,
+    gps_status_label TEXT
 );
 
 -- 11. FrontFrequency (frame_id 101)
@@ -290,6 +293,7 @@ CREATE TABLE IF NOT EXISTS tcu2 (
 CREATE TABLE IF NOT EXISTS aculv_fd_1 (
     timestamp              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     ams_status             INTEGER,
+    ams_status_label       TEXT,
     fld                    INTEGER,
     state_of_charge        DOUBLE PRECISION,
     accumulator_voltage    DOUBLE PRECISION,
@@ -328,7 +332,8 @@ CREATE TABLE IF NOT EXISTS pdm1 (
     global_error_flag     INTEGER,
     total_current         INTEGER,
     internal_rail_voltage DOUBLE PRECISION,
-    reset_source          INTEGER
+    reset_source          INTEGER,
+    reset_source_label    TEXT
 );
 
 -- 21. BamocarTxData (frame_id 385)
@@ -388,7 +393,41 @@ CREATE TABLE IF NOT EXISTS pdm_re_transmit (
     global_error_flag     INTEGER,
     total_current         INTEGER,
     internal_rail_voltage DOUBLE PRECISION,
-    reset_source          INTEGER
+    reset_source          INTEGER,
+    reset_source_label    TEXT
+);
+
+-- =============================================================
+-- CAN Definition Versions and Sessions
+-- =============================================================
+
+-- One row per activation of a definition set for a vehicle. The hash is the
+-- SHA-256 of the DBC/JSON file; effective_to is NULL while the set is in use.
+CREATE TABLE IF NOT EXISTS definition_versions (
+    id             SERIAL PRIMARY KEY,
+    vehicle        TEXT NOT NULL,
+    hash           TEXT NOT NULL,
+    format         TEXT NOT NULL,
+    content        BYTEA NOT NULL,
+    effective_from TIMESTAMPTZ NOT NULL,
+    effective_to   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS definition_versions_vehicle_from ON definition_versions (vehicle, effective_from);
+CREATE INDEX IF NOT EXISTS definition_versions_hash ON definition_versions (hash);
+
+-- One row per telemetry connection, with the definitions it was decoded with.
+-- Driver, venue, event and comment are filled in afterwards for exports.
+CREATE TABLE IF NOT EXISTS sessions (
+    id               SERIAL PRIMARY KEY,
+    vehicle          TEXT NOT NULL,
+    source           TEXT NOT NULL,
+    definitions_hash TEXT NOT NULL,
+    started_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ended_at         TIMESTAMPTZ,
+    driver           TEXT NOT NULL DEFAULT '',
+    venue            TEXT NOT NULL DEFAULT '',
+    event            TEXT NOT NULL DEFAULT '',
+    comment          TEXT NOT NULL DEFAULT ''
 );
 
 -- =============================================================
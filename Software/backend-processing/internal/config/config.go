@@ -18,13 +18,45 @@ type Config struct {
 		Port int    `mapstructure:"port"` // Raw telemetry WS port; receiver listens here.
 	} `mapstructure:"websocket"`
 
+	Vehicle           string `mapstructure:"vehicle"` // definition versions are stored per vehicle
 	DBCFile           string `mapstructure:"dbc_file"`
 	JSONFile          string `mapstructure:"json_file"`
-	Mode              string `mapstructure:"mode"`               // "csv" or "live"
+	Mode              string `mapstructure:"mode"`               // "csv", "live", "socketcan", "slcan" or "mqtt"
 	ThrottlerInterval int    `mapstructure:"throttler_interval"` // in milliseconds
 	APIPort           string `mapstructure:"apiport"`
+	RangePolicy       string `mapstructure:"range_policy"`   // "flag", "clamp" or "null"
+	LDSampleRate      int    `mapstructure:"ld_sample_rate"` // MoTeC export rate in Hz
 
-	LiveWSPort int `mapstructure:"live_ws_port"` // Live data WS (backend-to-frontend)
+	LiveWSPort    int `mapstructure:"live_ws_port"`    // Live data WS (backend-to-frontend)
+	BridgeTCPPort int `mapstructure:"bridge_tcp_port"` // Raw TCP ingest from the ESP32 bridge; 0 disables
+	UDPPort       int `mapstructure:"udp_port"`        // UDP telemetry datagrams; 0 disables
+
+	BridgeLegacyIDs bool `mapstructure:"bridge_legacy_ids"` // bridge firmware sends extended IDs without bit 31
+
+	SocketCANInterface string `mapstructure:"socketcan_interface"` // CAN interface read in "socketcan" mode
+	SLCANDevice        string `mapstructure:"slcan_device"`        // serial device read in "slcan" mode
+	SLCANBitrate       int    `mapstructure:"slcan_bitrate"`       // CAN bitrate set on the SLCAN adapter
+	SLCANTimestamps    bool   `mapstructure:"slcan_timestamps"`    // time frames by the adapter's clock
+
+	MQTT struct {
+		Broker          string   `mapstructure:"broker"` // tcp://host:port, ssl:// or ws://; a bare host:port means tcp
+		ClientID        string   `mapstructure:"client_id"`
+		Username        string   `mapstructure:"username"`
+		Password        string   `mapstructure:"password"`
+		Topics          []string `mapstructure:"topics"`
+		QoS             int      `mapstructure:"qos"`              // 0 or 1
+		Format          string   `mapstructure:"format"`           // "binary" or "json"
+		RepublishPrefix string   `mapstructure:"republish_prefix"` // decoded signals go to <prefix>/<message>/<signal>; "" disables
+	} `mapstructure:"mqtt"`
+}
+
+// DefinitionsFile returns the CAN definitions file to load. The DBC file is
+// preferred; the JSON export is used when no DBC file is configured.
+func (c *Config) DefinitionsFile() string {
+	if c.DBCFile != "" {
+		return c.DBCFile
+	}
+	return c.JSONFile
 }
 
 // LoadConfig reads and unmarshals the configuration file.
@@ -0,0 +1,28 @@
+// decoder.go
+//
+// Decoder diagnostics handlers. These expose the CAN decoder's runtime
+// counters, such as DBC range violations, over the REST API.
+package handlers
+
+import (
+	"net/http"
+
+	"telem-system/pkg/candecoder"
+
+	"github.com/go-chi/render"
+)
+
+// rangeViolationsResponse is the body of GET /api/rangeViolations.
+type rangeViolationsResponse struct {
+	Policy     string                      `json:"policy"`
+	Violations []candecoder.RangeViolation `json:"violations"`
+}
+
+// rangeViolationsHandler reports the per-signal range violation counters.
+func rangeViolationsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	render.JSON(w, r, rangeViolationsResponse{
+		Policy:     candecoder.CurrentRangePolicy().String(),
+		Violations: candecoder.RangeViolations(),
+	})
+}
@@ -0,0 +1,113 @@
+// export.go
+//
+// File export handlers. These stream a session or time range of stored
+// telemetry as a measurement file for offline analysis tools.
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/db"
+	"telem-system/pkg/export"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// Exporter serves file exports decoded with the vehicle's definitions.
+type Exporter struct {
+	Queries      *db.Queries
+	Vehicle      string
+	Defs         *candecoder.Registry
+	Versions     *candecoder.Versions
+	LDSampleRate int // default rate of MoTeC exports in Hz
+}
+
+// RegisterExportRoutes registers the file export endpoints.
+func RegisterExportRoutes(r chi.Router, e *Exporter) {
+	r.Get("/api/export/mdf4", e.mdf4Handler)
+	r.Get("/api/export/ld", e.ldHandler)
+}
+
+// selection parses ?session=ID, or ?from=...&to=... as RFC 3339 times.
+func (e *Exporter) selection(r *http.Request) (export.Selection, error) {
+	q := r.URL.Query()
+	current := e.Defs.Current()
+	if s := q.Get("session"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return export.Selection{}, fmt.Errorf("invalid session '%s'", s)
+		}
+		return export.SelectSession(r.Context(), e.Queries, e.Versions, id, current)
+	}
+	from, err := time.Parse(time.RFC3339, q.Get("from"))
+	if err != nil {
+		return export.Selection{}, fmt.Errorf("invalid from: %v", err)
+	}
+	to, err := time.Parse(time.RFC3339, q.Get("to"))
+	if err != nil {
+		return export.Selection{}, fmt.Errorf("invalid to: %v", err)
+	}
+	return export.SelectRange(e.Versions, e.Vehicle, from, to, current)
+}
+
+// mdf4Handler serves GET /api/export/mdf4 as an MDF4 file download.
+func (e *Exporter) mdf4Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	sel, err := e.selection(r)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	groups, err := export.Load(r.Context(), e.Queries, sel)
+	if err != nil {
+		render.Render(w, r, ErrRender(err))
+		return
+	}
+	// Write to a buffer first so a failed export is reported as an error
+	// rather than served as a truncated file.
+	var buf bytes.Buffer
+	if err := export.WriteMDF4(&buf, sel.From, groups); err != nil {
+		render.Render(w, r, ErrRender(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, sel.FileName(".mf4")))
+	w.Write(buf.Bytes())
+}
+
+// ldHandler serves GET /api/export/ld as a MoTeC log download. ?rate=Hz
+// overrides the configured sample rate.
+func (e *Exporter) ldHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	sel, err := e.selection(r)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	rate := e.LDSampleRate
+	if s := r.URL.Query().Get("rate"); s != "" {
+		if rate, err = strconv.Atoi(s); err != nil || rate <= 0 || rate > 10000 {
+			render.Render(w, r, ErrInvalidRequest(fmt.Errorf("invalid rate '%s'", s)))
+			return
+		}
+	}
+	groups, err := export.LoadResampled(r.Context(), e.Queries, sel, rate)
+	if err != nil {
+		render.Render(w, r, ErrRender(err))
+		return
+	}
+	var buf bytes.Buffer
+	if err := export.WriteLD(&buf, sel.LDInfo(e.Vehicle), sel.To, rate, groups); err != nil {
+		render.Render(w, r, ErrRender(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, sel.FileName(".ld")))
+	w.Write(buf.Bytes())
+}
@@ -137,4 +137,9 @@ func RegisterRoutes(r chi.Router, queries *db.Queries) {
 	r.Get("/api/pdm1Data", makePaginatedHandler(queries.FetchPDM1DataPaginated))
 	r.Get("/api/bamocarRxData", makePaginatedHandler(queries.FetchBamocarRxDataPaginated))
 	r.Get("/api/frontAnalogData", makePaginatedHandler(queries.FetchFrontAnalogDataPaginated))
+
+	r.Get("/api/sessions", makePaginatedHandler(queries.FetchSessionsPaginated))
+	r.Put("/api/sessions/{id}", sessionMetadataHandler(queries))
+
+	r.Get("/api/rangeViolations", rangeViolationsHandler)
 }
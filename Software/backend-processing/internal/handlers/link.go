@@ -0,0 +1,29 @@
+// link.go
+//
+// UDP link statistics handler. The UDP ingest tracks packet loss, reordering
+// and jitter per sender; GET /api/udpLink reports them.
+package handlers
+
+import (
+	"net/http"
+
+	"telem-system/pkg/linkstats"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// udpLinkResponse is the body of GET /api/udpLink.
+type udpLinkResponse struct {
+	Malformed uint64                `json:"malformed"` // datagrams that could not be parsed
+	Senders   []linkstats.LinkStats `json:"senders"`
+}
+
+// RegisterLinkRoutes registers the UDP link statistics endpoint.
+func RegisterLinkRoutes(r chi.Router, tracker *linkstats.Tracker) {
+	r.Get("/api/udpLink", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		senders, malformed := tracker.Stats()
+		render.JSON(w, r, udpLinkResponse{Malformed: malformed, Senders: senders})
+	})
+}
@@ -0,0 +1,60 @@
+// sessions.go
+//
+// Session metadata handlers. Sessions are recorded automatically per
+// connection or import; driver, venue, event and comment are entered
+// afterwards and end up in the headers of exported files.
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"telem-system/pkg/db"
+	"telem-system/pkg/types"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// ErrNotFound returns a not found error response.
+func ErrNotFound(err error) render.Renderer {
+	return &ErrResponse{
+		HTTPStatusCode: http.StatusNotFound,
+		StatusText:     "Resource not found.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// sessionMetadataHandler serves PUT /api/sessions/{id} with a JSON body of
+// driver, venue, event and comment, and returns the updated session.
+func sessionMetadataHandler(queries *db.Queries) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Render(w, r, ErrInvalidRequest(err))
+			return
+		}
+		var m types.SessionMetadata
+		if err := render.DecodeJSON(r.Body, &m); err != nil {
+			render.Render(w, r, ErrInvalidRequest(err))
+			return
+		}
+		if err := queries.UpdateSessionMetadata(r.Context(), id, m); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				render.Render(w, r, ErrNotFound(err))
+				return
+			}
+			render.Render(w, r, ErrRender(err))
+			return
+		}
+		session, err := queries.FetchSession(r.Context(), id)
+		if err != nil {
+			render.Render(w, r, ErrRender(err))
+			return
+		}
+		render.JSON(w, r, session)
+	}
+}
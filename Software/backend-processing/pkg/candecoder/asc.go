@@ -0,0 +1,274 @@
+// asc.go
+//
+// Parsing of Vector ASCII logs (.asc). The header sets the measurement start
+// ("date"), the number base of IDs and data ("base hex|dec") and whether
+// timestamps are absolute or relative to the previous event. Classic lines
+// read "<time> <channel> <id>[x] <Rx|Tx> d <dlc> <data...>", CAN FD lines
+// "<time> CANFD <channel> <Rx|Tx> <id>[x] [name] <brs> <esi> <dlc> <length>
+// <data...>"; other events such as statistics are skipped.
+package candecoder
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// ascDateLayouts are the date formats written by CANalyzer and CANoe.
+var ascDateLayouts = []string{
+	"Mon Jan 2 03:04:05.000 pm 2006",
+	"Mon Jan 2 03:04:05 pm 2006",
+	"Mon Jan 2 15:04:05.000 2006",
+	"Mon Jan 2 15:04:05 2006",
+}
+
+// ASCFrame is one frame of a Vector ASC log.
+type ASCFrame struct {
+	Time       time.Time     // Start plus Offset; zero if the log has no date
+	Offset     time.Duration // since the start of the measurement
+	Channel    int
+	ID         types.CANID
+	Direction  string // "Rx" or "Tx"
+	FD         bool
+	BRS        bool
+	ESI        bool
+	Remote     bool
+	ErrorFrame bool
+	Data       []byte
+}
+
+// ASCReader reads frames from an ASC log.
+type ASCReader struct {
+	sc       *bufio.Scanner
+	Line     int       // number of the line last read
+	Start    time.Time // measurement start from the header, zero if absent
+	Fallback time.Time // start used by ReadFrame when the header has no date
+	decimal  bool      // "base dec": IDs and data bytes are decimal
+	relative bool      // "timestamps relative": each time is a delta
+	last     time.Duration
+}
+
+// NewASCReader returns a reader over r.
+func NewASCReader(r io.Reader) *ASCReader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &ASCReader{sc: sc}
+}
+
+// Next returns the next frame, or io.EOF at the end of the log. Malformed
+// frame lines return an error wrapping ErrLogSyntax; reading may continue
+// after them.
+func (a *ASCReader) Next() (ASCFrame, error) {
+	for a.sc.Scan() {
+		a.Line++
+		fields := strings.Fields(a.sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "//") {
+			continue
+		}
+		if a.header(fields) {
+			continue
+		}
+		sec, nsec, err := parseSeconds(fields[0])
+		if err != nil {
+			continue // not an event line
+		}
+		offset := time.Duration(sec)*time.Second + time.Duration(nsec)
+		if a.relative {
+			offset += a.last
+		}
+		a.last = offset
+
+		f, ok, err := a.parseEvent(fields[1:])
+		if err != nil {
+			return f, fmt.Errorf("line %d: %w: %v", a.Line, ErrLogSyntax, err)
+		}
+		if !ok {
+			continue
+		}
+		f.Offset = offset
+		if !a.Start.IsZero() {
+			f.Time = a.Start.Add(offset)
+		}
+		return f, nil
+	}
+	if err := a.sc.Err(); err != nil {
+		return ASCFrame{}, err
+	}
+	return ASCFrame{}, io.EOF
+}
+
+// header applies a header line and reports whether the line was one.
+func (a *ASCReader) header(fields []string) bool {
+	switch strings.ToLower(fields[0]) {
+	case "date":
+		a.Start = parseASCDate(fields[1:])
+	case "base":
+		if len(fields) >= 2 {
+			a.decimal = strings.EqualFold(fields[1], "dec")
+		}
+		for i := 2; i+1 < len(fields); i++ {
+			if strings.EqualFold(fields[i], "timestamps") {
+				a.relative = strings.EqualFold(fields[i+1], "relative")
+			}
+		}
+	case "begin":
+		// "Begin Triggerblock <date>" gives the start if there was no date line.
+		if a.Start.IsZero() && len(fields) > 2 {
+			a.Start = parseASCDate(fields[2:])
+		}
+	case "end", "internal", "no":
+	default:
+		return false
+	}
+	return true
+}
+
+// parseASCDate parses the date of a header line, or returns the zero time.
+func parseASCDate(fields []string) time.Time {
+	s := strings.Join(fields, " ")
+	for _, layout := range ascDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseEvent parses the fields after the timestamp. It returns false for
+// events that are not CAN frames.
+func (a *ASCReader) parseEvent(fields []string) (ASCFrame, bool, error) {
+	var f ASCFrame
+	if len(fields) < 2 {
+		return f, false, nil
+	}
+	if strings.EqualFold(fields[0], "CANFD") {
+		return a.parseFD(fields[1:])
+	}
+	ch, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return f, false, nil
+	}
+	f.Channel = ch
+	if strings.EqualFold(fields[1], "ErrorFrame") {
+		f.ErrorFrame = true
+		return f, true, nil
+	}
+	id, ok := a.parseID(fields[1])
+	if !ok {
+		return f, false, nil // statistics and other channel events
+	}
+	f.ID = id
+	if len(fields) < 4 {
+		return f, false, fmt.Errorf("truncated frame")
+	}
+	f.Direction = fields[2]
+	if f.Direction == "TxRq" {
+		return f, false, nil
+	}
+	switch strings.ToLower(fields[3]) {
+	case "r":
+		f.Remote = true
+		return f, true, nil
+	case "d":
+	default:
+		return f, false, fmt.Errorf("unknown frame type '%s'", fields[3])
+	}
+	if len(fields) < 5 {
+		return f, false, fmt.Errorf("missing DLC")
+	}
+	dlc, err := strconv.ParseUint(fields[4], 16, 8)
+	if err != nil || dlc > 15 {
+		return f, false, fmt.Errorf("invalid DLC '%s'", fields[4])
+	}
+	if f.Data, err = a.parseData(fields[5:], DLCToLength(int(dlc), false)); err != nil {
+		return f, false, err
+	}
+	return f, true, nil
+}
+
+// parseFD parses the fields of a CANFD event after the keyword.
+func (a *ASCReader) parseFD(fields []string) (ASCFrame, bool, error) {
+	f := ASCFrame{FD: true}
+	if len(fields) < 3 {
+		return f, false, fmt.Errorf("truncated CAN FD frame")
+	}
+	ch, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return f, false, fmt.Errorf("invalid channel '%s'", fields[0])
+	}
+	f.Channel, f.Direction = ch, fields[1]
+	if strings.EqualFold(fields[2], "ErrorFrame") {
+		f.ErrorFrame = true
+		return f, true, nil
+	}
+	id, ok := a.parseID(fields[2])
+	if !ok {
+		return f, false, fmt.Errorf("invalid CAN id '%s'", fields[2])
+	}
+	f.ID = id
+	rest := fields[3:]
+	// The symbolic message name is optional; without it BRS and ESI follow.
+	if len(rest) > 0 && !isBit(rest[0]) {
+		rest = rest[1:]
+	}
+	if len(rest) < 4 || !isBit(rest[0]) || !isBit(rest[1]) {
+		return f, false, fmt.Errorf("truncated CAN FD frame")
+	}
+	f.BRS, f.ESI = rest[0] == "1", rest[1] == "1"
+	n, err := strconv.Atoi(rest[3])
+	if err != nil || n < 0 || n > 64 {
+		return f, false, fmt.Errorf("invalid data length '%s'", rest[3])
+	}
+	if f.Data, err = a.parseData(rest[4:], n); err != nil {
+		return f, false, err
+	}
+	return f, true, nil
+}
+
+// parseID parses an identifier in the log's base; a trailing x marks an
+// extended frame.
+func (a *ASCReader) parseID(s string) (types.CANID, bool) {
+	extended := strings.HasSuffix(s, "x") || strings.HasSuffix(s, "X")
+	if extended {
+		s = s[:len(s)-1]
+	}
+	base := 16
+	if a.decimal {
+		base = 10
+	}
+	id, err := strconv.ParseUint(s, base, 32)
+	if err != nil || id > 0x1FFFFFFF {
+		return 0, false
+	}
+	return types.NewCANID(uint32(id), extended || id > 0x7FF), true
+}
+
+// parseData parses n data bytes in the log's base.
+func (a *ASCReader) parseData(fields []string, n int) ([]byte, error) {
+	if len(fields) < n {
+		return nil, fmt.Errorf("expected %d data bytes, got %d", n, len(fields))
+	}
+	base := 16
+	if a.decimal {
+		base = 10
+	}
+	data := make([]byte, n)
+	for i := range data {
+		b, err := strconv.ParseUint(fields[i], base, 8)
+		if err != nil {
+			return nil, fmt.Errorf("parse data byte '%s': %v", fields[i], err)
+		}
+		data[i] = byte(b)
+	}
+	return data, nil
+}
+
+// isBit reports whether s is "0" or "1".
+func isBit(s string) bool {
+	return s == "0" || s == "1"
+}
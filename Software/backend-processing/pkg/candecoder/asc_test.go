@@ -0,0 +1,79 @@
+package candecoder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+func TestASCLog(t *testing.T) {
+	asc := `date Sat Nov 16 12:24:15.000 pm 2024
+base hex  timestamps absolute
+internal events logged
+// version 13.0.0
+Begin Triggerblock Sat Nov 16 12:24:15.000 pm 2024
+   0.000000 Start of measurement
+   0.015991 1  65              Rx   d 4 6A BC 73 46  Length = 228000 BitCount = 117 ID = 101
+   0.016500 2  18FF50E5x       Tx   d 2 01 02
+   0.017000 1  Statistic: D 0 R 0 XD 0 XR 0 E 0 O 0 B 0.00%
+   0.018000 1  ErrorFrame
+   0.019000 1  123             Rx   r
+   0.020000 CANFD   1 Rx        101  TCU2                             1 0 a 16 00 01 02 03 04 05 06 07 08 09 0a 0b 0c 0d 0e 0f   130000  223 00303000 5f4a8e 46500250 4b140250 20011736 2001173a
+   0.021000 CANFD   1 Rx        102                                   0 1 2 2 aa bb   130000  223 00303000 5f4a8e 46500250 4b140250 20011736 2001173a
+   0.022000 1  65              Rx   d 4 6A BC
+End TriggerBlock
+`
+	r := NewASCReader(strings.NewReader(asc))
+	var frames []ASCFrame
+	var syntax int
+	for {
+		f, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, ErrLogSyntax) {
+			syntax++
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) != 6 || syntax != 1 {
+		t.Fatalf("got %d frames and %d syntax errors", len(frames), syntax)
+	}
+
+	start := time.Date(2024, 11, 16, 12, 24, 15, 0, time.Local)
+	std := frames[0]
+	if std.ID != types.NewCANID(0x65, false) || std.Direction != "Rx" || !bytes.Equal(std.Data, []byte{0x6A, 0xBC, 0x73, 0x46}) {
+		t.Errorf("classic frame = %+v", std)
+	}
+	if want := start.Add(15991 * time.Microsecond); !std.Time.Equal(want) {
+		t.Errorf("time = %v, want %v", std.Time, want)
+	}
+	if ext := frames[1]; ext.ID != types.NewCANID(0x18FF50E5, true) || ext.Channel != 2 || ext.Direction != "Tx" {
+		t.Errorf("extended frame = %+v", ext)
+	}
+	if !frames[2].ErrorFrame || !frames[3].Remote {
+		t.Errorf("error/remote frames = %+v, %+v", frames[2], frames[3])
+	}
+	if fd := frames[4]; !fd.FD || !fd.BRS || fd.ESI || len(fd.Data) != 16 || fd.Data[15] != 0x0F {
+		t.Errorf("FD frame = %+v", fd)
+	}
+	if fd := frames[5]; fd.ID != 0x102 || fd.BRS || !fd.ESI || !bytes.Equal(fd.Data, []byte{0xAA, 0xBB}) {
+		t.Errorf("unnamed FD frame = %+v", fd)
+	}
+
+	rel := NewASCReader(strings.NewReader("base dec timestamps relative\n0.5 1 101 Rx d 1 255\n0.25 1 101 Rx d 1 16\n"))
+	rel.Next()
+	f, err := rel.Next()
+	if err != nil || f.Offset != 750*time.Millisecond || f.ID != 101 || f.Data[0] != 16 || !f.Time.IsZero() {
+		t.Errorf("relative decimal frame = %+v, %v", f, err)
+	}
+}
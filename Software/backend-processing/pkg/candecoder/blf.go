@@ -0,0 +1,275 @@
+// blf.go
+//
+// Reading of Vector binary logs (.blf). A BLF file is a "LOGG" header followed
+// by "LOBJ" objects; in practice all objects sit inside LOG_CONTAINER objects
+// whose payload is zlib-compressed, and an object may continue from one
+// container into the next. CAN_MESSAGE, CAN_MESSAGE2 and CAN_FD_MESSAGE
+// objects are returned as frames; other objects are skipped.
+package candecoder
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// BLF object types.
+const (
+	blfCANMessage   = 1
+	blfLogContainer = 10
+	blfCANMessage2  = 86
+	blfCANFDMessage = 100
+)
+
+const (
+	blfObjHeaderBase = 16 // signature, header size and version, object size and type
+	blfCANIDExtended = 0x80000000
+
+	blfFlagTenMicros = 0x1 // object timestamp in 10 µs units (else ns)
+	blfMsgFlagTx     = 0x01
+	blfMsgFlagRemote = 0x80
+	blfFDFlagEDL     = 0x1
+	blfFDFlagBRS     = 0x2
+	blfFDFlagESI     = 0x4
+)
+
+// BLFFrame is one CAN frame of a BLF log.
+type BLFFrame struct {
+	Time    time.Time     // Start plus Offset
+	Offset  time.Duration // since the start of the measurement
+	Channel int
+	ID      types.CANID
+	Tx      bool
+	FD      bool
+	BRS     bool
+	ESI     bool
+	Remote  bool
+	Data    []byte
+}
+
+// BLFReader reads frames from a BLF log.
+type BLFReader struct {
+	r     io.Reader
+	Start time.Time // measurement start from the file header
+	buf   []byte    // decompressed container data not yet parsed
+}
+
+// NewBLFReader reads the file header of r and returns a reader positioned at
+// the first object.
+func NewBLFReader(r io.Reader) (*BLFReader, error) {
+	var head [8]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, fmt.Errorf("read BLF header: %v", err)
+	}
+	if string(head[:4]) != "LOGG" {
+		return nil, fmt.Errorf("not a BLF file")
+	}
+	size := binary.LittleEndian.Uint32(head[4:])
+	if size < 72 || size > 4096 {
+		return nil, fmt.Errorf("invalid BLF header size %d", size)
+	}
+	rest := make([]byte, size-8)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, fmt.Errorf("read BLF header: %v", err)
+	}
+	// The measurement start is a SYSTEMTIME at offset 40 of the header.
+	st := rest[32:48]
+	u16 := func(i int) int { return int(binary.LittleEndian.Uint16(st[2*i:])) }
+	start := time.Date(u16(0), time.Month(u16(1)), u16(3), u16(4), u16(5), u16(6), u16(7)*int(time.Millisecond), time.Local)
+	return &BLFReader{r: r, Start: start}, nil
+}
+
+// Next returns the next CAN frame, or io.EOF at the end of the log.
+func (b *BLFReader) Next() (BLFFrame, error) {
+	for {
+		f, ok, err := b.parseBuffered()
+		if err != nil || ok {
+			return f, err
+		}
+		if err := b.readContainer(); err != nil {
+			return BLFFrame{}, err
+		}
+	}
+}
+
+// readContainer reads the next top-level object and appends its payload to the
+// buffer: the decompressed data of a container, or the object itself.
+func (b *BLFReader) readContainer() error {
+	var head [blfObjHeaderBase]byte
+	if _, err := io.ReadFull(b.r, head[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return io.EOF // trailing padding
+		}
+		return err
+	}
+	if string(head[:4]) != "LOBJ" {
+		return fmt.Errorf("%w: object signature %q", ErrLogSyntax, head[:4])
+	}
+	headerSize := int(binary.LittleEndian.Uint16(head[4:]))
+	objSize := int(binary.LittleEndian.Uint32(head[8:]))
+	objType := binary.LittleEndian.Uint32(head[12:])
+	if objSize < blfObjHeaderBase || headerSize < blfObjHeaderBase || headerSize > objSize {
+		return fmt.Errorf("invalid BLF object size %d", objSize)
+	}
+	body := make([]byte, objSize-blfObjHeaderBase)
+	if _, err := io.ReadFull(b.r, body); err != nil {
+		return fmt.Errorf("read BLF object: %v", err)
+	}
+	// Top-level objects are padded to a multiple of four bytes.
+	if pad := objSize % 4; pad > 0 {
+		io.ReadFull(b.r, make([]byte, pad))
+	}
+
+	if objType != blfLogContainer {
+		b.buf = append(b.buf, head[:]...)
+		b.buf = append(b.buf, body...)
+		return nil
+	}
+	payload := body[headerSize-blfObjHeaderBase:]
+	if len(payload) < 16 {
+		return fmt.Errorf("truncated BLF container")
+	}
+	method := binary.LittleEndian.Uint16(payload)
+	data := payload[16:]
+	switch method {
+	case 0:
+		b.buf = append(b.buf, data...)
+	case 2:
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("BLF container: %v", err)
+		}
+		out, err := io.ReadAll(zr)
+		if err != nil {
+			return fmt.Errorf("BLF container: %v", err)
+		}
+		b.buf = append(b.buf, out...)
+	default:
+		return fmt.Errorf("unsupported BLF compression method %d", method)
+	}
+	return nil
+}
+
+// parseBuffered parses objects from the buffer until a CAN frame is found. It
+// returns false when the buffer holds no complete object.
+func (b *BLFReader) parseBuffered() (BLFFrame, bool, error) {
+	for {
+		// Objects inside containers are padded; skip to the next signature.
+		i := bytes.Index(b.buf[:min(len(b.buf), 8)], []byte("LOBJ"))
+		if i < 0 {
+			if len(b.buf) >= 8 {
+				return BLFFrame{}, false, fmt.Errorf("%w: no object signature in container", ErrLogSyntax)
+			}
+			return BLFFrame{}, false, nil
+		}
+		obj := b.buf[i:]
+		if len(obj) < blfObjHeaderBase {
+			return BLFFrame{}, false, nil
+		}
+		headerSize := int(binary.LittleEndian.Uint16(obj[4:]))
+		headerVersion := binary.LittleEndian.Uint16(obj[6:])
+		objSize := int(binary.LittleEndian.Uint32(obj[8:]))
+		objType := binary.LittleEndian.Uint32(obj[12:])
+		if objSize < headerSize || headerSize < blfObjHeaderBase {
+			return BLFFrame{}, false, fmt.Errorf("%w: object size %d", ErrLogSyntax, objSize)
+		}
+		if len(obj) < objSize {
+			b.buf = b.buf[i:] // continues in the next container
+			return BLFFrame{}, false, nil
+		}
+		b.buf = obj[objSize:]
+		if len(b.buf) == 0 {
+			b.buf = nil
+		}
+
+		var flags uint32
+		var stamp uint64
+		switch headerVersion {
+		case 1:
+			if headerSize < blfObjHeaderBase+16 {
+				continue
+			}
+			flags = binary.LittleEndian.Uint32(obj[16:])
+			stamp = binary.LittleEndian.Uint64(obj[24:])
+		case 2:
+			if headerSize < blfObjHeaderBase+24 {
+				continue
+			}
+			flags = binary.LittleEndian.Uint32(obj[16:])
+			stamp = binary.LittleEndian.Uint64(obj[24:])
+		default:
+			continue
+		}
+		offset := time.Duration(stamp)
+		if flags&blfFlagTenMicros != 0 {
+			offset = time.Duration(stamp) * 10 * time.Microsecond
+		}
+
+		f, ok := parseBLFMessage(objType, obj[headerSize:objSize])
+		if !ok {
+			continue
+		}
+		f.Offset = offset
+		f.Time = b.Start.Add(offset)
+		return f, true, nil
+	}
+}
+
+// parseBLFMessage decodes the payload of a CAN message object.
+func parseBLFMessage(objType uint32, p []byte) (BLFFrame, bool) {
+	var f BLFFrame
+	if len(p) < 8 {
+		return f, false
+	}
+	f.Channel = int(binary.LittleEndian.Uint16(p))
+	flags := p[2]
+	dlc := int(p[3])
+	id := binary.LittleEndian.Uint32(p[4:])
+	f.ID = types.NewCANID(id&0x1FFFFFFF, id&blfCANIDExtended != 0)
+	f.Tx = flags&blfMsgFlagTx != 0
+	f.Remote = flags&blfMsgFlagRemote != 0
+
+	switch objType {
+	case blfCANMessage, blfCANMessage2:
+		if len(p) < 16 {
+			return f, false
+		}
+		f.Data = append([]byte(nil), p[8:8+min(dlc, 8)]...)
+	case blfCANFDMessage:
+		if len(p) < 20 {
+			return f, false
+		}
+		fdFlags := p[13]
+		valid := int(p[14])
+		f.FD = fdFlags&blfFDFlagEDL != 0
+		f.BRS = fdFlags&blfFDFlagBRS != 0
+		f.ESI = fdFlags&blfFDFlagESI != 0
+		n := min(valid, 64, len(p)-20)
+		f.Data = append([]byte(nil), p[20:20+n]...)
+	default:
+		return f, false
+	}
+	if f.Remote {
+		f.Data = nil
+	}
+	return f, true
+}
+
+// ReadFrame implements FrameReader.
+func (b *BLFReader) ReadFrame() (Frame, error) {
+	for {
+		f, err := b.Next()
+		if err != nil {
+			return Frame{}, err
+		}
+		if !f.Remote {
+			return Frame{Time: f.Time, ID: f.ID, Data: f.Data}, nil
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package candecoder
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// blfObject builds a BLF object with a version 1 header and a timestamp in
+// nanoseconds.
+func blfObject(objType uint32, stamp uint64, payload []byte) []byte {
+	le := binary.LittleEndian
+	obj := make([]byte, 32, 32+len(payload))
+	copy(obj, "LOBJ")
+	le.PutUint16(obj[4:], 32)
+	le.PutUint16(obj[6:], 1)
+	le.PutUint32(obj[8:], uint32(32+len(payload)))
+	le.PutUint32(obj[12:], objType)
+	le.PutUint32(obj[16:], 2) // nanosecond timestamps
+	le.PutUint64(obj[24:], stamp)
+	return append(obj, payload...)
+}
+
+// blfContainer wraps data in a zlib-compressed LOG_CONTAINER object.
+func blfContainer(data []byte) []byte {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(data)
+	zw.Close()
+	le := binary.LittleEndian
+	obj := make([]byte, 32, 32+z.Len()+3)
+	copy(obj, "LOBJ")
+	le.PutUint16(obj[4:], 16)
+	le.PutUint16(obj[6:], 1)
+	le.PutUint32(obj[8:], uint32(32+z.Len()))
+	le.PutUint32(obj[12:], blfLogContainer)
+	le.PutUint16(obj[16:], 2)
+	le.PutUint32(obj[24:], uint32(len(data)))
+	obj = append(obj, z.Bytes()...)
+	return append(obj, make([]byte, len(obj)%4)...)
+}
+
+func TestBLFLog(t *testing.T) {
+	le := binary.LittleEndian
+	canMsg := func(flags, dlc byte, id uint32, data ...byte) []byte {
+		p := make([]byte, 16)
+		le.PutUint16(p, 1)
+		p[2], p[3] = flags, dlc
+		le.PutUint32(p[4:], id)
+		copy(p[8:], data)
+		return p
+	}
+	fd := make([]byte, 20+64)
+	le.PutUint16(fd, 2)
+	fd[3] = 10
+	le.PutUint32(fd[4:], 0x102)
+	fd[13], fd[14] = blfFDFlagEDL|blfFDFlagBRS, 16
+	for i := 0; i < 16; i++ {
+		fd[20+i] = byte(i)
+	}
+
+	var objs []byte
+	objs = append(objs, blfObject(blfCANMessage, 1000000, canMsg(0, 4, 0x65, 0x6A, 0xBC, 0x73, 0x46))...)
+	objs = append(objs, 0, 0, 0, 0) // padding between objects
+	objs = append(objs, blfObject(65, 1500000, make([]byte, 8))...)
+	objs = append(objs, blfObject(blfCANMessage2, 2000000, append(canMsg(blfMsgFlagTx, 2, 0x18FF50E5|blfCANIDExtended, 1, 2), make([]byte, 8)...))...)
+	objs = append(objs, blfObject(blfCANMessage, 2500000, canMsg(blfMsgFlagRemote, 0, 0x123))...)
+	objs = append(objs, blfObject(blfCANFDMessage, 3000000, fd)...)
+
+	header := make([]byte, 144)
+	copy(header, "LOGG")
+	le.PutUint32(header[4:], 144)
+	for i, v := range []uint16{2024, 11, 6, 16, 12, 24, 15, 500} {
+		le.PutUint16(header[40+2*i:], v)
+	}
+	// The CAN_MESSAGE2 object is split across the two containers.
+	split := 60 + 4 + 40
+	file := append(header, blfContainer(objs[:split])...)
+	file = append(file, blfContainer(objs[split:])...)
+
+	r, err := NewBLFReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("NewBLFReader: %v", err)
+	}
+	start := time.Date(2024, 11, 16, 12, 24, 15, 500*int(time.Millisecond), time.Local)
+	if !r.Start.Equal(start) {
+		t.Errorf("start = %v, want %v", r.Start, start)
+	}
+	var frames []BLFFrame
+	for {
+		f, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) != 4 {
+		t.Fatalf("got %d frames", len(frames))
+	}
+	if std := frames[0]; std.ID != 0x65 || std.Tx || !bytes.Equal(std.Data, []byte{0x6A, 0xBC, 0x73, 0x46}) || !std.Time.Equal(start.Add(time.Millisecond)) {
+		t.Errorf("CAN_MESSAGE = %+v", std)
+	}
+	if ext := frames[1]; ext.ID != types.NewCANID(0x18FF50E5, true) || !ext.Tx || !bytes.Equal(ext.Data, []byte{1, 2}) {
+		t.Errorf("CAN_MESSAGE2 = %+v", ext)
+	}
+	if !frames[2].Remote || frames[2].Data != nil {
+		t.Errorf("remote frame = %+v", frames[2])
+	}
+	if f := frames[3]; !f.FD || !f.BRS || f.ESI || f.Channel != 2 || len(f.Data) != 16 || f.Data[15] != 15 || f.Offset != 3*time.Millisecond {
+		t.Errorf("CAN_FD_MESSAGE = %+v", f)
+	}
+
+	if _, err := NewBLFReader(strings.NewReader("date Sat Nov 16")); err == nil {
+		t.Error("NewBLFReader accepted an ASC log")
+	}
+}
@@ -0,0 +1,124 @@
+// bridge.go
+//
+// Binary framing of the ESP32 CAN bridge (Firmware/can_wifi_transmitter). Each
+// frame is [4-byte big-endian ID][1-byte DLC][DLC data bytes], with bit 31 of
+// the ID set for extended frames. BridgeReader splits a TCP stream into frames
+// and resynchronises after corrupt or partial data.
+//
+// Headers are validated strictly, which is what lets the reader tell noise
+// from a frame: only one in 2^20 ID values without bit 31 is a standard ID.
+// Firmware built before bit 31 was introduced sends extended IDs without it;
+// those streams need BridgeReader.LegacyIDs, which accepts any 29-bit ID and
+// reads IDs above 0x7FF as extended, at the cost of passing far more noise as
+// headers.
+package candecoder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"telem-system/pkg/types"
+)
+
+const (
+	bridgeHeaderLen = 5
+	bridgeMaxData   = 8 // the bridge forwards classic CAN frames only
+)
+
+// BridgeFrame is one frame read from the bridge. Data is only valid until the
+// next call to BridgeReader.Next.
+type BridgeFrame struct {
+	ID   types.CANID
+	Data []byte
+}
+
+// BridgeReader reads bridge frames from a byte stream. A stream starts in
+// sync; after a header fails validation the reader skips one byte at a time
+// and only accepts a frame again once it is followed by another valid header.
+type BridgeReader struct {
+	r      *bufio.Reader
+	synced bool
+	buf    [bridgeMaxData]byte
+
+	// LegacyIDs reads IDs above 0x7FF as extended whether or not bit 31 is
+	// set, for bridges running firmware that predates bit 31.
+	LegacyIDs bool
+
+	Frames  uint64 // frames returned
+	Resyncs uint64 // times sync was lost
+	Skipped uint64 // bytes discarded while resynchronising
+}
+
+// NewBridgeReader returns a reader over r.
+func NewBridgeReader(r io.Reader) *BridgeReader {
+	return &BridgeReader{r: bufio.NewReader(r), synced: true}
+}
+
+// parseBridgeHeader validates a frame header: the DLC must fit a classic CAN
+// frame and the ID must fit in 11 or, with bit 31 set, 29 bits. With legacy,
+// any 29-bit ID is accepted and IDs above 0x7FF are extended.
+func parseBridgeHeader(h []byte, legacy bool) (types.CANID, int, bool) {
+	id := types.CANID(binary.BigEndian.Uint32(h))
+	dlc := int(h[4])
+	if dlc > bridgeMaxData || id.ID() > 0x1FFFFFFF {
+		return 0, 0, false
+	}
+	if legacy {
+		return types.NewCANID(id.ID(), id.Extended() || id.ID() > 0x7FF), dlc, true
+	}
+	if !id.Extended() && id.ID() > 0x7FF {
+		return 0, 0, false
+	}
+	return id, dlc, true
+}
+
+// Next returns the next frame. It returns io.EOF at the end of the stream and
+// io.ErrUnexpectedEOF if the stream ends inside a frame.
+func (b *BridgeReader) Next() (BridgeFrame, error) {
+	for {
+		h, err := b.r.Peek(bridgeHeaderLen)
+		if err != nil {
+			if err == io.EOF && len(h) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return BridgeFrame{}, err
+		}
+		id, dlc, ok := parseBridgeHeader(h, b.LegacyIDs)
+		if ok && !b.synced {
+			ok = b.confirm(dlc)
+		}
+		if !ok {
+			if b.synced {
+				b.synced = false
+				b.Resyncs++
+			}
+			b.r.Discard(1)
+			b.Skipped++
+			continue
+		}
+
+		b.r.Discard(bridgeHeaderLen)
+		if _, err := io.ReadFull(b.r, b.buf[:dlc]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return BridgeFrame{}, err
+		}
+		b.synced = true
+		b.Frames++
+		return BridgeFrame{ID: id, Data: b.buf[:dlc]}, nil
+	}
+}
+
+// confirm reports whether a candidate frame with dlc data bytes is followed by
+// another valid header. If the stream ends or fails first the candidate is
+// accepted, as there is nothing left to misalign.
+func (b *BridgeReader) confirm(dlc int) bool {
+	next, err := b.r.Peek(2*bridgeHeaderLen + dlc)
+	if err != nil {
+		return true
+	}
+	_, _, ok := parseBridgeHeader(next[bridgeHeaderLen+dlc:], b.LegacyIDs)
+	return ok
+}
@@ -0,0 +1,85 @@
+package candecoder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+func bridgeFrame(id types.CANID, data ...byte) []byte {
+	return append([]byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id), byte(len(data))}, data...)
+}
+
+// readBridge returns every frame of stream as "<id> <data>".
+func readBridge(t *testing.T, r *BridgeReader) []string {
+	t.Helper()
+	var got []string
+	for {
+		f, err := r.Next()
+		if err != nil {
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("final error = %v, want io.ErrUnexpectedEOF", err)
+			}
+			return got
+		}
+		got = append(got, fmt.Sprintf("%s % X", f.ID, f.Data))
+	}
+}
+
+func TestBridgeReaderResync(t *testing.T) {
+	var stream []byte
+	stream = append(stream, bridgeFrame(4, 0x01, 0x02)...)
+	stream = append(stream, bridgeFrame(types.NewCANID(0x18FF50E5, true), 0xAA)...)
+	stream = append(stream, 0xFF, 0xFF, 0x13, 0x37, 0x00, 0x09) // garbage
+	stream = append(stream, bridgeFrame(6, 0x10, 0x20, 0x30)...)
+	stream = append(stream, bridgeFrame(7)...)
+	stream = append(stream, 0x00, 0x00) // truncated header
+
+	r := NewBridgeReader(bytes.NewReader(stream))
+	got := readBridge(t, r)
+	want := []string{"0x004 01 02", "0x18FF50E5x AA", "0x006 10 20 30", "0x007 "}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("frames = %q, want %q", got, want)
+	}
+	if r.Frames != 4 || r.Resyncs != 1 || r.Skipped != 6 {
+		t.Errorf("counts: %d frames, %d resyncs, %d skipped", r.Frames, r.Resyncs, r.Skipped)
+	}
+}
+
+func TestBridgeReaderLegacyIDs(t *testing.T) {
+	// The noise reads as a frame with ID 0x1234 and two data bytes when any
+	// 29-bit ID is accepted, so only the strict reader drops it.
+	var stream []byte
+	stream = append(stream, bridgeFrame(4, 0x01)...)
+	stream = append(stream, 0x00, 0x00, 0x12, 0x34, 0x02, 0xAA, 0xBB) // noise
+	stream = append(stream, bridgeFrame(6, 0x10, 0x20, 0x30)...)
+	stream = append(stream, bridgeFrame(7)...)
+	stream = append(stream, bridgeFrame(0x18FF50E5, 0xCC)...) // extended ID without bit 31
+	stream = append(stream, bridgeFrame(8, 0x80)...)
+	stream = append(stream, 0x00) // truncated header
+
+	tests := []struct {
+		legacy           bool
+		want             []string
+		resyncs, skipped uint64
+	}{
+		{false, []string{"0x004 01", "0x006 10 20 30", "0x007 ", "0x008 80"}, 2, 13},
+		{true, []string{"0x004 01", "0x00001234x AA BB", "0x006 10 20 30", "0x007 ", "0x18FF50E5x CC", "0x008 80"}, 0, 0},
+	}
+	for _, tt := range tests {
+		r := NewBridgeReader(bytes.NewReader(stream))
+		r.LegacyIDs = tt.legacy
+		got := readBridge(t, r)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("legacy %v: frames = %q, want %q", tt.legacy, got, tt.want)
+		}
+		if r.Resyncs != tt.resyncs || r.Skipped != tt.skipped {
+			t.Errorf("legacy %v: %d resyncs, %d skipped, want %d, %d", tt.legacy, r.Resyncs, r.Skipped, tt.resyncs, tt.skipped)
+		}
+	}
+}
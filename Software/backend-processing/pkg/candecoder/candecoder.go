@@ -18,8 +18,8 @@ import (
 )
 
 // LoadJSONDefinitions reads and parses a JSON file containing CAN message definitions.
-// It returns both a slice of messages and a map of messages keyed by frame ID.
-func LoadJSONDefinitions(jsonPath string) ([]types.Message, map[uint32]types.Message, error) {
+// It returns both a slice of messages and a map of messages keyed by CANID.
+func LoadJSONDefinitions(jsonPath string) ([]types.Message, map[types.CANID]types.Message, error) {
 	data, err := ioutil.ReadFile(jsonPath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("read JSON file: %v", err)
@@ -30,125 +30,184 @@ func LoadJSONDefinitions(jsonPath string) ([]types.Message, map[uint32]types.Mes
 		return nil, nil, fmt.Errorf("parse JSON: %v", err)
 	}
 
-	msgMap := make(map[uint32]types.Message)
+	return messages, MessageMap(messages), nil
+}
+
+// MessageMap indexes messages by CANID, so standard and extended frames with
+// the same numeric identifier do not collide.
+func MessageMap(messages []types.Message) map[types.CANID]types.Message {
+	msgMap := make(map[types.CANID]types.Message, len(messages))
 	for _, msg := range messages {
-		msgMap[msg.FrameID] = msg
+		msgMap[msg.Key()] = msg
 	}
-	return messages, msgMap, nil
+	return msgMap
+}
+
+// LabelSuffix is appended to a signal name to form the key under which
+// DecodeMessage stores the signal's choice label.
+const LabelSuffix = "_label"
+
+// LabelKey returns the decoded-map key holding the choice label for a signal.
+func LabelKey(signalName string) string {
+	return signalName + LabelSuffix
 }
 
 // DecodeMessage decodes raw CAN data into a map of signal names and stringified values.
 // If a signal cannot be decoded, its value is returned as an empty string.
+// For multiplexed messages only the signals selected by the current
+// multiplexer values are included. Signals with value descriptions (Choices)
+// also get their label under LabelKey(name) when the raw value is known.
+// It is a string view over DecodeFrame kept for existing callers.
 func DecodeMessage(data []byte, msg types.Message) (map[string]string, error) {
-	// Pad data if shorter than expected
-	if len(data) < msg.Length {
-		pad := make([]byte, msg.Length-len(data))
-		data = append(data, pad...)
+	frame, err := DecodeFrame(data, msg)
+	if err != nil {
+		return nil, err
 	}
-	decoded := make(map[string]string)
-
-	for _, signal := range msg.Signals {
-		val, err := decodeSignal(data, signal, msg.Length)
-		if err != nil {
-			decoded[signal.Name] = ""
-			continue
-		}
-		switch v := val.(type) {
-		case float64:
-			// If the value is a whole number, output as an integer string.
-			if v == float64(int64(v)) {
-				decoded[signal.Name] = strconv.FormatInt(int64(v), 10)
-			} else {
-				decoded[signal.Name] = fmt.Sprintf("%.6f", v)
-			}
-		case int64:
-			decoded[signal.Name] = strconv.FormatInt(v, 10)
-		default:
-			decoded[signal.Name] = fmt.Sprintf("%v", v)
+	decoded := make(map[string]string, len(frame.Signals))
+	for _, sv := range frame.Signals {
+		decoded[sv.Name] = FormatValue(sv)
+		if sv.Label != "" {
+			decoded[LabelKey(sv.Name)] = sv.Label
 		}
 	}
 	return decoded, nil
 }
 
-// decodeSignal extracts and converts a single signal from the provided raw data.
+// FormatValue renders a decoded value the way DecodeMessage reports it:
+// whole numbers as integers, other values with six decimals and invalid
+// signals as "".
+func FormatValue(sv SignalValue) string {
+	if !sv.Valid {
+		return ""
+	}
+	switch sv.Kind {
+	case KindInt:
+		return strconv.FormatInt(sv.Int, 10)
+	case KindUint:
+		if sv.Float == float64(sv.Uint) {
+			return strconv.FormatUint(sv.Uint, 10)
+		}
+	}
+	// If the value is a whole number, output as an integer string.
+	if sv.Float == float64(int64(sv.Float)) {
+		return strconv.FormatInt(int64(sv.Float), 10)
+	}
+	return fmt.Sprintf("%.6f", sv.Float)
+}
+
+// choiceLabel looks up the value description for the signal's raw value.
+// Choices are keyed by the raw (unscaled) integer, as exported by cantools.
+func choiceLabel(data []byte, signal types.Signal, msgLength int) (string, bool) {
+	if len(signal.Choices) == 0 || signal.IsFloat {
+		return "", false
+	}
+	raw, err := rawSignalValue(data, signal, msgLength)
+	if err != nil {
+		return "", false
+	}
+	key := strconv.FormatUint(raw, 10)
+	if signal.IsSigned {
+		key = strconv.FormatInt(signExtend(raw, signal.Length), 10)
+	}
+	label, ok := signal.Choices[key]
+	return label, ok
+}
+
+// decodeSignal extracts and converts a single signal from the provided raw
+// data. Float signals and scaled integers are returned as float64; integers
+// with factor 1 and offset 0 are returned exactly as int64 or uint64, so that
+// 64-bit counters keep every bit.
 func decodeSignal(data []byte, signal types.Signal, msgLength int) (interface{}, error) {
-	bitStart := signal.Start
-	bitEnd := bitStart + signal.Length
-	if bitEnd > msgLength*8 {
+	if !signalFits(signal, msgLength) || signal.Length > 64 {
 		return nil, fmt.Errorf("signal %s out of bounds", signal.Name)
 	}
+	if signal.IsFloat && signal.Length != 32 && signal.Length != 64 {
+		return nil, fmt.Errorf("unsupported float length %d for %s", signal.Length, signal.Name)
+	}
 
-	// Handle float signals separately.
+	// Motorola signals use DBC sawtooth bit numbering for both floats and integers.
+	raw, err := rawSignalValue(data, signal, msgLength)
+	if err != nil {
+		return nil, err
+	}
 	if signal.IsFloat {
-		if signal.Length != 32 && signal.Length != 64 {
-			return nil, fmt.Errorf("unsupported float length %d for %s", signal.Length, signal.Name)
-		}
-		byteStart := bitStart / 8
-		byteEnd := (bitStart + signal.Length) / 8
-		if byteEnd > len(data) {
-			return nil, fmt.Errorf("data too short for %s", signal.Name)
-		}
-		floatBytes := make([]byte, signal.Length/8)
-		copy(floatBytes, data[byteStart:byteEnd])
-		// Adjust for byte order.
-		if strings.EqualFold(signal.ByteOrder, "big_endian") {
-			for i, j := 0, len(floatBytes)-1; i < j; i, j = i+1, j-1 {
-				floatBytes[i], floatBytes[j] = floatBytes[j], floatBytes[i]
-			}
-		}
-		var physical float64
-		if signal.Length == 32 {
-			bits := binary.LittleEndian.Uint32(floatBytes)
-			physical = float64(math.Float32frombits(bits))*signal.Factor + signal.Offset
-		} else {
-			bits := binary.LittleEndian.Uint64(floatBytes)
-			physical = float64(math.Float64frombits(bits))*signal.Factor + signal.Offset
-		}
-		return physical, nil
+		return floatPhysical(raw, signal), nil
 	}
+	return intPhysical(raw, signal), nil
+}
 
-	// For little-endian integer signals that are whole-byte aligned.
-	if signal.ByteOrder == "little_endian" && signal.Length%8 == 0 && bitStart%8 == 0 {
-		numBytes := signal.Length / 8
-		startByte := bitStart / 8
-		endByte := startByte + numBytes
-		if endByte > len(data) {
-			return nil, fmt.Errorf("signal %s out of bounds", signal.Name)
-		}
-		bytesSlice := data[startByte:endByte]
-		reversed := make([]byte, numBytes)
-		for i := 0; i < numBytes; i++ {
-			reversed[i] = bytesSlice[numBytes-1-i]
-		}
-		raw := 0
-		for i, b := range reversed {
-			raw |= int(b) << (8 * (numBytes - 1 - i))
-		}
-		phys := float64(raw)*signal.Factor + signal.Offset
-		if signal.IsSigned {
-			return int64(phys), nil
+// isBigEndian reports whether the signal uses Motorola byte order.
+func isBigEndian(signal types.Signal) bool {
+	return strings.EqualFold(signal.ByteOrder, "big_endian")
+}
+
+// signalFits reports whether the signal lies entirely within msgLength bytes.
+// For Motorola signals the start bit is the MSB, so the extent is measured from
+// the MSB's position in sequential (byte-major, MSB-first) bit order.
+func signalFits(signal types.Signal, msgLength int) bool {
+	if signal.Start < 0 || signal.Length <= 0 {
+		return false
+	}
+	if isBigEndian(signal) {
+		msb := signal.Start/8*8 + 7 - signal.Start%8
+		return msb+signal.Length <= msgLength*8
+	}
+	return signal.Start+signal.Length <= msgLength*8
+}
+
+// extractBigEndian reads a Motorola signal whose MSB is at DBC bit startBit.
+// Bits are numbered LSB-first within each byte; moving towards the LSB walks
+// down a byte and then wraps to bit 7 of the next byte (the DBC "sawtooth").
+func extractBigEndian(data []byte, startBit, length int) uint64 {
+	var raw uint64
+	pos := startBit
+	for i := 0; i < length; i++ {
+		raw = raw<<1 | uint64((data[pos/8]>>(pos%8))&1)
+		if pos%8 == 0 {
+			pos += 15
+		} else {
+			pos--
 		}
-		return phys, nil
 	}
+	return raw
+}
 
-	// Fallback: bit-by-bit extraction.
-	var raw int
-	for i := bitStart; i < bitEnd; i++ {
-		byteIndex := i / 8
-		bitIndex := i % 8
-		raw |= int((data[byteIndex]>>bitIndex)&1) << (i - bitStart)
+// floatPhysical interprets raw as an IEEE-754 value and applies factor/offset.
+func floatPhysical(raw uint64, signal types.Signal) float64 {
+	if signal.Length == 32 {
+		return float64(math.Float32frombits(uint32(raw)))*signal.Factor + signal.Offset
 	}
+	return math.Float64frombits(raw)*signal.Factor + signal.Offset
+}
+
+// intPhysical sign-extends raw if required and applies factor/offset.
+// Unscaled values are returned as int64 (signed) or uint64; scaled values are
+// returned as float64 and keep their fractional part.
+func intPhysical(raw uint64, signal types.Signal) interface{} {
+	unscaled := signal.Factor == 1 && signal.Offset == 0
 	if signal.IsSigned {
-		maxVal := 1 << (signal.Length - 1)
-		if raw >= maxVal {
-			raw -= 1 << signal.Length
+		v := signExtend(raw, signal.Length)
+		if unscaled {
+			return v
 		}
+		return float64(v)*signal.Factor + signal.Offset
 	}
-	phys := float64(raw)*signal.Factor + signal.Offset
-	if signal.IsSigned {
-		return int64(phys), nil
+	if unscaled {
+		return raw
 	}
-	return phys, nil
+	return float64(raw)*signal.Factor + signal.Offset
+}
+
+// signExtend interprets the low length bits of raw as a two's complement
+// integer.
+func signExtend(raw uint64, length int) int64 {
+	if length <= 0 || length >= 64 {
+		return int64(raw)
+	}
+	if raw&(1<<(length-1)) != 0 {
+		raw |= ^uint64(0) << length
+	}
+	return int64(raw)
 }
 
 // ParseLiveCANPacket converts a space-separated CAN packet string into a byte slice.
@@ -167,3 +226,17 @@ func ParseLiveCANPacket(packet string) ([]byte, error) {
 	}
 	return data, nil
 }
+
+// ParseLiveFrame parses a live packet, whose first four bytes carry the
+// big-endian CAN identifier followed by the payload. As in DBC files, bit 31
+// of the identifier marks an extended frame.
+func ParseLiveFrame(packet string) (types.CANID, []byte, error) {
+	data, err := ParseLiveCANPacket(packet)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) < 4 {
+		return 0, nil, fmt.Errorf("CAN packet too short: %d bytes", len(data))
+	}
+	return types.CANID(binary.BigEndian.Uint32(data[:4])), data[4:], nil
+}
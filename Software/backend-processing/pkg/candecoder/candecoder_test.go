@@ -0,0 +1,233 @@
+package candecoder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+func TestDecodeBigEndianSignals(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		signal types.Signal
+		want   string
+	}{
+		{
+			name:   "byte aligned 8 bit",
+			data:   []byte{0xAB},
+			signal: types.Signal{Start: 7, Length: 8, Factor: 1},
+			want:   "171",
+		},
+		{
+			name:   "byte aligned 16 bit",
+			data:   []byte{0x12, 0x34},
+			signal: types.Signal{Start: 7, Length: 16, Factor: 1},
+			want:   "4660",
+		},
+		{
+			name:   "nibble inside one byte",
+			data:   []byte{0x0A},
+			signal: types.Signal{Start: 3, Length: 4, Factor: 1},
+			want:   "10",
+		},
+		{
+			name:   "12 bit crossing a byte boundary",
+			data:   []byte{0x0A, 0xBC},
+			signal: types.Signal{Start: 3, Length: 12, Factor: 1},
+			want:   "2748",
+		},
+		{
+			name:   "10 bit unaligned in the middle of the frame",
+			data:   []byte{0x00, 0x15, 0xA8},
+			signal: types.Signal{Start: 12, Length: 10, Factor: 1},
+			want:   "693",
+		},
+		{
+			name:   "start bit 0 wraps into the next byte",
+			data:   []byte{0x01, 0x54},
+			signal: types.Signal{Start: 0, Length: 8, Factor: 1},
+			want:   "170",
+		},
+		{
+			name:   "signed 8 bit",
+			data:   []byte{0xFF},
+			signal: types.Signal{Start: 7, Length: 8, IsSigned: true, Factor: 1},
+			want:   "-1",
+		},
+		{
+			name:   "signed 6 bit minimum",
+			data:   []byte{0x20},
+			signal: types.Signal{Start: 5, Length: 6, IsSigned: true, Factor: 1},
+			want:   "-32",
+		},
+		{
+			name:   "signed 16 bit at byte 2",
+			data:   []byte{0x00, 0x00, 0xFF, 0x38},
+			signal: types.Signal{Start: 23, Length: 16, IsSigned: true, Factor: 1},
+			want:   "-200",
+		},
+		{
+			name:   "scaled unsigned",
+			data:   []byte{0x01, 0x90},
+			signal: types.Signal{Start: 7, Length: 16, Factor: 0.5, Offset: -40},
+			want:   "160",
+		},
+		{
+			name:   "32 bit float",
+			data:   []byte{0x3F, 0xC0, 0x00, 0x00},
+			signal: types.Signal{Start: 7, Length: 32, IsFloat: true, Factor: 1},
+			want:   "1.500000",
+		},
+		{
+			name:   "past the end of the frame",
+			data:   []byte{0xFF},
+			signal: types.Signal{Start: 7, Length: 16, Factor: 1},
+			want:   "",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.signal.Name = "Sig"
+			tc.signal.ByteOrder = "big_endian"
+			msg := types.Message{Name: "Msg", Length: len(tc.data), Signals: []types.Signal{tc.signal}}
+			decoded, err := DecodeMessage(tc.data, msg)
+			if err != nil {
+				t.Fatalf("DecodeMessage: %v", err)
+			}
+			if got := decoded["Sig"]; got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeChoiceLabels(t *testing.T) {
+	msg := types.Message{
+		Name:   "ACULV_FD_1",
+		Length: 2,
+		Signals: []types.Signal{
+			{Name: "AMSStatus", Start: 0, Length: 8, ByteOrder: "little_endian", Factor: 1,
+				Choices: map[string]string{"0": "OK", "3": "FAULT_OVERVOLTAGE"}},
+			{Name: "Trim", Start: 8, Length: 4, ByteOrder: "little_endian", IsSigned: true, Factor: 1,
+				Choices: map[string]string{"-1": "NEGATIVE"}},
+		},
+	}
+	decoded, err := DecodeMessage([]byte{3, 0x0F}, msg)
+	if err != nil {
+		t.Fatalf("DecodeMessage: %v", err)
+	}
+	want := map[string]string{
+		"AMSStatus":           "3",
+		LabelKey("AMSStatus"): "FAULT_OVERVOLTAGE",
+		"Trim":                "-1",
+		LabelKey("Trim"):      "NEGATIVE",
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("got %v, want %v", decoded, want)
+	}
+
+	decoded, _ = DecodeMessage([]byte{7, 0}, msg)
+	if _, ok := decoded[LabelKey("AMSStatus")]; ok {
+		t.Errorf("unexpected label for undescribed value: %v", decoded)
+	}
+}
+
+func TestDecodeSignalBitWidths(t *testing.T) {
+	le := func(start, length int, signed bool, factor float64) types.Signal {
+		return types.Signal{Start: start, Length: length, ByteOrder: "little_endian", IsSigned: signed, Factor: factor}
+	}
+	be := func(start, length int, signed bool, factor float64) types.Signal {
+		s := le(start, length, signed, factor)
+		s.ByteOrder = "big_endian"
+		return s
+	}
+	ones := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	tests := []struct {
+		name   string
+		data   []byte
+		signal types.Signal
+		want   string
+	}{
+		{"1 bit unsigned", []byte{0x01}, le(0, 1, false, 1), "1"},
+		{"1 bit signed", []byte{0x01}, le(0, 1, true, 1), "-1"},
+		{"1 bit signed big endian", []byte{0x80}, be(7, 1, true, 1), "-1"},
+		{"63 bit unsigned", ones, le(0, 63, false, 1), "9223372036854775807"},
+		{"63 bit signed", ones, le(1, 63, true, 1), "-1"},
+		{"63 bit signed minimum", []byte{0, 0, 0, 0, 0, 0, 0, 0x40}, le(0, 63, true, 1), "-4611686018427387904"},
+		{"63 bit signed big endian", []byte{0x40, 0, 0, 0, 0, 0, 0, 0x01}, be(6, 63, true, 1), "-4611686018427387903"},
+		{"64 bit unsigned maximum", ones, le(0, 64, false, 1), "18446744073709551615"},
+		{"64 bit unsigned beyond float precision", []byte{0x01, 0, 0, 0, 0, 0, 0x20, 0}, le(0, 64, false, 1), "9007199254740993"},
+		{"64 bit signed minimum", []byte{0, 0, 0, 0, 0, 0, 0, 0x80}, le(0, 64, true, 1), "-9223372036854775808"},
+		{"64 bit signed big endian", []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFE}, be(7, 64, true, 1), "-2"},
+		{"64 bit signed scaled", []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFD}, be(7, 64, true, 0.5), "-1.500000"},
+		{"byte aligned signed 16 bit", []byte{0x38, 0xFF}, le(0, 16, true, 1), "-200"},
+		{"signed scaled keeps fraction", []byte{0xFF, 0xFF}, le(0, 16, true, 0.1), "-0.100000"},
+	}
+	var frame DecodedFrame
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.signal.Name = "Sig"
+			msg := types.Message{Name: "Msg", Length: len(tc.data), Signals: []types.Signal{tc.signal}}
+			decoded, err := DecodeFrame(tc.data, msg)
+			if err != nil {
+				t.Fatalf("DecodeFrame: %v", err)
+			}
+			got, _ := decoded.Value("Sig")
+			if s := FormatValue(got); s != tc.want {
+				t.Errorf("got %q (%+v), want %q", s, got, tc.want)
+			}
+			if err := Compile(msg).Decode(tc.data, &frame); err != nil {
+				t.Fatalf("Plan.Decode: %v", err)
+			}
+			if p, _ := frame.Value("Sig"); p != got {
+				t.Errorf("plan got %+v, DecodeFrame got %+v", p, got)
+			}
+		})
+	}
+}
+
+const extendedTestDBC = `VERSION ""
+
+BO_ 291 StdFrame: 1 TCU
+ SG_ A : 0|8@1+ (1,0) [0|0] "" Vector__XXX
+
+BO_ 2147483939 ExtFrame: 1 TCU
+ SG_ B : 0|8@1+ (1,0) [0|0] "" Vector__XXX
+`
+
+func TestExtendedIDsDoNotCollide(t *testing.T) {
+	msgs, err := ParseDBC(strings.NewReader(extendedTestDBC))
+	if err != nil {
+		t.Fatalf("ParseDBC: %v", err)
+	}
+	messageMap := MessageMap(msgs)
+	if len(messageMap) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messageMap))
+	}
+	if m := messageMap[types.NewCANID(0x123, false)]; m.Name != "StdFrame" {
+		t.Errorf("standard 0x123 = %q", m.Name)
+	}
+	if m := messageMap[types.NewCANID(0x123, true)]; m.Name != "ExtFrame" || m.FrameID != 0x123 {
+		t.Errorf("extended 0x123 = %+v", m)
+	}
+
+	id, payload, err := ParseLiveFrame("80 00 01 23 2A")
+	if err != nil || id != types.NewCANID(0x123, true) || !reflect.DeepEqual(payload, []byte{0x2A}) {
+		t.Errorf("ParseLiveFrame = %v, % X, %v", id, payload, err)
+	}
+	for _, tc := range []struct {
+		id, flags string
+		want      types.CANID
+	}{
+		{"291", "196610", types.NewCANID(0x123, false)},
+		{"291", "4", types.NewCANID(0x123, true)},
+		{"419364950", "", types.NewCANID(419364950, true)},
+	} {
+		if got, err := ParseKvaserID(tc.id, tc.flags); err != nil || got != tc.want {
+			t.Errorf("ParseKvaserID(%s, %s) = %v, %v; want %v", tc.id, tc.flags, got, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// candump.go
+//
+// Parsing of can-utils log files as written by `candump -l`. Each line is
+// "(seconds.micros) interface frame", where frame is ID#DATA for classic CAN,
+// ID#R[len] for remote requests and ID##<flags>DATA for CAN FD. Three hex
+// digits denote an 11-bit ID and eight digits a 29-bit ID.
+package candecoder
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// CAN FD flags in the nibble following "##".
+const (
+	CandumpFlagBRS = 0x1 // bit rate switch
+	CandumpFlagESI = 0x2 // error state indicator
+)
+
+// ErrLogSyntax wraps errors for malformed log lines. Readers that return it
+// can continue with the next line.
+var ErrLogSyntax = errors.New("malformed log line")
+
+// candumpErrFlag marks error frames in 8-digit IDs (CAN_ERR_FLAG).
+const candumpErrFlag = 0x20000000
+
+// CandumpFrame is one frame of a candump log.
+type CandumpFrame struct {
+	Time       time.Time
+	Interface  string
+	ID         types.CANID
+	FD         bool
+	Flags      byte // CandumpFlag* bits of CAN FD frames
+	Remote     bool
+	ErrorFrame bool
+	Data       []byte
+}
+
+// ParseCandumpLine parses one line of a candump log.
+func ParseCandumpLine(line string) (CandumpFrame, error) {
+	var f CandumpFrame
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return f, fmt.Errorf("expected '(time) interface frame', got %d fields", len(fields))
+	}
+	ts := fields[0]
+	if len(ts) < 3 || ts[0] != '(' || ts[len(ts)-1] != ')' {
+		return f, fmt.Errorf("invalid timestamp '%s'", ts)
+	}
+	sec, nsec, err := parseSeconds(ts[1 : len(ts)-1])
+	if err != nil {
+		return f, err
+	}
+	f.Time = time.Unix(sec, nsec)
+	f.Interface = fields[1]
+
+	frame := fields[2]
+	sep := strings.IndexByte(frame, '#')
+	if sep < 0 {
+		return f, fmt.Errorf("missing '#' in frame '%s'", frame)
+	}
+	idField, rest := frame[:sep], frame[sep+1:]
+	id, err := strconv.ParseUint(idField, 16, 32)
+	if err != nil {
+		return f, fmt.Errorf("parse CAN id '%s': %v", idField, err)
+	}
+	switch len(idField) {
+	case 3:
+		f.ID = types.NewCANID(uint32(id), false)
+	case 8:
+		f.ErrorFrame = id&candumpErrFlag != 0
+		f.ID = types.NewCANID(uint32(id)&0x1FFFFFFF, true)
+	default:
+		return f, fmt.Errorf("CAN id '%s' must have 3 or 8 hex digits", idField)
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "#"):
+		if len(rest) < 2 {
+			return f, fmt.Errorf("missing CAN FD flags in '%s'", frame)
+		}
+		flags, err := strconv.ParseUint(rest[1:2], 16, 8)
+		if err != nil {
+			return f, fmt.Errorf("parse CAN FD flags '%s': %v", rest[1:2], err)
+		}
+		f.FD, f.Flags = true, byte(flags)
+		rest = rest[2:]
+	case strings.HasPrefix(rest, "R") || strings.HasPrefix(rest, "r"):
+		f.Remote = true
+		return f, nil
+	}
+	// A classic frame may carry its raw DLC (9-15) after an underscore.
+	if i := strings.IndexByte(rest, '_'); i >= 0 && !f.FD {
+		rest = rest[:i]
+	}
+	rest = strings.ReplaceAll(rest, ".", "")
+	if f.Data, err = hex.DecodeString(rest); err != nil {
+		return f, fmt.Errorf("parse data '%s': %v", rest, err)
+	}
+	limit := 8
+	if f.FD {
+		limit = 64
+	}
+	if len(f.Data) > limit {
+		return f, fmt.Errorf("%d data bytes exceed the %d byte limit", len(f.Data), limit)
+	}
+	return f, nil
+}
+
+// parseSeconds parses a decimal "seconds.fraction" timestamp without going
+// through float64, which would lose the microseconds of a Unix timestamp.
+func parseSeconds(s string) (sec, nsec int64, err error) {
+	secStr, fracStr, _ := strings.Cut(s, ".")
+	if sec, err = strconv.ParseInt(secStr, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("parse timestamp '%s': %v", s, err)
+	}
+	if fracStr != "" {
+		if len(fracStr) > 9 {
+			fracStr = fracStr[:9]
+		}
+		frac, err := strconv.ParseUint(fracStr, 10, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse timestamp '%s': %v", s, err)
+		}
+		nsec = int64(frac)
+		for i := len(fracStr); i < 9; i++ {
+			nsec *= 10
+		}
+	}
+	return sec, nsec, nil
+}
+
+// CandumpReader reads frames from a candump log, skipping blank lines and
+// '#' comments.
+type CandumpReader struct {
+	sc   *bufio.Scanner
+	Line int // number of the line last read
+}
+
+// NewCandumpReader returns a reader over r.
+func NewCandumpReader(r io.Reader) *CandumpReader {
+	return &CandumpReader{sc: bufio.NewScanner(r)}
+}
+
+// Next returns the next frame, or io.EOF at the end of the log. Malformed
+// lines return an error wrapping ErrLogSyntax with the line number; reading
+// may continue after them.
+func (c *CandumpReader) Next() (CandumpFrame, error) {
+	for c.sc.Scan() {
+		c.Line++
+		line := strings.TrimSpace(c.sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f, err := ParseCandumpLine(line)
+		if err != nil {
+			return f, fmt.Errorf("line %d: %w: %v", c.Line, ErrLogSyntax, err)
+		}
+		return f, nil
+	}
+	if err := c.sc.Err(); err != nil {
+		return CandumpFrame{}, err
+	}
+	return CandumpFrame{}, io.EOF
+}
@@ -0,0 +1,64 @@
+package candecoder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+func TestCandumpLog(t *testing.T) {
+	log := `# recorded on the bench
+(1731759855.316080) can0 065#6ABC7346
+(1731759855.318090) can0 18FF50E5#0102030405060708
+(1731759855.320000) can1 101##3000102030405060708090A0B
+(1731759855.320100) can0 123#R
+(1731759855.320200) can0 20000080#0000000000000000
+(1731759855.320300) can0 123#XYZ
+(1731759855.320400) can0 7FF#
+`
+	r := NewCandumpReader(strings.NewReader(log))
+	var frames []CandumpFrame
+	var syntax int
+	for {
+		f, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, ErrLogSyntax) {
+			syntax++
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) != 6 || syntax != 1 {
+		t.Fatalf("got %d frames and %d syntax errors", len(frames), syntax)
+	}
+
+	std := frames[0]
+	if std.ID != types.NewCANID(0x065, false) || !bytes.Equal(std.Data, []byte{0x6A, 0xBC, 0x73, 0x46}) || std.Interface != "can0" {
+		t.Errorf("standard frame = %+v", std)
+	}
+	if want := time.Unix(1731759855, 316080000); !std.Time.Equal(want) {
+		t.Errorf("time = %v, want %v", std.Time, want)
+	}
+	if ext := frames[1]; ext.ID != types.NewCANID(0x18FF50E5, true) || len(ext.Data) != 8 {
+		t.Errorf("extended frame = %+v", ext)
+	}
+	if fd := frames[2]; !fd.FD || fd.Flags != CandumpFlagBRS|CandumpFlagESI || len(fd.Data) != 12 || fd.Data[11] != 0x0B {
+		t.Errorf("FD frame = %+v", fd)
+	}
+	if !frames[3].Remote || !frames[4].ErrorFrame {
+		t.Errorf("remote/error frames = %+v, %+v", frames[3], frames[4])
+	}
+	if empty := frames[5]; empty.ID != 0x7FF || len(empty.Data) != 0 {
+		t.Errorf("empty frame = %+v", empty)
+	}
+}
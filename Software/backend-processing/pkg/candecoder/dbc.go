@@ -0,0 +1,826 @@
+// dbc.go
+//
+// Native parser for Vector DBC files. It produces the same []types.Message
+// representation as LoadJSONDefinitions so the server can load the DBC
+// directly instead of relying on the cantools JSON conversion step.
+package candecoder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"telem-system/pkg/types"
+)
+
+// LoadDefinitions loads CAN message definitions from either a DBC or a JSON
+// file, selected by the file extension.
+func LoadDefinitions(path string) ([]types.Message, map[types.CANID]types.Message, error) {
+	if DefinitionsFormat(path) == FormatDBC {
+		return LoadDBCDefinitions(path)
+	}
+	return LoadJSONDefinitions(path)
+}
+
+// LoadDBCDefinitions reads and parses a DBC file containing CAN message definitions.
+// It returns both a slice of messages and a map of messages keyed by CANID.
+func LoadDBCDefinitions(dbcPath string) ([]types.Message, map[types.CANID]types.Message, error) {
+	f, err := os.Open(dbcPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read DBC file: %v", err)
+	}
+	defer f.Close()
+
+	messages, err := ParseDBC(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse DBC: %v", err)
+	}
+
+	return messages, MessageMap(messages), nil
+}
+
+// ParseDBC parses DBC content and returns the messages in file order.
+// Supported sections are BO_, SG_, VAL_, CM_, BA_DEF_, BA_DEF_DEF_, BA_,
+// SIG_VALTYPE_ and SG_MUL_VAL_; other sections are skipped. Two messages
+// with the same ID are an error.
+func ParseDBC(r io.Reader) ([]types.Message, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	toks, err := tokenizeDBC(string(src))
+	if err != nil {
+		return nil, err
+	}
+	p := &dbcParser{
+		toks:     toks,
+		byRawID:  make(map[uint32]*types.Message),
+		attrDefs: make(map[string]*dbcAttrDef),
+		msgAttrs: make(map[uint32]map[string]string),
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.finish(), nil
+}
+
+// --- Tokenizer ---
+
+type dbcTokKind int
+
+const (
+	tokIdent dbcTokKind = iota
+	tokNumber
+	tokString
+	tokPunct
+)
+
+type dbcToken struct {
+	kind dbcTokKind
+	text string
+	line int
+	col  int
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// tokenizeDBC splits DBC source into identifiers, numbers, strings and punctuation.
+func tokenizeDBC(src string) ([]dbcToken, error) {
+	var toks []dbcToken
+	line, lineStart := 1, 0
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+			lineStart = i
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '"':
+			startLine, col := line, i-lineStart
+			var sb strings.Builder
+			i++
+			for i < len(src) && src[i] != '"' {
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				if src[i] == '\n' {
+					line++
+					lineStart = i + 1
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", startLine)
+			}
+			i++
+			toks = append(toks, dbcToken{tokString, sb.String(), startLine, col})
+		case isDigit(c) || ((c == '-' || c == '+' || c == '.') && i+1 < len(src) && (isDigit(src[i+1]) || src[i+1] == '.')):
+			j := i + 1
+			hex := c == '0' && i+1 < len(src) && (src[i+1] == 'x' || src[i+1] == 'X')
+			if hex {
+				j++
+			}
+			for j < len(src) {
+				d := src[j]
+				if isDigit(d) || d == '.' || (hex && ((d >= 'a' && d <= 'f') || (d >= 'A' && d <= 'F'))) {
+					j++
+					continue
+				}
+				if !hex && (d == 'e' || d == 'E') {
+					j++
+					continue
+				}
+				// Exponent sign, e.g. 3.4E+038.
+				if (d == '+' || d == '-') && (src[j-1] == 'e' || src[j-1] == 'E') {
+					j++
+					continue
+				}
+				break
+			}
+			toks = append(toks, dbcToken{tokNumber, src[i:j], line, i - lineStart})
+			i = j
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(src) && (isIdentStart(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			toks = append(toks, dbcToken{tokIdent, src[i:j], line, i - lineStart})
+			i = j
+		default:
+			toks = append(toks, dbcToken{tokPunct, string(c), line, i - lineStart})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+// --- Parser ---
+
+type dbcAttrDef struct {
+	objType string
+	valType string
+	enum    []string
+	deflt   string
+}
+
+type dbcParser struct {
+	toks []dbcToken
+	pos  int
+
+	order    []*types.Message
+	byRawID  map[uint32]*types.Message
+	attrDefs map[string]*dbcAttrDef
+	msgAttrs map[uint32]map[string]string
+}
+
+func (p *dbcParser) eof() bool { return p.pos >= len(p.toks) }
+
+func (p *dbcParser) peek() dbcToken {
+	if p.eof() {
+		return dbcToken{}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *dbcParser) next() (dbcToken, error) {
+	if p.eof() {
+		return dbcToken{}, fmt.Errorf("unexpected end of file")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *dbcParser) errorf(t dbcToken, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", t.line, fmt.Sprintf(format, args...))
+}
+
+func (p *dbcParser) expect(text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.text != text || t.kind == tokString {
+		return p.errorf(t, "expected %q, got %q", text, t.text)
+	}
+	return nil
+}
+
+func (p *dbcParser) expectKind(kind dbcTokKind, what string) (dbcToken, error) {
+	t, err := p.next()
+	if err != nil {
+		return t, err
+	}
+	if t.kind != kind {
+		return t, p.errorf(t, "expected %s, got %q", what, t.text)
+	}
+	return t, nil
+}
+
+func (p *dbcParser) uintTok() (uint64, error) {
+	t, err := p.expectKind(tokNumber, "integer")
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(t.text, 0, 64)
+	if err != nil {
+		return 0, p.errorf(t, "invalid integer %q", t.text)
+	}
+	return v, nil
+}
+
+func (p *dbcParser) floatTok() (float64, error) {
+	t, err := p.expectKind(tokNumber, "number")
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return 0, p.errorf(t, "invalid number %q", t.text)
+	}
+	return v, nil
+}
+
+// skipStatement advances past the next ';'.
+func (p *dbcParser) skipStatement() {
+	for !p.eof() {
+		t := p.toks[p.pos]
+		p.pos++
+		if t.kind == tokPunct && t.text == ";" {
+			return
+		}
+	}
+}
+
+// skipLine advances past every token on the given line.
+func (p *dbcParser) skipLine(line int) {
+	for !p.eof() && p.toks[p.pos].line == line {
+		p.pos++
+	}
+}
+
+func (p *dbcParser) parse() error {
+	for !p.eof() {
+		t, _ := p.next()
+		if t.kind != tokIdent {
+			return p.errorf(t, "unexpected %q", t.text)
+		}
+		var err error
+		switch t.text {
+		case "VERSION":
+			_, err = p.expectKind(tokString, "version string")
+		case "NS_":
+			// The new-symbols list is indented beneath NS_ and has no terminator.
+			if err = p.expect(":"); err == nil {
+				for !p.eof() && (p.peek().line == t.line || p.peek().col > 0) {
+					p.pos++
+				}
+			}
+		case "BS_", "BU_":
+			p.skipLine(t.line)
+		case "BO_":
+			err = p.parseMessage()
+		case "SG_":
+			err = p.errorf(t, "SG_ outside of BO_")
+		case "CM_":
+			err = p.parseComment()
+		case "VAL_":
+			err = p.parseValueDescriptions()
+		case "BA_DEF_":
+			err = p.parseAttrDef()
+		case "BA_DEF_DEF_":
+			err = p.parseAttrDefault()
+		case "BA_":
+			err = p.parseAttr()
+		case "SIG_VALTYPE_":
+			err = p.parseSigValType()
+		case "SG_MUL_VAL_":
+			err = p.parseExtendedMux()
+		default:
+			// VAL_TABLE_, BO_TX_BU_, EV_, relation attributes, etc.
+			p.skipStatement()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseMessage handles "BO_ <id> <name>: <dlc> <sender>" and its SG_ lines.
+func (p *dbcParser) parseMessage() error {
+	rawID, err := p.uintTok()
+	if err != nil {
+		return err
+	}
+	name, err := p.expectKind(tokIdent, "message name")
+	if err != nil {
+		return err
+	}
+	if err := p.expect(":"); err != nil {
+		return err
+	}
+	length, err := p.uintTok()
+	if err != nil {
+		return err
+	}
+	if _, err := p.expectKind(tokIdent, "transmitter"); err != nil {
+		return err
+	}
+
+	// BO_ identifiers use bit 31 to mark 29-bit frames, as CANID does.
+	id := types.CANID(rawID)
+	msg := &types.Message{
+		FrameID:         id.ID(),
+		Name:            name.text,
+		IsExtendedFrame: id.Extended(),
+		Length:          int(length),
+		Signals:         []types.Signal{},
+	}
+	for !p.eof() && p.peek().kind == tokIdent && p.peek().text == "SG_" {
+		p.pos++
+		sig, err := p.parseSignal()
+		if err != nil {
+			return err
+		}
+		msg.Signals = append(msg.Signals, sig)
+	}
+	// The pseudo-message VECTOR__INDEPENDENT_SIG_MSG only holds orphaned signals.
+	if msg.Name == "VECTOR__INDEPENDENT_SIG_MSG" {
+		return nil
+	}
+	if prev, dup := p.byRawID[uint32(rawID)]; dup {
+		return p.errorf(name, "message %s reuses the ID of %s", msg.Name, prev.Name)
+	}
+	p.order = append(p.order, msg)
+	p.byRawID[uint32(rawID)] = msg
+	return nil
+}
+
+// parseSignal handles
+// "SG_ <name> [M|mN[M]] : <start>|<len>@<order><sign> (<factor>,<offset>) [<min>|<max>] "<unit>" <receivers>".
+func (p *dbcParser) parseSignal() (types.Signal, error) {
+	var sig types.Signal
+	name, err := p.expectKind(tokIdent, "signal name")
+	if err != nil {
+		return sig, err
+	}
+	sig.Name = name.text
+	// Multiplexer indicator: "M", "m<N>" or "m<N>M".
+	if t := p.peek(); t.kind == tokIdent {
+		p.pos++
+		if err := parseMuxIndicator(t, &sig); err != nil {
+			return sig, p.errorf(t, "%v", err)
+		}
+	}
+	if err := p.expect(":"); err != nil {
+		return sig, err
+	}
+	start, err := p.uintTok()
+	if err != nil {
+		return sig, err
+	}
+	if err := p.expect("|"); err != nil {
+		return sig, err
+	}
+	length, err := p.uintTok()
+	if err != nil {
+		return sig, err
+	}
+	if err := p.expect("@"); err != nil {
+		return sig, err
+	}
+	order, err := p.next()
+	if err != nil {
+		return sig, err
+	}
+	sign, err := p.next()
+	if err != nil {
+		return sig, err
+	}
+	switch order.text {
+	case "0":
+		sig.ByteOrder = "big_endian"
+	case "1":
+		sig.ByteOrder = "little_endian"
+	default:
+		return sig, p.errorf(order, "invalid byte order %q", order.text)
+	}
+	switch sign.text {
+	case "+":
+		sig.IsSigned = false
+	case "-":
+		sig.IsSigned = true
+	default:
+		return sig, p.errorf(sign, "invalid value type %q", sign.text)
+	}
+	sig.Start = int(start)
+	sig.Length = int(length)
+
+	if err := p.expect("("); err != nil {
+		return sig, err
+	}
+	if sig.Factor, err = p.floatTok(); err != nil {
+		return sig, err
+	}
+	if err := p.expect(","); err != nil {
+		return sig, err
+	}
+	if sig.Offset, err = p.floatTok(); err != nil {
+		return sig, err
+	}
+	if err := p.expect(")"); err != nil {
+		return sig, err
+	}
+
+	if err := p.expect("["); err != nil {
+		return sig, err
+	}
+	minimum, err := p.floatTok()
+	if err != nil {
+		return sig, err
+	}
+	if err := p.expect("|"); err != nil {
+		return sig, err
+	}
+	maximum, err := p.floatTok()
+	if err != nil {
+		return sig, err
+	}
+	if err := p.expect("]"); err != nil {
+		return sig, err
+	}
+	// Like cantools, a [0|0] range means "no limits".
+	if minimum != 0 || maximum != 0 {
+		sig.Minimum = &minimum
+		sig.Maximum = &maximum
+	}
+
+	unit, err := p.expectKind(tokString, "unit")
+	if err != nil {
+		return sig, err
+	}
+	sig.Unit = unit.text
+	sig.Choices = map[string]string{}
+
+	// Receivers: a comma separated node list on the same line.
+	for !p.eof() && p.peek().line == unit.line {
+		p.pos++
+	}
+	return sig, nil
+}
+
+// parseMuxIndicator applies a SG_ multiplexer indicator to sig.
+func parseMuxIndicator(t dbcToken, sig *types.Signal) error {
+	text := t.text
+	if strings.HasSuffix(text, "M") {
+		sig.IsMultiplexer = true
+		text = strings.TrimSuffix(text, "M")
+	}
+	if text == "" {
+		return nil
+	}
+	if !strings.HasPrefix(text, "m") {
+		return fmt.Errorf("invalid multiplexer indicator %q", t.text)
+	}
+	id, err := strconv.ParseUint(text[1:], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid multiplexer indicator %q", t.text)
+	}
+	sig.MultiplexerIDs = types.MuxRanges{{Lo: id, Hi: id}}
+	return nil
+}
+
+func (p *dbcParser) signalByName(rawID uint32, name string) *types.Signal {
+	msg, ok := p.byRawID[rawID]
+	if !ok {
+		return nil
+	}
+	for i := range msg.Signals {
+		if msg.Signals[i].Name == name {
+			return &msg.Signals[i]
+		}
+	}
+	return nil
+}
+
+// parseComment handles CM_ for the network, nodes, messages and signals.
+func (p *dbcParser) parseComment() error {
+	t := p.peek()
+	if t.kind == tokString {
+		p.pos++
+		return p.expect(";")
+	}
+	obj, err := p.expectKind(tokIdent, "object type")
+	if err != nil {
+		return err
+	}
+	switch obj.text {
+	case "BO_":
+		id, err := p.uintTok()
+		if err != nil {
+			return err
+		}
+		text, err := p.expectKind(tokString, "comment")
+		if err != nil {
+			return err
+		}
+		if msg, ok := p.byRawID[uint32(id)]; ok {
+			msg.Comment = text.text
+		}
+	case "SG_":
+		id, err := p.uintTok()
+		if err != nil {
+			return err
+		}
+		name, err := p.expectKind(tokIdent, "signal name")
+		if err != nil {
+			return err
+		}
+		text, err := p.expectKind(tokString, "comment")
+		if err != nil {
+			return err
+		}
+		if sig := p.signalByName(uint32(id), name.text); sig != nil {
+			sig.Comment = text.text
+		}
+	default:
+		// Node and environment variable comments are not represented.
+		p.skipStatement()
+		return nil
+	}
+	return p.expect(";")
+}
+
+// parseValueDescriptions handles "VAL_ <id> <signal> <value> "<label>" ... ;".
+func (p *dbcParser) parseValueDescriptions() error {
+	if p.peek().kind != tokNumber {
+		// Environment variable value descriptions.
+		p.skipStatement()
+		return nil
+	}
+	id, err := p.uintTok()
+	if err != nil {
+		return err
+	}
+	name, err := p.expectKind(tokIdent, "signal name")
+	if err != nil {
+		return err
+	}
+	sig := p.signalByName(uint32(id), name.text)
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.kind == tokPunct && t.text == ";" {
+			return nil
+		}
+		if t.kind != tokNumber {
+			return p.errorf(t, "expected value, got %q", t.text)
+		}
+		label, err := p.expectKind(tokString, "value label")
+		if err != nil {
+			return err
+		}
+		if sig != nil {
+			v, err := strconv.ParseFloat(t.text, 64)
+			if err != nil {
+				return p.errorf(t, "invalid value %q", t.text)
+			}
+			sig.Choices[strconv.FormatFloat(v, 'f', -1, 64)] = label.text
+		}
+	}
+}
+
+// parseAttrDef handles "BA_DEF_ [BU_|BO_|SG_|EV_] "<name>" <type> [params] ;".
+func (p *dbcParser) parseAttrDef() error {
+	def := &dbcAttrDef{}
+	if t := p.peek(); t.kind == tokIdent {
+		def.objType = t.text
+		p.pos++
+	}
+	name, err := p.expectKind(tokString, "attribute name")
+	if err != nil {
+		return err
+	}
+	typ, err := p.expectKind(tokIdent, "attribute type")
+	if err != nil {
+		return err
+	}
+	def.valType = typ.text
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.kind == tokPunct && t.text == ";" {
+			break
+		}
+		if def.valType == "ENUM" && t.kind == tokString {
+			def.enum = append(def.enum, t.text)
+		}
+	}
+	p.attrDefs[name.text] = def
+	return nil
+}
+
+// parseAttrDefault handles "BA_DEF_DEF_ "<name>" <value> ;".
+func (p *dbcParser) parseAttrDefault() error {
+	name, err := p.expectKind(tokString, "attribute name")
+	if err != nil {
+		return err
+	}
+	val, err := p.next()
+	if err != nil {
+		return err
+	}
+	if def, ok := p.attrDefs[name.text]; ok {
+		def.deflt = val.text
+	}
+	return p.expect(";")
+}
+
+// parseAttr handles "BA_ "<name>" [BU_ <node>|BO_ <id>|SG_ <id> <signal>|EV_ <var>] <value> ;".
+// Only message attributes are retained.
+func (p *dbcParser) parseAttr() error {
+	name, err := p.expectKind(tokString, "attribute name")
+	if err != nil {
+		return err
+	}
+	if t := p.peek(); t.kind == tokIdent && t.text == "BO_" {
+		p.pos++
+		id, err := p.uintTok()
+		if err != nil {
+			return err
+		}
+		val, err := p.next()
+		if err != nil {
+			return err
+		}
+		if p.msgAttrs[uint32(id)] == nil {
+			p.msgAttrs[uint32(id)] = make(map[string]string)
+		}
+		p.msgAttrs[uint32(id)][name.text] = val.text
+		return p.expect(";")
+	}
+	p.skipStatement()
+	return nil
+}
+
+// parseSigValType handles "SIG_VALTYPE_ <id> <signal> : <1|2> ;".
+func (p *dbcParser) parseSigValType() error {
+	id, err := p.uintTok()
+	if err != nil {
+		return err
+	}
+	name, err := p.expectKind(tokIdent, "signal name")
+	if err != nil {
+		return err
+	}
+	if err := p.expect(":"); err != nil {
+		return err
+	}
+	typ, err := p.uintTok()
+	if err != nil {
+		return err
+	}
+	if sig := p.signalByName(uint32(id), name.text); sig != nil {
+		sig.IsFloat = typ == 1 || typ == 2
+	}
+	return p.expect(";")
+}
+
+// parseExtendedMux handles
+// "SG_MUL_VAL_ <id> <signal> <switch> <lo>-<hi>[, <lo>-<hi>...] ;".
+func (p *dbcParser) parseExtendedMux() error {
+	id, err := p.uintTok()
+	if err != nil {
+		return err
+	}
+	name, err := p.expectKind(tokIdent, "signal name")
+	if err != nil {
+		return err
+	}
+	sw, err := p.expectKind(tokIdent, "multiplexer switch")
+	if err != nil {
+		return err
+	}
+	var ids []types.MuxRange
+	for {
+		lo, err := p.uintTok()
+		if err != nil {
+			return err
+		}
+		// The tokenizer reads "3-10" as "3" followed by "-10".
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		var hiText string
+		switch {
+		case t.kind == tokNumber && strings.HasPrefix(t.text, "-"):
+			hiText = t.text[1:]
+		case t.kind == tokPunct && t.text == "-":
+			hi, err := p.expectKind(tokNumber, "range end")
+			if err != nil {
+				return err
+			}
+			hiText = hi.text
+		default:
+			return p.errorf(t, "expected range, got %q", t.text)
+		}
+		hi, err := strconv.ParseUint(hiText, 0, 64)
+		if err != nil || hi < lo {
+			return p.errorf(t, "invalid multiplexer range %d-%s", lo, hiText)
+		}
+		ids = append(ids, types.MuxRange{Lo: lo, Hi: hi})
+
+		t, err = p.next()
+		if err != nil {
+			return err
+		}
+		if t.kind == tokPunct && t.text == ";" {
+			break
+		}
+		if t.kind != tokPunct || t.text != "," {
+			return p.errorf(t, "expected ',' or ';', got %q", t.text)
+		}
+	}
+	if sig := p.signalByName(uint32(id), name.text); sig != nil {
+		sig.MultiplexerSignal = sw.text
+		sig.MultiplexerIDs = types.NewMuxRanges(ids...)
+	}
+	return nil
+}
+
+// attrValue resolves a message attribute, falling back to its default and
+// translating enum indices to their labels.
+func (p *dbcParser) attrValue(rawID uint32, name string) (string, bool) {
+	def, defined := p.attrDefs[name]
+	val, ok := p.msgAttrs[rawID][name]
+	if !ok {
+		if !defined || def.deflt == "" {
+			return "", false
+		}
+		val = def.deflt
+	}
+	if defined && def.valType == "ENUM" {
+		if idx, err := strconv.Atoi(val); err == nil && idx >= 0 && idx < len(def.enum) {
+			val = def.enum[idx]
+		}
+	}
+	return val, true
+}
+
+// finish applies message-level attributes and returns the messages in file order.
+func (p *dbcParser) finish() []types.Message {
+	out := make([]types.Message, 0, len(p.order))
+	for rawID, msg := range p.byRawID {
+		if v, ok := p.attrValue(rawID, "VFrameFormat"); ok {
+			if strings.HasPrefix(v, "Extended") {
+				msg.IsExtendedFrame = true
+			}
+			msg.IsFD = strings.HasSuffix(v, "_FD")
+		}
+		if v, ok := p.attrValue(rawID, "GenMsgCycleTime"); ok {
+			if ms, err := strconv.Atoi(v); err == nil {
+				msg.CycleTime = ms
+			}
+		}
+	}
+	for _, msg := range p.order {
+		resolveSimpleMux(msg)
+		out = append(out, *msg)
+	}
+	return out
+}
+
+// resolveSimpleMux points multiplexed signals without an SG_MUL_VAL_ entry at
+// the message's multiplexer switch.
+func resolveSimpleMux(msg *types.Message) {
+	var mux string
+	for _, sig := range msg.Signals {
+		if sig.IsMultiplexer && len(sig.MultiplexerIDs) == 0 {
+			mux = sig.Name
+			break
+		}
+	}
+	if mux == "" {
+		return
+	}
+	for i := range msg.Signals {
+		if len(msg.Signals[i].MultiplexerIDs) > 0 && msg.Signals[i].MultiplexerSignal == "" {
+			msg.Signals[i].MultiplexerSignal = mux
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package candecoder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+const ucrJSON = "../../configs/UCR-01.json"
+
+func parseTestDBC(t *testing.T, src string) []types.Message {
+	t.Helper()
+	messages, err := ParseDBC(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseDBC: %v", err)
+	}
+	return messages
+}
+
+func TestParseDBCMessagesAndSignals(t *testing.T) {
+	messages := parseTestDBC(t, `VERSION "1.0"
+
+NS_ :
+	NS_DESC_
+	CM_
+
+BS_:
+
+BU_: VCU BMS
+
+BO_ 1712 BMS_Pack: 6 BMS
+ SG_ PackVoltage : 0|16@1+ (0.1,0) [0|600] "V" VCU
+ SG_ PackCurrent : 23|16@0- (0.05,-10) [0|0] "A" VCU,Logger
+ SG_ Ratio : 32|32@1- (1,0) [-1E+038|1E+038] "" VCU
+
+BO_ 2566869221 Charger: 8 VCU
+ SG_ State : 0|8@1+ (1,0) [0|3] "" BMS
+
+CM_ "Network comment";
+CM_ BU_ VCU "Node comment";
+CM_ BO_ 1712 "Pack totals";
+CM_ SG_ 1712 PackVoltage "Sum of cells";
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 10000;
+BA_DEF_ BO_ "VFrameFormat" ENUM "StandardCAN","ExtendedCAN","StandardCAN_FD","ExtendedCAN_FD";
+BA_DEF_DEF_ "GenMsgCycleTime" 0;
+BA_DEF_DEF_ "VFrameFormat" "StandardCAN";
+BA_ "GenMsgCycleTime" BO_ 1712 100;
+BA_ "VFrameFormat" BO_ 2566869221 3;
+BA_ "GenSigStartValue" SG_ 1712 PackVoltage 0;
+VAL_ 2566869221 State 0 "Off" 1 "Charging" 2 "Done" 3 "Fault" ;
+SIG_VALTYPE_ 1712 Ratio : 1;
+`)
+	f := func(v float64) *float64 { return &v }
+	want := []types.Message{
+		{
+			FrameID: 1712, Name: "BMS_Pack", Length: 6, CycleTime: 100, Comment: "Pack totals",
+			Signals: []types.Signal{
+				{Name: "PackVoltage", Start: 0, Length: 16, ByteOrder: "little_endian", Factor: 0.1,
+					Minimum: f(0), Maximum: f(600), Unit: "V", Choices: map[string]string{}, Comment: "Sum of cells"},
+				{Name: "PackCurrent", Start: 23, Length: 16, ByteOrder: "big_endian", IsSigned: true, Factor: 0.05,
+					Offset: -10, Unit: "A", Choices: map[string]string{}},
+				{Name: "Ratio", Start: 32, Length: 32, ByteOrder: "little_endian", IsSigned: true, IsFloat: true, Factor: 1,
+					Minimum: f(-1e38), Maximum: f(1e38), Choices: map[string]string{}},
+			},
+		},
+		{
+			FrameID: 0x18FF50E5, Name: "Charger", IsExtendedFrame: true, IsFD: true, Length: 8,
+			Signals: []types.Signal{
+				{Name: "State", Start: 0, Length: 8, ByteOrder: "little_endian", Factor: 1, Minimum: f(0), Maximum: f(3),
+					Choices: map[string]string{"0": "Off", "1": "Charging", "2": "Done", "3": "Fault"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("ParseDBC =\n%+v\nwant\n%+v", messages, want)
+	}
+}
+
+func TestParseDBCMultiplexers(t *testing.T) {
+	messages := parseTestDBC(t, `VERSION ""
+
+BO_ 100 Simple: 8 ECU
+ SG_ Mux M : 0|8@1+ (1,0) [0|0] "" Vector__XXX
+ SG_ A m0 : 8|8@1+ (1,0) [0|0] "" Vector__XXX
+ SG_ B m1 : 8|16@1+ (1,0) [0|0] "" Vector__XXX
+
+BO_ 200 Extended: 8 ECU
+ SG_ Top M : 0|8@1+ (1,0) [0|0] "" Vector__XXX
+ SG_ Sub m2M : 8|8@1+ (1,0) [0|0] "" Vector__XXX
+ SG_ Leaf m0 : 16|8@1+ (1,0) [0|0] "" Vector__XXX
+ SG_ Wide m0 : 24|32@1+ (1,0) [0|0] "" Vector__XXX
+
+SG_MUL_VAL_ 200 Sub Top 1-3, 5-5, 4-4;
+SG_MUL_VAL_ 200 Leaf Sub 7-7;
+SG_MUL_VAL_ 200 Wide Top 0-4294967295;
+`)
+	mux := func(msg types.Message, name string) (bool, types.MuxRanges, string) {
+		for _, sig := range msg.Signals {
+			if sig.Name == name {
+				return sig.IsMultiplexer, sig.MultiplexerIDs, sig.MultiplexerSignal
+			}
+		}
+		t.Fatalf("%s: no signal %s", msg.Name, name)
+		return false, nil, ""
+	}
+	tests := []struct {
+		msg, signal string
+		isMux       bool
+		ids         types.MuxRanges
+		selector    string
+	}{
+		{"Simple", "Mux", true, nil, ""},
+		{"Simple", "A", false, types.MuxRanges{{Lo: 0, Hi: 0}}, "Mux"},
+		{"Simple", "B", false, types.MuxRanges{{Lo: 1, Hi: 1}}, "Mux"},
+		{"Extended", "Top", true, nil, ""},
+		{"Extended", "Sub", true, types.MuxRanges{{Lo: 1, Hi: 5}}, "Top"},
+		{"Extended", "Leaf", false, types.MuxRanges{{Lo: 7, Hi: 7}}, "Sub"},
+		{"Extended", "Wide", false, types.MuxRanges{{Lo: 0, Hi: 4294967295}}, "Top"},
+	}
+	byName := map[string]types.Message{}
+	for _, msg := range messages {
+		byName[msg.Name] = msg
+	}
+	for _, tt := range tests {
+		isMux, ids, selector := mux(byName[tt.msg], tt.signal)
+		if isMux != tt.isMux || !reflect.DeepEqual(ids, tt.ids) || selector != tt.selector {
+			t.Errorf("%s.%s = %v %v %q, want %v %v %q", tt.msg, tt.signal, isMux, ids, selector, tt.isMux, tt.ids, tt.selector)
+		}
+	}
+}
+
+func TestParseDBCErrors(t *testing.T) {
+	const bo = "BO_ 100 A: 8 ECU\n SG_ S : 0|8@1+ (1,0) [0|0] \"\" X\n"
+	tests := []struct {
+		name, src, want string
+	}{
+		{"signal outside message", `SG_ S : 0|8@1+ (1,0) [0|0] "" X`, "SG_ outside of BO_"},
+		{"missing colon", "BO_ 100 A 8 ECU\n", `expected ":"`},
+		{"bad byte order", "BO_ 100 A: 8 ECU\n SG_ S : 0|8@2+ (1,0) [0|0] \"\" X\n", "invalid byte order"},
+		{"bad value type", "BO_ 100 A: 8 ECU\n SG_ S : 0|8@1* (1,0) [0|0] \"\" X\n", "invalid value type"},
+		{"bad mux indicator", "BO_ 100 A: 8 ECU\n SG_ S x1 : 0|8@1+ (1,0) [0|0] \"\" X\n", "invalid multiplexer indicator"},
+		{"missing unit", "BO_ 100 A: 8 ECU\n SG_ S : 0|8@1+ (1,0) [0|0] X\n", "expected unit"},
+		{"unterminated string", "VERSION \"1.0\n", "unterminated string"},
+		{"truncated signal", "BO_ 100 A: 8 ECU\n SG_ S : 0|8@1+ (1,0", "unexpected end of file"},
+		{"duplicate ID", bo + "BO_ 100 B: 8 ECU\n", "message B reuses the ID of A"},
+		{"reversed mux range", bo + "SG_MUL_VAL_ 100 S M 5-3;\n", "invalid multiplexer range"},
+		{"value without label", bo + `VAL_ 100 S 0 "Off" 1 ;`, "expected value label"},
+		{"label without value", bo + `VAL_ 100 S "Off" ;`, "expected value"},
+	}
+	for _, tt := range tests {
+		_, err := ParseDBC(strings.NewReader(tt.src))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: ParseDBC error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestDBCMatchesJSON(t *testing.T) {
+	_, fromDBC, err := LoadDBCDefinitions(ucrDBC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, fromJSON, err := LoadJSONDefinitions(ucrJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fromDBC) != len(fromJSON) {
+		t.Errorf("DBC has %d messages, JSON %d", len(fromDBC), len(fromJSON))
+	}
+	for id, want := range fromJSON {
+		got, ok := fromDBC[id]
+		if !ok {
+			t.Errorf("%v %s missing from the DBC", id, want.Name)
+			continue
+		}
+		// The cantools export carries neither comments nor cycle times.
+		got.Comment, got.CycleTime = "", 0
+		for i := range got.Signals {
+			got.Signals[i].Comment = ""
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%v %s differs:\nDBC  %+v\nJSON %+v", id, want.Name, got, want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// encode.go
+//
+// CAN frame encoding. EncodeMessage is the inverse of DecodeMessage: it turns
+// physical signal values back into the raw payload of a message.
+package candecoder
+
+import (
+	"fmt"
+	"math"
+
+	"telem-system/pkg/types"
+)
+
+// EncodeMessage builds the msg.Length byte payload for the given physical
+// values. Factor and offset are removed, integer signals are rounded to the
+// nearest raw value and range checked, and float signals are stored as IEEE
+// 32 or 64 bit values. Signals without an entry in values are encoded as raw 0.
+//
+// For multiplexed messages the multiplexer values are written first and only
+// the signals they select are encoded; values for inactive signals are ignored.
+func EncodeMessage(msg types.Message, values map[string]float64) ([]byte, error) {
+	known := make(map[string]bool, len(msg.Signals))
+	for _, sig := range msg.Signals {
+		known[sig.Name] = true
+	}
+	for name := range values {
+		if !known[name] {
+			return nil, fmt.Errorf("message %s has no signal %s", msg.Name, name)
+		}
+	}
+
+	data := make([]byte, msg.Length)
+	written := make(map[string]bool, len(msg.Signals))
+	for {
+		// Selectors decide which signals are active, so settle every active
+		// multiplexer before writing any of the signals it selects.
+		var muxes, rest []types.Signal
+		for _, sig := range activeSignals(data, msg) {
+			switch {
+			case written[sig.Name]:
+			case sig.IsMultiplexer:
+				muxes = append(muxes, sig)
+			default:
+				rest = append(rest, sig)
+			}
+		}
+		pending := rest
+		if len(muxes) > 0 {
+			pending = muxes
+		}
+		for _, sig := range pending {
+			if err := encodeSignal(data, sig, msg.Length, values[sig.Name]); err != nil {
+				return nil, err
+			}
+			written[sig.Name] = true
+		}
+		if len(muxes) == 0 {
+			return data, nil
+		}
+	}
+}
+
+// encodeSignal writes the physical value of one signal into data.
+func encodeSignal(data []byte, signal types.Signal, msgLength int, value float64) error {
+	if !signalFits(signal, msgLength) || signal.Length > 64 {
+		return fmt.Errorf("signal %s out of bounds", signal.Name)
+	}
+	raw, err := rawFromPhysical(signal, value)
+	if err != nil {
+		return err
+	}
+	if isBigEndian(signal) {
+		insertBigEndian(data, signal.Start, signal.Length, raw)
+	} else {
+		insertLittleEndian(data, signal.Start, signal.Length, raw)
+	}
+	return nil
+}
+
+// rawFromPhysical converts a physical value to the signal's raw bit pattern.
+func rawFromPhysical(signal types.Signal, value float64) (uint64, error) {
+	if signal.Factor == 0 {
+		return 0, fmt.Errorf("signal %s has a zero factor", signal.Name)
+	}
+	scaled := (value - signal.Offset) / signal.Factor
+	if signal.IsFloat {
+		switch signal.Length {
+		case 32:
+			return uint64(math.Float32bits(float32(scaled))), nil
+		case 64:
+			return math.Float64bits(scaled), nil
+		}
+		return 0, fmt.Errorf("unsupported float length %d for %s", signal.Length, signal.Name)
+	}
+
+	// Comparisons with NaN are always false, so non-finite values would pass
+	// the range checks below and convert to an arbitrary bit pattern.
+	if math.IsNaN(scaled) || math.IsInf(scaled, 0) {
+		return 0, fmt.Errorf("value %v is not finite for signal %s", value, signal.Name)
+	}
+	scaled = math.Round(scaled)
+	if signal.IsSigned {
+		limit := math.Ldexp(1, signal.Length-1)
+		if scaled < -limit || scaled >= limit {
+			return 0, fmt.Errorf("value %v out of range for signal %s", value, signal.Name)
+		}
+		raw := uint64(int64(scaled))
+		if signal.Length < 64 {
+			raw &= 1<<signal.Length - 1
+		}
+		return raw, nil
+	}
+	if scaled < 0 || scaled >= math.Ldexp(1, signal.Length) {
+		return 0, fmt.Errorf("value %v out of range for signal %s", value, signal.Name)
+	}
+	return uint64(scaled), nil
+}
+
+// insertLittleEndian writes an Intel signal whose LSB is at bit startBit.
+func insertLittleEndian(data []byte, startBit, length int, raw uint64) {
+	for i := 0; i < length; i++ {
+		pos := startBit + i
+		data[pos/8] &^= 1 << (pos % 8)
+		data[pos/8] |= byte((raw>>i)&1) << (pos % 8)
+	}
+}
+
+// insertBigEndian writes a Motorola signal whose MSB is at DBC bit startBit,
+// following the same sawtooth walk as extractBigEndian.
+func insertBigEndian(data []byte, startBit, length int, raw uint64) {
+	pos := startBit
+	for i := length - 1; i >= 0; i-- {
+		data[pos/8] &^= 1 << (pos % 8)
+		data[pos/8] |= byte((raw>>i)&1) << (pos % 8)
+		if pos%8 == 0 {
+			pos += 15
+		} else {
+			pos--
+		}
+	}
+}
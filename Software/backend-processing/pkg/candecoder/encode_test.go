@@ -0,0 +1,83 @@
+package candecoder
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+func TestEncodeMessageRoundTrip(t *testing.T) {
+	msg := types.Message{
+		Name:   "Mixed",
+		Length: 16,
+		Signals: []types.Signal{
+			{Name: "Temp", Start: 3, Length: 12, ByteOrder: "big_endian", IsSigned: true, Factor: 0.5, Offset: -40},
+			{Name: "Speed", Start: 16, Length: 16, ByteOrder: "little_endian", Factor: 0.1},
+			{Name: "Trim", Start: 33, Length: 5, ByteOrder: "little_endian", IsSigned: true, Factor: 1},
+			{Name: "Ratio", Start: 64, Length: 32, ByteOrder: "little_endian", IsFloat: true, Factor: 1},
+			{Name: "Gain", Start: 103, Length: 32, ByteOrder: "big_endian", IsFloat: true, Factor: 2},
+		},
+	}
+	values := map[string]float64{"Temp": -100, "Speed": 123.4, "Trim": -7, "Ratio": 0.25, "Gain": -3}
+	data, err := EncodeMessage(msg, values)
+	if err != nil {
+		t.Fatalf("EncodeMessage: %v", err)
+	}
+	frame, err := DecodeFrame(data, msg)
+	if err != nil {
+		t.Fatalf("DecodeFrame: %v", err)
+	}
+	for name, want := range values {
+		if got := frame.Float(name); math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestEncodeMultiplexedMessage(t *testing.T) {
+	msgs, err := ParseDBC(strings.NewReader(muxTestDBC))
+	if err != nil {
+		t.Fatalf("ParseDBC: %v", err)
+	}
+	data, err := EncodeMessage(msgs[0], map[string]float64{"REGID": 49, "Page": 7, "TempA": 5, "TempB": 100})
+	if err != nil {
+		t.Fatalf("EncodeMessage: %v", err)
+	}
+	if want := []byte{49, 7, 0xE8, 0x03, 0, 0}; !reflect.DeepEqual(data, want) {
+		t.Errorf("got % X, want % X", data, want)
+	}
+	data, err = EncodeMessage(msgs[0], map[string]float64{"REGID": 48, "Speed": 1000})
+	if err != nil {
+		t.Fatalf("EncodeMessage: %v", err)
+	}
+	if want := []byte{48, 0xE8, 0x03, 0, 0, 0}; !reflect.DeepEqual(data, want) {
+		t.Errorf("got % X, want % X", data, want)
+	}
+}
+
+func TestEncodeMessageErrors(t *testing.T) {
+	msg := types.Message{
+		Name:   "Small",
+		Length: 1,
+		Signals: []types.Signal{
+			{Name: "Level", Start: 0, Length: 4, ByteOrder: "little_endian", Factor: 1},
+			{Name: "Trim", Start: 4, Length: 4, ByteOrder: "little_endian", IsSigned: true, Factor: 1},
+		},
+	}
+	for name, values := range map[string]map[string]float64{
+		"above range":     {"Level": 16},
+		"negative":        {"Level": -1},
+		"unknown signal":  {"Other": 1},
+		"NaN":             {"Level": math.NaN()},
+		"infinity":        {"Level": math.Inf(1)},
+		"signed NaN":      {"Trim": math.NaN()},
+		"signed infinity": {"Trim": math.Inf(-1)},
+	} {
+		if _, err := EncodeMessage(msg, values); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
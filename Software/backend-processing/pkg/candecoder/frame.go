@@ -0,0 +1,179 @@
+// frame.go
+//
+// Typed decode API. DecodeFrame returns numeric signal values directly so
+// callers do not have to format and re-parse strings for every frame.
+package candecoder
+
+import (
+	"math"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// ValueKind describes how a decoded signal value should be interpreted.
+type ValueKind uint8
+
+const (
+	// KindFloat is an IEEE float signal or a scaled value with a fractional
+	// factor/offset; use SignalValue.Float.
+	KindFloat ValueKind = iota
+	// KindInt is a signed integer signal; use SignalValue.Int.
+	KindInt
+	// KindUint is an unsigned integer signal; use SignalValue.Uint.
+	KindUint
+)
+
+// SignalValue is a single decoded signal.
+type SignalValue struct {
+	Name  string
+	Kind  ValueKind
+	Float float64 // Physical value; set for every kind.
+	Int   int64   // Set for KindInt.
+	Uint  uint64  // Set for KindUint.
+	Label string  // Choice label, if the value has a description.
+	Valid bool    // False if the signal could not be decoded.
+
+	// OutOfRange is set when the decoded value lies outside the signal's
+	// minimum/maximum; see RangePolicy for how the value is then treated.
+	OutOfRange bool
+}
+
+// setPhysical stores a physical value and derives the integer views from it.
+func (sv *SignalValue) setPhysical(v float64) {
+	sv.Float = v
+	sv.Int, sv.Uint = 0, 0
+	switch sv.Kind {
+	case KindInt:
+		sv.Int = int64(v)
+	case KindUint:
+		if v > 0 {
+			sv.Uint = uint64(v)
+		}
+	}
+}
+
+// setInt stores an exact signed integer value, for signals whose raw value is
+// their physical value. Float holds the nearest float64.
+func (sv *SignalValue) setInt(v int64) {
+	sv.Float, sv.Int, sv.Uint = float64(v), v, 0
+}
+
+// setUint stores an exact unsigned integer value; see setInt.
+func (sv *SignalValue) setUint(v uint64) {
+	sv.Float, sv.Int, sv.Uint = float64(v), 0, v
+}
+
+// DecodedFrame holds the decoded signals of one CAN frame in definition order.
+// For multiplexed messages only the active signals are present.
+type DecodedFrame struct {
+	ID      types.CANID
+	Message string
+	Signals []SignalValue
+
+	// Timestamp is the capture time of a frame replayed from a log or
+	// timestamped by the kernel on receipt. Decoding clears it; callers set it
+	// after decoding, and a zero Timestamp means the frame was received now.
+	Timestamp time.Time
+
+	active []uint8 // multiplexer scratch space reused by Plan.Decode
+}
+
+// Time returns the frame's capture time, or the current time for frames
+// received live.
+func (f *DecodedFrame) Time() time.Time {
+	if f.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return f.Timestamp
+}
+
+// Value returns the named signal and whether it is present in the frame.
+func (f *DecodedFrame) Value(name string) (SignalValue, bool) {
+	if f == nil {
+		return SignalValue{}, false
+	}
+	for i := range f.Signals {
+		if f.Signals[i].Name == name {
+			return f.Signals[i], true
+		}
+	}
+	return SignalValue{}, false
+}
+
+// Float returns the physical value of the named signal, or 0 if it is missing
+// or invalid.
+func (f *DecodedFrame) Float(name string) float64 {
+	if v, ok := f.Value(name); ok && v.Valid {
+		return v.Float
+	}
+	return 0
+}
+
+// Int returns the named signal as an int, truncating fractional values.
+// It returns 0 if the signal is missing or invalid.
+func (f *DecodedFrame) Int(name string) int {
+	v, ok := f.Value(name)
+	if !ok || !v.Valid {
+		return 0
+	}
+	switch v.Kind {
+	case KindInt:
+		return int(v.Int)
+	case KindUint:
+		return int(v.Uint)
+	default:
+		return int(v.Float)
+	}
+}
+
+// Label returns the choice label of the named signal, or "" if none applies.
+func (f *DecodedFrame) Label(name string) string {
+	v, _ := f.Value(name)
+	return v.Label
+}
+
+// DecodeFrame decodes raw CAN data into typed signal values. Values outside
+// the DBC limits are counted and handled according to the RangePolicy, and
+// signals extending past the end of a short payload are marked invalid, as
+// Plan.Decode does.
+func DecodeFrame(data []byte, msg types.Message) (*DecodedFrame, error) {
+	signals := activeSignals(data, msg)
+	frame := &DecodedFrame{
+		ID:      msg.Key(),
+		Message: msg.Name,
+		Signals: make([]SignalValue, 0, len(signals)),
+	}
+	for _, signal := range signals {
+		sv := SignalValue{Name: signal.Name, Kind: valueKind(signal)}
+		val, err := decodeSignal(data, signal, msg.Length)
+		if err == nil {
+			sv.Valid = true
+			switch v := val.(type) {
+			case float64:
+				sv.setPhysical(v)
+			case int64:
+				sv.setInt(v)
+			case uint64:
+				sv.setUint(v)
+			}
+			if label, ok := choiceLabel(data, signal, msg.Length); ok {
+				sv.Label = label
+			}
+			checkRange(&sv, msg.Name, signal)
+		}
+		frame.Signals = append(frame.Signals, sv)
+	}
+	return frame, nil
+}
+
+// valueKind classifies a signal from its definition.
+func valueKind(signal types.Signal) ValueKind {
+	if signal.IsFloat || signal.Factor != math.Trunc(signal.Factor) || signal.Offset != math.Trunc(signal.Offset) {
+		return KindFloat
+	}
+	if signal.IsSigned {
+		return KindInt
+	}
+	return KindUint
+}
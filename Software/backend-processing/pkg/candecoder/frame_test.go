@@ -0,0 +1,48 @@
+package candecoder
+
+import (
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+func TestDecodeFrameTyped(t *testing.T) {
+	msg := types.Message{
+		FrameID: 9,
+		Name:    "Mixed",
+		Length:  4,
+		Signals: []types.Signal{
+			{Name: "Status", Start: 0, Length: 8, ByteOrder: "little_endian", Factor: 1,
+				Choices: map[string]string{"2": "RUNNING"}},
+			{Name: "Temp", Start: 15, Length: 8, ByteOrder: "big_endian", IsSigned: true, Factor: 1},
+			{Name: "Voltage", Start: 16, Length: 16, ByteOrder: "little_endian", Factor: 0.01},
+			{Name: "Missing", Start: 40, Length: 8, ByteOrder: "little_endian", Factor: 1},
+		},
+	}
+	frame, err := DecodeFrame([]byte{2, 0xF6, 0xE8, 0x03}, msg)
+	if err != nil {
+		t.Fatalf("DecodeFrame: %v", err)
+	}
+	if frame.ID != 9 || len(frame.Signals) != 4 {
+		t.Fatalf("unexpected frame %+v", frame)
+	}
+
+	status, _ := frame.Value("Status")
+	if status.Kind != KindUint || status.Uint != 2 || status.Label != "RUNNING" || !status.Valid {
+		t.Errorf("Status = %+v", status)
+	}
+	temp, _ := frame.Value("Temp")
+	if temp.Kind != KindInt || temp.Int != -10 || frame.Int("Temp") != -10 {
+		t.Errorf("Temp = %+v", temp)
+	}
+	volt, _ := frame.Value("Voltage")
+	if volt.Kind != KindFloat || frame.Float("Voltage") != 10 {
+		t.Errorf("Voltage = %+v", volt)
+	}
+	if missing, ok := frame.Value("Missing"); !ok || missing.Valid {
+		t.Errorf("Missing = %+v, present %v", missing, ok)
+	}
+	if frame.Float("Nope") != 0 || frame.Label("Nope") != "" {
+		t.Error("unknown signal should read as zero")
+	}
+}
@@ -0,0 +1,95 @@
+// framebatch.go
+//
+// Binary framing of the /telemetry WebSocket. A client that offers the
+// FrameBatchSubprotocol sends CANFrameBatch protobufs (proto/canframes.proto)
+// as binary messages, many frames to a message, each with its DLC, flags and
+// a capture time relative to the batch's sender timestamp. Clients that offer
+// no subprotocol keep sending one hex-encoded frame per text message.
+package candecoder
+
+import (
+	"fmt"
+	"time"
+
+	"telem-system/pkg/types"
+
+	"telem-system/proto"
+
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// FrameBatchSubprotocol is the WebSocket subprotocol of the batched framing.
+const FrameBatchSubprotocol = "telem.canframes.v1"
+
+const (
+	batchFlagExtended = uint32(proto.CANFrameFlag_CAN_FRAME_FLAG_EXTENDED)
+	batchFlagFD       = uint32(proto.CANFrameFlag_CAN_FRAME_FLAG_FD)
+	batchFlagSkipped  = uint32(proto.CANFrameFlag_CAN_FRAME_FLAG_REMOTE | proto.CANFrameFlag_CAN_FRAME_FLAG_ERROR)
+)
+
+// ParseFrameBatch returns the data frames of a CANFrameBatch message and its
+// sequence number. Remote and error frames are left out, as are frames whose
+// identifier or data disagree with their flags and DLC; skipped counts the
+// latter. Frames of a batch without a sender timestamp have a zero Time.
+func ParseFrameBatch(b []byte) (frames []Frame, seq uint64, skipped int, err error) {
+	var batch proto.CANFrameBatch
+	if err := protobuf.Unmarshal(b, &batch); err != nil {
+		return nil, 0, 0, fmt.Errorf("parse CAN frame batch: %v", err)
+	}
+	frames = make([]Frame, 0, len(batch.Frames))
+	for _, f := range batch.Frames {
+		if f.Flags&batchFlagSkipped != 0 {
+			continue
+		}
+		extended := f.Flags&batchFlagExtended != 0
+		fd := f.Flags&batchFlagFD != 0
+		if extended && f.Id > 0x1FFFFFFF || !extended && f.Id > 0x7FF ||
+			f.Dlc > 15 || len(f.Data) != DLCToLength(int(f.Dlc), fd) {
+			skipped++
+			continue
+		}
+		frame := Frame{ID: types.NewCANID(f.Id, extended), Data: f.Data}
+		if batch.SenderTimeUs != 0 {
+			frame.Time = time.UnixMicro(batch.SenderTimeUs + int64(f.TimeOffsetUs))
+		}
+		frames = append(frames, frame)
+	}
+	return frames, batch.Sequence, skipped, nil
+}
+
+// AppendFrameBatch appends the CANFrameBatch encoding of frames to dst. The
+// batch is stamped with the first frame's time and later frames are offset
+// from it; if that time is zero the receiver times the frames on arrival.
+// Payloads longer than 8 bytes are sent as CAN FD frames and must have a
+// valid CAN FD length.
+func AppendFrameBatch(dst []byte, seq uint64, frames []Frame) ([]byte, error) {
+	batch := proto.CANFrameBatch{Sequence: seq, Frames: make([]*proto.CANFrame, len(frames))}
+	var start time.Time
+	if len(frames) > 0 && !frames[0].Time.IsZero() {
+		start = frames[0].Time
+		batch.SenderTimeUs = start.UnixMicro()
+	}
+	for i, f := range frames {
+		fd := len(f.Data) > 8
+		dlc := LengthToDLC(len(f.Data))
+		if DLCToLength(dlc, fd) != len(f.Data) {
+			return dst, fmt.Errorf("frame %v length %d is not a CAN FD length", f.ID, len(f.Data))
+		}
+		pf := &proto.CANFrame{Id: f.ID.ID(), Dlc: uint32(dlc), Data: f.Data}
+		if f.ID.Extended() {
+			pf.Flags |= batchFlagExtended
+		}
+		if fd {
+			pf.Flags |= batchFlagFD
+		}
+		if !start.IsZero() && !f.Time.IsZero() {
+			offset := f.Time.Sub(start).Microseconds()
+			if offset < 0 || offset > 0xFFFFFFFF {
+				return dst, fmt.Errorf("frame %d is %d µs from the batch start", i+1, offset)
+			}
+			pf.TimeOffsetUs = uint32(offset)
+		}
+		batch.Frames[i] = pf
+	}
+	return protobuf.MarshalOptions{}.MarshalAppend(dst, &batch)
+}
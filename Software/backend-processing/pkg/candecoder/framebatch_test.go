@@ -0,0 +1,65 @@
+package candecoder
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"telem-system/pkg/types"
+
+	"telem-system/proto"
+
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+func TestFrameBatchRoundTrip(t *testing.T) {
+	start := time.UnixMicro(1700000000123456)
+	want := []Frame{
+		{Time: start, ID: types.NewCANID(0x65, false), Data: []byte{1, 2, 3}},
+		{Time: start.Add(950 * time.Microsecond), ID: types.NewCANID(0x18FF50E5, true), Data: bytes.Repeat([]byte{0xAA}, 8)},
+		{Time: start.Add(1800 * time.Microsecond), ID: types.NewCANID(0x6, false), Data: bytes.Repeat([]byte{0x55}, 48)},
+	}
+	b, err := AppendFrameBatch(nil, 7, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, seq, skipped, err := ParseFrameBatch(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != 7 || skipped != 0 {
+		t.Errorf("seq, skipped = %d, %d, want 7, 0", seq, skipped)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || got[i].ID != want[i].ID || !bytes.Equal(got[i].Data, want[i].Data) {
+			t.Errorf("frame %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Without a capture time the frames are timed on arrival.
+	b, _ = AppendFrameBatch(nil, 0, []Frame{{ID: types.NewCANID(0x65, false), Data: []byte{1}}})
+	if got, _, _, _ := ParseFrameBatch(b); len(got) != 1 || !got[0].Time.IsZero() {
+		t.Errorf("untimed batch = %+v", got)
+	}
+
+	if _, err := AppendFrameBatch(nil, 0, []Frame{{ID: types.NewCANID(0x65, false), Data: make([]byte, 10)}}); err == nil {
+		t.Error("10-byte frame encoded")
+	}
+	// Remote frames are dropped; frames whose data disagrees with their DLC
+	// or whose ID does not fit its format are counted as skipped.
+	b, _ = protobuf.Marshal(&proto.CANFrameBatch{Frames: []*proto.CANFrame{
+		{Id: 0x65, Dlc: 2, Flags: uint32(proto.CANFrameFlag_CAN_FRAME_FLAG_REMOTE)},
+		{Id: 0x65, Dlc: 3, Data: []byte{1, 2}},
+		{Id: 0x800, Dlc: 1, Data: []byte{1}},
+		{Id: 0x65, Dlc: 9, Data: make([]byte, 12), Flags: uint32(proto.CANFrameFlag_CAN_FRAME_FLAG_FD)},
+	}})
+	if got, _, skipped, err := ParseFrameBatch(b); err != nil || len(got) != 1 || len(got[0].Data) != 12 || skipped != 2 {
+		t.Errorf("filtered batch = %d frames, %d skipped, %v", len(got), skipped, err)
+	}
+	if _, _, _, err := ParseFrameBatch([]byte{0x0A, 0x05}); err == nil {
+		t.Error("truncated batch accepted")
+	}
+}
@@ -0,0 +1,299 @@
+// kvaser.go
+//
+// Kvaser CSV log parsing. Rows follow the layout of the header row
+// (Time,Channel,id,Flags,DLC,Data0..Data63,Counter,AbsTime); the DLC column
+// holds the raw code, which for CAN FD frames means 12 to 64 bytes for 9-15.
+package candecoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// Kvaser message flags as written to the Flags column of CSV logs.
+const (
+	KvaserFlagRTR        = 0x00001 // remote request frame
+	KvaserFlagSTD        = 0x00002 // 11-bit identifier
+	KvaserFlagEXT        = 0x00004 // 29-bit identifier
+	KvaserFlagErrorFrame = 0x00020 // bus error frame
+	KvaserFlagFDF        = 0x10000 // CAN FD frame
+	KvaserFlagBRS        = 0x20000 // CAN FD bit rate switch
+	KvaserFlagESI        = 0x40000 // CAN FD error state indicator
+)
+
+// KvaserAbsTimeLayout is the format of the AbsTime column, in local time.
+const KvaserAbsTimeLayout = "2006-01-02 15:04:05"
+
+// fdLengths maps CAN FD DLC codes 9-15 to payload lengths.
+var fdLengths = [...]int{9: 12, 10: 16, 11: 20, 12: 24, 13: 32, 14: 48, 15: 64}
+
+// DLCToLength returns the payload length for a DLC code. Classic frames carry
+// at most 8 bytes whatever the code; CAN FD codes 9-15 map to 12-64 bytes.
+func DLCToLength(dlc int, fd bool) int {
+	switch {
+	case dlc < 0:
+		return 0
+	case dlc <= 8:
+		return dlc
+	case !fd:
+		return 8
+	case dlc < len(fdLengths):
+		return fdLengths[dlc]
+	}
+	return 64
+}
+
+// LengthToDLC returns the smallest DLC code whose CAN FD payload holds n bytes.
+func LengthToDLC(n int) int {
+	if n <= 8 {
+		return n
+	}
+	for dlc := 9; dlc < len(fdLengths); dlc++ {
+		if fdLengths[dlc] >= n {
+			return dlc
+		}
+	}
+	return 15
+}
+
+// Errors returned by CheckLength.
+var (
+	ErrFrameTooShort = errors.New("frame shorter than definition")
+	ErrFrameTooLong  = errors.New("frame longer than definition")
+)
+
+// CheckLength compares the payload length of a received frame with its
+// definition. Frames too short to hold every signal return ErrFrameTooShort;
+// Plan.Decode marks the missing signals invalid. Longer frames return
+// ErrFrameTooLong unless the extra bytes are the padding up to the next CAN FD
+// length.
+func CheckLength(msg types.Message, n int) error {
+	switch {
+	case n < msg.Length:
+		return fmt.Errorf("%s (%s): %w: %d < %d bytes", msg.Name, msg.Key(), ErrFrameTooShort, n, msg.Length)
+	case n > DLCToLength(LengthToDLC(msg.Length), true):
+		return fmt.Errorf("%s (%s): %w: %d > %d bytes", msg.Name, msg.Key(), ErrFrameTooLong, n, msg.Length)
+	}
+	return nil
+}
+
+// ParseKvaserID builds the CANID of a Kvaser CSV row from its decimal id and
+// Flags columns. Identifiers wider than 11 bits are treated as extended even
+// if the flags column is empty.
+func ParseKvaserID(idField, flagsField string) (types.CANID, error) {
+	id, err := strconv.ParseUint(strings.TrimSpace(idField), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("parse CAN id '%s': %v", idField, err)
+	}
+	extended := id > 0x7FF
+	if f := strings.TrimSpace(flagsField); f != "" {
+		flags, err := strconv.ParseUint(f, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("parse flags '%s': %v", flagsField, err)
+		}
+		extended = extended || flags&KvaserFlagEXT != 0
+	}
+	return types.NewCANID(uint32(id), extended), nil
+}
+
+// KvaserFrame is one parsed row of a Kvaser CSV log.
+type KvaserFrame struct {
+	Time    float64   // seconds since the start of the log
+	AbsTime time.Time // wall-clock time to the second, zero if not logged
+	Channel int
+	ID      types.CANID
+	Flags   uint32
+	DLC     int    // DLC code as logged
+	Data    []byte // DLCToLength(DLC, IsFD()) bytes
+}
+
+// IsFD reports whether the row is a CAN FD frame.
+func (f *KvaserFrame) IsFD() bool { return f.Flags&KvaserFlagFDF != 0 }
+
+// BitRateSwitch reports whether the FD data phase used the faster bit rate.
+func (f *KvaserFrame) BitRateSwitch() bool { return f.Flags&KvaserFlagBRS != 0 }
+
+// IsErrorFrame reports whether the row records a bus error rather than data.
+func (f *KvaserFrame) IsErrorFrame() bool { return f.Flags&KvaserFlagErrorFrame != 0 }
+
+// IsRemote reports whether the row is a remote request without payload.
+func (f *KvaserFrame) IsRemote() bool { return f.Flags&KvaserFlagRTR != 0 }
+
+// KvaserLayout holds the column positions of a Kvaser CSV log. A negative
+// index marks a missing column.
+type KvaserLayout struct {
+	Time    int
+	Channel int
+	ID      int
+	Flags   int
+	DLC     int
+	Data    []int // columns of Data0, Data1, ...
+	AbsTime int
+}
+
+// DefaultKvaserLayout returns the column order of a Kvaser CSV export, used
+// until a header row is seen.
+func DefaultKvaserLayout() *KvaserLayout {
+	l := &KvaserLayout{Time: 0, Channel: 1, ID: 2, Flags: 3, DLC: 4, Data: make([]int, 64), AbsTime: 70}
+	for i := range l.Data {
+		l.Data[i] = 5 + i
+	}
+	return l
+}
+
+// ParseKvaserHeader builds the layout described by a header row. It returns
+// false if record is not a header (it has no id and DLC columns).
+func ParseKvaserHeader(record []string) (*KvaserLayout, bool) {
+	l := &KvaserLayout{Time: -1, Channel: -1, ID: -1, Flags: -1, DLC: -1, AbsTime: -1}
+	type dataCol struct{ n, col int }
+	var data []dataCol
+	for col, field := range record {
+		name := strings.ToLower(strings.TrimSpace(field))
+		switch name {
+		case "time":
+			l.Time = col
+		case "channel":
+			l.Channel = col
+		case "id":
+			l.ID = col
+		case "flags":
+			l.Flags = col
+		case "dlc":
+			l.DLC = col
+		case "abstime":
+			l.AbsTime = col
+		default:
+			if strings.HasPrefix(name, "data") {
+				if n, err := strconv.Atoi(name[4:]); err == nil {
+					data = append(data, dataCol{n, col})
+				}
+			}
+		}
+	}
+	if l.ID < 0 || l.DLC < 0 {
+		return nil, false
+	}
+	sort.Slice(data, func(i, j int) bool { return data[i].n < data[j].n })
+	for i, d := range data {
+		if d.n != i {
+			break
+		}
+		l.Data = append(l.Data, d.col)
+	}
+	return l, true
+}
+
+// ParseRow parses one data row. The payload length comes from the DLC column;
+// a row with fewer data bytes than its DLC announces is rejected.
+func (l *KvaserLayout) ParseRow(record []string) (KvaserFrame, error) {
+	var f KvaserFrame
+	field := func(col int) string {
+		if col < 0 || col >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[col])
+	}
+	if l.ID >= len(record) || l.DLC >= len(record) {
+		return f, fmt.Errorf("row has %d columns", len(record))
+	}
+
+	var err error
+	if f.ID, err = ParseKvaserID(field(l.ID), field(l.Flags)); err != nil {
+		return f, err
+	}
+	if s := field(l.Flags); s != "" {
+		flags, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return f, fmt.Errorf("parse flags '%s': %v", s, err)
+		}
+		f.Flags = uint32(flags)
+	}
+	if f.DLC, err = strconv.Atoi(field(l.DLC)); err != nil || f.DLC < 0 || f.DLC > 15 {
+		return f, fmt.Errorf("invalid DLC '%s'", field(l.DLC))
+	}
+	if s := field(l.Time); s != "" {
+		if f.Time, err = strconv.ParseFloat(s, 64); err != nil {
+			return f, fmt.Errorf("parse time '%s': %v", s, err)
+		}
+	}
+	if s := field(l.Channel); s != "" {
+		if f.Channel, err = strconv.Atoi(s); err != nil {
+			return f, fmt.Errorf("parse channel '%s': %v", s, err)
+		}
+	}
+	if s := field(l.AbsTime); s != "" {
+		if f.AbsTime, err = time.ParseInLocation(KvaserAbsTimeLayout, s, time.Local); err != nil {
+			return f, fmt.Errorf("parse AbsTime '%s': %v", s, err)
+		}
+	}
+
+	if f.IsRemote() || f.IsErrorFrame() {
+		return f, nil
+	}
+	n := DLCToLength(f.DLC, f.IsFD())
+	if n > len(l.Data) {
+		return f, fmt.Errorf("DLC %d needs %d data columns, layout has %d", f.DLC, n, len(l.Data))
+	}
+	f.Data = make([]byte, n)
+	for i := 0; i < n; i++ {
+		s := field(l.Data[i])
+		if s == "" {
+			return f, fmt.Errorf("DLC %d announces %d bytes, row has %d", f.DLC, n, i)
+		}
+		b, err := strconv.ParseUint(s, 16, 8)
+		if err != nil {
+			return f, fmt.Errorf("parse hex byte '%s': %v", s, err)
+		}
+		f.Data[i] = byte(b)
+	}
+	return f, nil
+}
+
+// ParseKvaserJSON parses a frame sent as a JSON object with the columns of a
+// Kvaser CSV row, as published by the MQTT test sketch
+// (Documentation/MQTT Testing): {"Time":0.35, "Channel":1, "ID":513,
+// "Flags":2, "DLC":3, "Data":["90","00","00"], "AbsTime":"2024-10-16 0:16"}.
+// Numbers may also be sent as strings. The row is validated like a CSV row,
+// except that Time and AbsTime, which the sketch fills loosely, are ignored.
+func ParseKvaserJSON(payload []byte) (KvaserFrame, error) {
+	var obj struct {
+		Channel, ID, Flags, DLC json.RawMessage
+		Data                    []json.RawMessage
+	}
+	if err := json.Unmarshal(payload, &obj); err != nil {
+		return KvaserFrame{}, fmt.Errorf("parse JSON frame: %v", err)
+	}
+	layout := DefaultKvaserLayout()
+	if len(obj.Data) > len(layout.Data) {
+		return KvaserFrame{}, fmt.Errorf("%d data bytes exceed %d", len(obj.Data), len(layout.Data))
+	}
+	record := make([]string, layout.AbsTime+1)
+	record[layout.Channel] = jsonScalar(obj.Channel)
+	record[layout.ID] = jsonScalar(obj.ID)
+	record[layout.Flags] = jsonScalar(obj.Flags)
+	record[layout.DLC] = jsonScalar(obj.DLC)
+	for i, b := range obj.Data {
+		record[layout.Data[i]] = jsonScalar(b)
+	}
+	return layout.ParseRow(record)
+}
+
+// jsonScalar returns a JSON string's contents or any other value's literal
+// text, so "513" and 513 read alike.
+func jsonScalar(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
@@ -0,0 +1,86 @@
+package candecoder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+func TestKvaserCSVRows(t *testing.T) {
+	header := strings.Split("Time,Channel,id,Flags,DLC,Data0,Data1,Data2,Data3,Data4,Data5,Data6,Data7,Data8,Data9,Data10,Data11,Data12,Data13,Data14,Data15,Counter,AbsTime", ",")
+	layout, ok := ParseKvaserHeader(header)
+	if !ok || len(layout.Data) != 16 || layout.DLC != 4 {
+		t.Fatalf("ParseKvaserHeader = %+v, %v", layout, ok)
+	}
+	if _, ok := ParseKvaserHeader(strings.Split("0.1,1,4,2,2,aa,bb", ",")); ok {
+		t.Error("data row parsed as header")
+	}
+
+	fd := strings.Split("0.31608,2,101,196610,10,6a,bc,73,46,0d,25,17,44,95,4e,28,44,b3,2d,83,46,1,2024-11-16 12:24:15", ",")
+	row, err := layout.ParseRow(fd)
+	if err != nil {
+		t.Fatalf("ParseRow: %v", err)
+	}
+	if !row.IsFD() || !row.BitRateSwitch() || row.ID != 101 || len(row.Data) != 16 || row.Data[15] != 0x46 {
+		t.Errorf("FD row = %+v", row)
+	}
+	if want := time.Date(2024, 11, 16, 12, 24, 15, 0, time.Local); !row.AbsTime.Equal(want) {
+		t.Errorf("AbsTime = %v, want %v", row.AbsTime, want)
+	}
+
+	short := strings.Split("0.5,2,101,196610,10,6a,bc,73,,,,,,,,,,,,,,1,2024-11-16 12:24:16", ",")
+	if _, err := layout.ParseRow(short); err == nil {
+		t.Error("row with fewer bytes than its DLC was accepted")
+	}
+	errFrame := strings.Split("0.6,2,0,32,0,,,,,,,,,,,,,,,,,2,2024-11-16 12:24:16", ",")
+	if row, err := layout.ParseRow(errFrame); err != nil || !row.IsErrorFrame() {
+		t.Errorf("error frame = %+v, %v", row, err)
+	}
+
+	for _, tc := range []struct {
+		dlc  int
+		fd   bool
+		want int
+	}{{8, false, 8}, {9, false, 8}, {9, true, 12}, {13, true, 32}, {15, true, 64}} {
+		if got := DLCToLength(tc.dlc, tc.fd); got != tc.want {
+			t.Errorf("DLCToLength(%d, %v) = %d, want %d", tc.dlc, tc.fd, got, tc.want)
+		}
+	}
+}
+
+func TestCheckLengthAndShortPayload(t *testing.T) {
+	msg := types.Message{
+		Name:   "Reg",
+		Length: 6,
+		Signals: []types.Signal{
+			{Name: "REGID", Start: 0, Length: 8, ByteOrder: "little_endian", Factor: 1},
+			{Name: "Value", Start: 8, Length: 16, ByteOrder: "little_endian", Factor: 1},
+			{Name: "Extra", Start: 24, Length: 24, ByteOrder: "little_endian", Factor: 1},
+		},
+	}
+	if err := CheckLength(msg, 3); !errors.Is(err, ErrFrameTooShort) {
+		t.Errorf("CheckLength(3) = %v", err)
+	}
+	if err := CheckLength(msg, 8); !errors.Is(err, ErrFrameTooLong) {
+		t.Errorf("CheckLength(8) = %v", err)
+	}
+	msg.Length = 10
+	if err := CheckLength(msg, 12); err != nil {
+		t.Errorf("FD padding rejected: %v", err)
+	}
+	msg.Length = 6
+
+	var frame DecodedFrame
+	if err := Compile(msg).Decode([]byte{0x3D, 0x49, 0x00}, &frame); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if frame.Float("REGID") != 0x3D || frame.Float("Value") != 0x49 {
+		t.Errorf("got %+v", frame.Signals)
+	}
+	if extra, _ := frame.Value("Extra"); extra.Valid {
+		t.Errorf("signal past a short payload decoded: %+v", extra)
+	}
+}
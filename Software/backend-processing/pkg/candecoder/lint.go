@@ -0,0 +1,162 @@
+// lint.go
+//
+// Structural checks for CAN definitions. Lint reports problems that make the
+// decoder silently produce garbage: overlapping or out-of-bounds signals,
+// unsupported float lengths, duplicate frame IDs and zero factors.
+package candecoder
+
+import (
+	"fmt"
+
+	"telem-system/pkg/types"
+)
+
+// LintIssue is one problem found in a definition set.
+type LintIssue struct {
+	Message string
+	ID      types.CANID
+	Signal  string // empty for message-level problems
+	Problem string
+}
+
+// String formats the issue as "Message (ID).Signal: problem".
+func (i LintIssue) String() string {
+	where := fmt.Sprintf("%s (%s)", i.Message, i.ID)
+	if i.Signal != "" {
+		where += "." + i.Signal
+	}
+	return where + ": " + i.Problem
+}
+
+// Lint checks every message and signal and returns the problems found, in
+// definition order.
+func Lint(messages []types.Message) []LintIssue {
+	var issues []LintIssue
+	seen := make(map[types.CANID]string, len(messages))
+	for _, msg := range messages {
+		add := func(signal, format string, args ...interface{}) {
+			issues = append(issues, LintIssue{Message: msg.Name, ID: msg.Key(), Signal: signal, Problem: fmt.Sprintf(format, args...)})
+		}
+		if prev, dup := seen[msg.Key()]; dup {
+			add("", "duplicate frame ID, also used by %s", prev)
+		} else {
+			seen[msg.Key()] = msg.Name
+		}
+		if msg.Length < 0 || msg.Length > 64 {
+			add("", "invalid length %d bytes", msg.Length)
+		} else if msg.Length > 8 && !msg.IsFD {
+			add("", "length %d exceeds 8 bytes but the message is not CAN FD", msg.Length)
+		}
+		if !msg.IsExtendedFrame && msg.FrameID > 0x7FF {
+			add("", "standard frame ID exceeds 11 bits")
+		}
+
+		byName := make(map[string]int, len(msg.Signals))
+		for i, sig := range msg.Signals {
+			if _, dup := byName[sig.Name]; dup {
+				add(sig.Name, "duplicate signal name")
+			}
+			byName[sig.Name] = i
+		}
+		for _, sig := range msg.Signals {
+			switch {
+			case sig.Length <= 0 || sig.Length > 64:
+				add(sig.Name, "invalid length %d bits", sig.Length)
+			case !signalFits(sig, msg.Length):
+				add(sig.Name, "bits %s run past the %d byte message", bitRange(sig), msg.Length)
+			}
+			if sig.IsFloat && sig.Length != 32 && sig.Length != 64 {
+				add(sig.Name, "float signal is %d bits, must be 32 or 64", sig.Length)
+			}
+			if sig.Factor == 0 {
+				add(sig.Name, "factor is zero")
+			}
+			if sig.Minimum != nil && sig.Maximum != nil && *sig.Minimum > *sig.Maximum {
+				add(sig.Name, "minimum %v is greater than maximum %v", *sig.Minimum, *sig.Maximum)
+			}
+			if len(sig.MultiplexerIDs) > 0 {
+				if _, found := muxIndex(msg, byName, sig); !found {
+					add(sig.Name, "multiplexed signal has no multiplexer")
+				}
+			}
+		}
+		for _, pair := range overlappingSignals(msg, byName) {
+			add(pair[0], "overlaps signal %s", pair[1])
+		}
+	}
+	return issues
+}
+
+// bitRange formats the start bit and length of a signal as in a DBC file.
+func bitRange(sig types.Signal) string {
+	order := 1
+	if isBigEndian(sig) {
+		order = 0
+	}
+	return fmt.Sprintf("%d|%d@%d", sig.Start, sig.Length, order)
+}
+
+// overlappingSignals returns pairs of signals that share payload bits and can
+// be present in the same frame. Signals selected by disjoint values of a
+// common multiplexer never coexist and are not reported.
+func overlappingSignals(msg types.Message, byName map[string]int) [][2]string {
+	masks := make([][]byte, len(msg.Signals))
+	for i, sig := range msg.Signals {
+		if sig.Length <= 0 || sig.Length > 64 || !signalFits(sig, msg.Length) {
+			continue
+		}
+		masks[i] = make([]byte, msg.Length)
+		for _, s := range appendSegments(nil, sig) {
+			masks[i][s.byteIdx] |= s.mask << s.lo
+		}
+	}
+	conds := make([]map[int]types.MuxRanges, len(msg.Signals))
+	for i := range msg.Signals {
+		conds[i] = muxConditions(msg, byName, i)
+	}
+
+	var pairs [][2]string
+	for i := range msg.Signals {
+		for j := i + 1; j < len(msg.Signals); j++ {
+			if masks[i] == nil || masks[j] == nil || exclusive(conds[i], conds[j]) {
+				continue
+			}
+			for b := range masks[i] {
+				if masks[i][b]&masks[j][b] != 0 {
+					pairs = append(pairs, [2]string{msg.Signals[i].Name, msg.Signals[j].Name})
+					break
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// muxConditions collects, for every multiplexer above signal i, the selector
+// values under which the signal is present.
+func muxConditions(msg types.Message, byName map[string]int, i int) map[int]types.MuxRanges {
+	conds := make(map[int]types.MuxRanges)
+	for depth := 0; depth < len(msg.Signals); depth++ {
+		sig := msg.Signals[i]
+		if len(sig.MultiplexerIDs) == 0 {
+			break
+		}
+		mux, found := muxIndex(msg, byName, sig)
+		if !found || conds[mux] != nil {
+			break
+		}
+		conds[mux] = sig.MultiplexerIDs
+		i = mux
+	}
+	return conds
+}
+
+// exclusive reports whether two condition sets can never hold together.
+func exclusive(a, b map[int]types.MuxRanges) bool {
+	for mux, ids := range a {
+		if other, ok := b[mux]; ok && !ids.Overlaps(other) {
+			return true
+		}
+	}
+	return false
+}
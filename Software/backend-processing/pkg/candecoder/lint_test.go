@@ -0,0 +1,73 @@
+package candecoder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+func TestLint(t *testing.T) {
+	muxMsgs, err := ParseDBC(strings.NewReader(muxTestDBC))
+	if err != nil {
+		t.Fatalf("ParseDBC: %v", err)
+	}
+	if issues := Lint(muxMsgs); len(issues) != 0 {
+		t.Errorf("multiplexed branches reported as problems: %v", issues)
+	}
+
+	msgs, err := ParseDBC(strings.NewReader(`VERSION ""
+
+BO_ 10 Bad: 4 TCU
+ SG_ A : 0|16@1+ (1,0) [0|0] "" Vector__XXX
+ SG_ B : 8|8@1+ (1,0) [0|0] "" Vector__XXX
+ SG_ C : 24|16@1+ (1,0) [0|0] "" Vector__XXX
+ SG_ D : 0|16@1+ (0,0) [0|0] "" Vector__XXX
+ SG_ E : 16|8@1+ (1,0) [0|0] "" Vector__XXX
+
+BO_ 11 Float: 1 TCU
+ SG_ F : 0|8@1+ (1,0) [0|0] "" Vector__XXX
+
+SIG_VALTYPE_ 11 F : 1;
+`))
+	if err != nil {
+		t.Fatalf("ParseDBC: %v", err)
+	}
+	// ParseDBC rejects duplicate IDs; JSON definitions are not checked on load.
+	msgs = append(msgs, types.Message{FrameID: 10, Name: "Dup", Length: 1})
+	var got []string
+	for _, issue := range Lint(msgs) {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		"Bad (0x00A).C: bits 24|16@1 run past the 4 byte message",
+		"Bad (0x00A).D: factor is zero",
+		"Bad (0x00A).A: overlaps signal B",
+		"Bad (0x00A).A: overlaps signal D",
+		"Bad (0x00A).B: overlaps signal D",
+		"Float (0x00B).F: float signal is 8 bits, must be 32 or 64",
+		"Dup (0x00A): duplicate frame ID, also used by Bad",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// TestLintShippedDefinitions checks that the DBC and its JSON export report
+// the same problems.
+func TestLintShippedDefinitions(t *testing.T) {
+	var results [2][]string
+	for i, path := range []string{"../../configs/UCR-01.dbc", "../../configs/UCR-01.json"} {
+		messages, _, err := LoadDefinitions(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, issue := range Lint(messages) {
+			results[i] = append(results[i], issue.String())
+		}
+	}
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Errorf("DBC problems:\n%s\nJSON problems:\n%s", strings.Join(results[0], "\n"), strings.Join(results[1], "\n"))
+	}
+}
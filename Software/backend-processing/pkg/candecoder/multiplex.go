@@ -0,0 +1,103 @@
+// multiplex.go
+//
+// Multiplexed signal selection. A frame ID may carry different signal sets
+// depending on the raw value of a selector (multiplexer) signal; only the
+// signals active for the current selector value are decoded.
+package candecoder
+
+import (
+	"fmt"
+
+	"telem-system/pkg/types"
+)
+
+// isMultiplexed reports whether any signal in msg depends on a selector.
+func isMultiplexed(msg types.Message) bool {
+	for _, sig := range msg.Signals {
+		if len(sig.MultiplexerIDs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// activeSignals returns the signals of msg that are present in data for the
+// current multiplexer selector values. Non-multiplexed messages are returned
+// unchanged.
+func activeSignals(data []byte, msg types.Message) []types.Signal {
+	if !isMultiplexed(msg) {
+		return msg.Signals
+	}
+	byName := make(map[string]int, len(msg.Signals))
+	for i, sig := range msg.Signals {
+		byName[sig.Name] = i
+	}
+	// active caches per-signal results; 0 = unknown, 1 = active, 2 = inactive.
+	active := make([]uint8, len(msg.Signals))
+	var isActive func(i int, depth int) bool
+	isActive = func(i int, depth int) bool {
+		if active[i] != 0 {
+			return active[i] == 1
+		}
+		sig := msg.Signals[i]
+		ok := true
+		if len(sig.MultiplexerIDs) > 0 {
+			ok = false
+			if muxIdx, found := muxIndex(msg, byName, sig); found && depth < len(msg.Signals) && isActive(muxIdx, depth+1) {
+				if sel, err := rawSignalValue(data, msg.Signals[muxIdx], msg.Length); err == nil {
+					ok = sig.MultiplexerIDs.Contains(sel)
+				}
+			}
+		}
+		if ok {
+			active[i] = 1
+		} else {
+			active[i] = 2
+		}
+		return ok
+	}
+
+	out := make([]types.Signal, 0, len(msg.Signals))
+	for i := range msg.Signals {
+		if isActive(i, 0) {
+			out = append(out, msg.Signals[i])
+		}
+	}
+	return out
+}
+
+// muxIndex locates the selector for a multiplexed signal, falling back to the
+// message's only top-level multiplexer when MultiplexerSignal is not set.
+func muxIndex(msg types.Message, byName map[string]int, sig types.Signal) (int, bool) {
+	if sig.MultiplexerSignal != "" {
+		idx, ok := byName[sig.MultiplexerSignal]
+		return idx, ok
+	}
+	for i, s := range msg.Signals {
+		if s.IsMultiplexer && len(s.MultiplexerIDs) == 0 {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// rawSignalValue extracts the unscaled, unsigned bit pattern of a signal.
+func rawSignalValue(data []byte, signal types.Signal, msgLength int) (uint64, error) {
+	if !signalFits(signal, msgLength) || !signalFits(signal, len(data)) || signal.Length > 64 {
+		return 0, fmt.Errorf("signal %s out of bounds", signal.Name)
+	}
+	if isBigEndian(signal) {
+		return extractBigEndian(data, signal.Start, signal.Length), nil
+	}
+	return extractLittleEndian(data, signal.Start, signal.Length), nil
+}
+
+// extractLittleEndian reads an Intel signal whose LSB is at bit startBit.
+func extractLittleEndian(data []byte, startBit, length int) uint64 {
+	var raw uint64
+	for i := 0; i < length; i++ {
+		pos := startBit + i
+		raw |= uint64((data[pos/8]>>(pos%8))&1) << i
+	}
+	return raw
+}
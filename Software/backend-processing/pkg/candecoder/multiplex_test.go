@@ -0,0 +1,95 @@
+package candecoder
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+const muxTestDBC = `VERSION ""
+
+BU_: TCU
+
+BO_ 513 BamocarRxData: 6 TCU
+ SG_ REGID M : 0|8@1+ (1,0) [0|0] "" Vector__XXX
+ SG_ Speed m48 : 8|16@1+ (1,0) [0|0] "rpm" Vector__XXX
+ SG_ Page m49M : 8|8@1+ (1,0) [0|0] "" Vector__XXX
+ SG_ TempA m0 : 16|16@1+ (0.1,0) [0|0] "" Vector__XXX
+ SG_ TempB m1 : 16|16@1+ (0.1,0) [0|0] "" Vector__XXX
+
+SG_MUL_VAL_ 513 TempA Page 0-0;
+SG_MUL_VAL_ 513 TempB Page 1-3, 7-7;
+`
+
+func TestDecodeMultiplexedMessage(t *testing.T) {
+	msgs, err := ParseDBC(strings.NewReader(muxTestDBC))
+	if err != nil {
+		t.Fatalf("ParseDBC: %v", err)
+	}
+	msg := msgs[0]
+
+	tests := []struct {
+		name string
+		data []byte
+		want map[string]string
+	}{
+		{
+			name: "simple multiplexing",
+			data: []byte{48, 0xE8, 0x03, 0, 0, 0},
+			want: map[string]string{"REGID": "48", "Speed": "1000"},
+		},
+		{
+			name: "extended multiplexing first page",
+			data: []byte{49, 0, 0xE8, 0x03, 0, 0},
+			want: map[string]string{"REGID": "49", "Page": "0", "TempA": "100"},
+		},
+		{
+			name: "extended multiplexing range",
+			data: []byte{49, 7, 0xE8, 0x03, 0, 0},
+			want: map[string]string{"REGID": "49", "Page": "7", "TempB": "100"},
+		},
+		{
+			name: "unknown selector",
+			data: []byte{50, 7, 0xE8, 0x03, 0, 0},
+			want: map[string]string{"REGID": "50"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := DecodeMessage(tc.data, msg)
+			if err != nil {
+				t.Fatalf("DecodeMessage: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tc.want) {
+				t.Errorf("got %v, want %v", decoded, tc.want)
+			}
+		})
+	}
+}
+
+func TestMuxRangesJSON(t *testing.T) {
+	// cantools writes every selector value; adjacent values become one range.
+	var ids types.MuxRanges
+	if err := json.Unmarshal([]byte(`[5, 1, 2, 3, [10, 4294967295]]`), &ids); err != nil {
+		t.Fatal(err)
+	}
+	want := types.MuxRanges{{Lo: 1, Hi: 3}, {Lo: 5, Hi: 5}, {Lo: 10, Hi: 4294967295}}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("ranges = %v, want %v", ids, want)
+	}
+	for v, in := range map[uint64]bool{0: false, 2: true, 4: false, 5: true, 1 << 31: true} {
+		if ids.Contains(v) != in {
+			t.Errorf("Contains(%d) = %v", v, !in)
+		}
+	}
+	b, err := json.Marshal(ids)
+	if err != nil || string(b) != `[[1,3],5,[10,4294967295]]` {
+		t.Errorf("Marshal = %s, %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`[[3, 1]]`), &ids); err == nil {
+		t.Error("reversed range accepted")
+	}
+}
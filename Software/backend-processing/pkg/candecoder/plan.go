@@ -0,0 +1,242 @@
+// plan.go
+//
+// Precompiled decoder plans. Compile turns a message definition into a flat
+// list of byte/shift/mask operations once at load time, so that decoding a
+// frame is a handful of integer operations per signal and, with a reused
+// DecodedFrame, does not allocate.
+package candecoder
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// bitSegment copies n bits starting at bit lo of data[byteIdx] to bit dst of
+// the raw value.
+type bitSegment struct {
+	byteIdx int
+	lo      uint8
+	mask    uint8
+	dst     uint8
+}
+
+// planSignal is the compiled form of one signal.
+type planSignal struct {
+	def      types.Signal
+	kind     ValueKind
+	ok       bool // false if the signal can never be decoded (bounds, float length)
+	segStart int  // index of the first segment in Plan.segs
+	segEnd   int
+	end      int    // payload bytes needed to decode the signal
+	signMask uint64 // sign bit for signed integers, 0 otherwise
+	extend   uint64 // bits set above the signal when sign-extending
+	exact    bool   // factor 1, offset 0: the raw integer is the value
+	choices  map[int64]string
+	mux      int // index of the selector signal, or -1
+	muxIDs   types.MuxRanges
+}
+
+// Plan is a compiled decoder for one message definition. A Plan is immutable
+// after Compile and may be shared between goroutines.
+type Plan struct {
+	msg     types.Message
+	signals []planSignal
+	segs    []bitSegment
+	muxed   bool
+}
+
+// Compile builds the decoder plan for msg.
+func Compile(msg types.Message) *Plan {
+	p := &Plan{msg: msg, signals: make([]planSignal, len(msg.Signals)), muxed: isMultiplexed(msg)}
+	byName := make(map[string]int, len(msg.Signals))
+	for i, sig := range msg.Signals {
+		byName[sig.Name] = i
+	}
+	for i, sig := range msg.Signals {
+		ps := planSignal{def: sig, kind: valueKind(sig), mux: -1}
+		ps.exact = !sig.IsFloat && sig.Factor == 1 && sig.Offset == 0
+		ps.ok = signalFits(sig, msg.Length) && sig.Length <= 64 &&
+			(!sig.IsFloat || sig.Length == 32 || sig.Length == 64)
+		if ps.ok {
+			ps.segStart = len(p.segs)
+			p.segs = appendSegments(p.segs, sig)
+			ps.segEnd = len(p.segs)
+			for _, seg := range p.segs[ps.segStart:ps.segEnd] {
+				ps.end = max(ps.end, seg.byteIdx+1)
+			}
+			if sig.IsSigned && !sig.IsFloat {
+				ps.signMask = 1 << (sig.Length - 1)
+				if sig.Length < 64 {
+					ps.extend = ^uint64(0) << sig.Length
+				}
+			}
+		}
+		if len(sig.Choices) > 0 {
+			ps.choices = make(map[int64]string, len(sig.Choices))
+			for k, label := range sig.Choices {
+				if v, err := strconv.ParseInt(k, 10, 64); err == nil {
+					ps.choices[v] = label
+				}
+			}
+		}
+		if len(sig.MultiplexerIDs) > 0 {
+			if idx, found := muxIndex(msg, byName, sig); found {
+				ps.mux = idx
+			}
+			ps.muxIDs = sig.MultiplexerIDs
+		}
+		p.signals[i] = ps
+	}
+	return p
+}
+
+// CompileAll compiles a plan for every message in messageMap.
+func CompileAll(messageMap map[types.CANID]types.Message) map[types.CANID]*Plan {
+	plans := make(map[types.CANID]*Plan, len(messageMap))
+	for id, msg := range messageMap {
+		plans[id] = Compile(msg)
+	}
+	return plans
+}
+
+// Message returns the definition the plan was compiled from.
+func (p *Plan) Message() types.Message {
+	return p.msg
+}
+
+// appendSegments maps the bits of sig onto per-byte segments. Within a byte
+// both Intel and Motorola signals map consecutive bits to consecutive raw
+// bits, so each touched byte needs exactly one shift and mask.
+func appendSegments(segs []bitSegment, sig types.Signal) []bitSegment {
+	pos := sig.Start
+	bigEndian := isBigEndian(sig)
+	first := len(segs)
+	for i := 0; i < sig.Length; i++ {
+		rawBit := i
+		if bigEndian {
+			rawBit = sig.Length - 1 - i
+		}
+		idx, bit := pos/8, uint8(pos%8)
+		n := len(segs)
+		if n > first && segs[n-1].byteIdx == idx {
+			s := &segs[n-1]
+			if bit < s.lo {
+				s.lo, s.dst = bit, uint8(rawBit)
+			}
+			s.mask = s.mask<<1 | 1
+		} else {
+			segs = append(segs, bitSegment{byteIdx: idx, lo: bit, mask: 1, dst: uint8(rawBit)})
+		}
+		if !bigEndian {
+			pos++
+		} else if pos%8 == 0 {
+			pos += 15
+		} else {
+			pos--
+		}
+	}
+	return segs
+}
+
+// present reports whether data is long enough to hold the signal.
+func (ps *planSignal) present(data []byte) bool {
+	return ps.ok && ps.end <= len(data)
+}
+
+// raw assembles the unsigned bit pattern of a signal; the caller checks that
+// the signal is present.
+func (p *Plan) raw(data []byte, ps *planSignal) uint64 {
+	var raw uint64
+	for _, s := range p.segs[ps.segStart:ps.segEnd] {
+		raw |= uint64((data[s.byteIdx]>>s.lo)&s.mask) << s.dst
+	}
+	return raw
+}
+
+// Decode decodes data into frame, reusing frame's signal buffer. Once the
+// buffer has grown to the message's signal count no further allocations are
+// made. Signals extending past the end of a short payload are marked invalid
+// rather than decoded from padding.
+func (p *Plan) Decode(data []byte, frame *DecodedFrame) error {
+	frame.ID = p.msg.Key()
+	frame.Message = p.msg.Name
+	frame.Signals = frame.Signals[:0]
+	frame.Timestamp = time.Time{}
+	if p.muxed {
+		if cap(frame.active) < len(p.signals) {
+			frame.active = make([]uint8, len(p.signals))
+		}
+		frame.active = frame.active[:len(p.signals)]
+		clear(frame.active)
+	}
+	for i := range p.signals {
+		ps := &p.signals[i]
+		if p.muxed && !p.isActive(data, frame.active, i, 0) {
+			continue
+		}
+		sv := SignalValue{Name: ps.def.Name, Kind: ps.kind}
+		if ps.present(data) {
+			p.decodeValue(data, ps, &sv)
+			checkRange(&sv, p.msg.Name, ps.def)
+		}
+		frame.Signals = append(frame.Signals, sv)
+	}
+	return nil
+}
+
+// decodeValue fills sv from the raw bits of ps.
+func (p *Plan) decodeValue(data []byte, ps *planSignal, sv *SignalValue) {
+	raw := p.raw(data, ps)
+	sv.Valid = true
+	switch {
+	case ps.def.IsFloat:
+		sv.setPhysical(floatPhysical(raw, ps.def))
+		return
+	case ps.signMask != 0:
+		if raw&ps.signMask != 0 {
+			raw |= ps.extend
+		}
+		v := int64(raw)
+		if ps.exact {
+			sv.setInt(v)
+		} else {
+			sv.setPhysical(float64(v)*ps.def.Factor + ps.def.Offset)
+		}
+		if ps.choices != nil {
+			sv.Label = ps.choices[v]
+		}
+	default:
+		if ps.exact {
+			sv.setUint(raw)
+		} else {
+			sv.setPhysical(float64(raw)*ps.def.Factor + ps.def.Offset)
+		}
+		if ps.choices != nil && raw <= math.MaxInt64 {
+			sv.Label = ps.choices[int64(raw)]
+		}
+	}
+}
+
+// isActive evaluates the multiplexer chain of signal i, caching results in
+// active (0 = unknown, 1 = active, 2 = inactive).
+func (p *Plan) isActive(data []byte, active []uint8, i, depth int) bool {
+	if active[i] != 0 {
+		return active[i] == 1
+	}
+	ps := &p.signals[i]
+	ok := ps.muxIDs == nil
+	if !ok && ps.mux >= 0 && depth < len(p.signals) && p.isActive(data, active, ps.mux, depth+1) {
+		if sel := &p.signals[ps.mux]; sel.present(data) {
+			ok = ps.muxIDs.Contains(p.raw(data, sel))
+		}
+	}
+	if ok {
+		active[i] = 1
+	} else {
+		active[i] = 2
+	}
+	return ok
+}
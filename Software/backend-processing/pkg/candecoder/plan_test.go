@@ -0,0 +1,161 @@
+package candecoder
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+const ucrDBC = "../../configs/UCR-01.dbc"
+
+func loadUCR(t testing.TB) map[types.CANID]types.Message {
+	t.Helper()
+	_, messageMap, err := LoadDefinitions(ucrDBC)
+	if err != nil {
+		t.Fatalf("LoadDefinitions: %v", err)
+	}
+	return messageMap
+}
+
+func TestPlanMatchesDecodeFrame(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var frame DecodedFrame
+	for _, msg := range loadUCR(t) {
+		plan := Compile(msg)
+		for n := 0; n < 100; n++ {
+			data := make([]byte, msg.Length)
+			rng.Read(data)
+			// Every fifth payload is cut short to compare signals past its end.
+			if n%5 == 0 {
+				data = data[:rng.Intn(msg.Length+1)]
+			}
+			want, _ := DecodeFrame(data, msg)
+			if err := plan.Decode(data, &frame); err != nil {
+				t.Fatalf("%s: Decode: %v", msg.Name, err)
+			}
+			if len(frame.Signals) != len(want.Signals) {
+				t.Fatalf("%s: got %d signals, want %d", msg.Name, len(frame.Signals), len(want.Signals))
+			}
+			for i, w := range want.Signals {
+				g := frame.Signals[i]
+				if math.IsNaN(w.Float) && math.IsNaN(g.Float) {
+					continue
+				}
+				if g != w {
+					t.Fatalf("%s.%s: got %+v, want %+v", msg.Name, w.Name, g, w)
+				}
+			}
+		}
+	}
+}
+
+func TestPlanSignedSignals(t *testing.T) {
+	msg := types.Message{
+		Name:   "Signed",
+		Length: 8,
+		Signals: []types.Signal{
+			{Name: "Aligned", Start: 0, Length: 16, ByteOrder: "little_endian", IsSigned: true, Factor: 1},
+			{Name: "Scaled", Start: 16, Length: 8, ByteOrder: "little_endian", IsSigned: true, Factor: 0.5},
+			{Name: "Motorola", Start: 35, Length: 12, ByteOrder: "big_endian", IsSigned: true, Factor: 1},
+			{Name: "Trim", Start: 52, Length: 4, ByteOrder: "little_endian", IsSigned: true, Factor: 1,
+				Choices: map[string]string{"-1": "NEGATIVE"}},
+		},
+	}
+	data := []byte{0x18, 0xFC, 0xFD, 0, 0x0F, 0xFF, 0xF0, 0}
+	var frame DecodedFrame
+	if err := Compile(msg).Decode(data, &frame); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	want := map[string]float64{"Aligned": -1000, "Scaled": -1.5, "Motorola": -1, "Trim": -1}
+	for name, w := range want {
+		if got := frame.Float(name); got != w {
+			t.Errorf("%s = %v, want %v", name, got, w)
+		}
+	}
+	if frame.Label("Trim") != "NEGATIVE" {
+		t.Errorf("Trim label = %q", frame.Label("Trim"))
+	}
+}
+
+func TestPlanMultiplexed(t *testing.T) {
+	msgs, err := ParseDBC(strings.NewReader(muxTestDBC))
+	if err != nil {
+		t.Fatalf("ParseDBC: %v", err)
+	}
+	plan := Compile(msgs[0])
+	var frame DecodedFrame
+	for _, data := range [][]byte{
+		{48, 0xE8, 0x03, 0, 0, 0},
+		{49, 0, 0xE8, 0x03, 0, 0},
+		{49, 7, 0xE8, 0x03, 0, 0},
+		{50, 7, 0xE8, 0x03, 0, 0},
+	} {
+		want, _ := DecodeFrame(data, msgs[0])
+		if err := plan.Decode(data, &frame); err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		if !reflect.DeepEqual(frame.Signals, want.Signals) {
+			t.Errorf("% X: got %+v, want %+v", data, frame.Signals, want.Signals)
+		}
+	}
+}
+
+func TestPlanDecodeDoesNotAllocate(t *testing.T) {
+	messageMap := loadUCR(t)
+	plan := Compile(messageMap[50])
+	data := make([]byte, 64)
+	var frame DecodedFrame
+	plan.Decode(data, &frame)
+	allocs := testing.AllocsPerRun(100, func() {
+		plan.Decode(data, &frame)
+	})
+	if allocs != 0 {
+		t.Errorf("Decode allocated %v times per frame", allocs)
+	}
+}
+
+// cellFrame returns the CellVoltage1 definition and a 64-byte payload.
+func cellFrame(b *testing.B) (types.Message, []byte) {
+	msg := loadUCR(b)[50]
+	data := make([]byte, msg.Length)
+	for i := range data {
+		data[i] = byte(i * 37)
+	}
+	return msg, data
+}
+
+func BenchmarkDecodeMessage(b *testing.B) {
+	msg, data := cellFrame(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DecodeMessage(data, msg)
+	}
+}
+
+func BenchmarkDecodeFrame(b *testing.B) {
+	msg, data := cellFrame(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DecodeFrame(data, msg)
+	}
+}
+
+func BenchmarkPlanDecode(b *testing.B) {
+	msg, data := cellFrame(b)
+	plan := Compile(msg)
+	var frame DecodedFrame
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		plan.Decode(data, &frame)
+	}
+}
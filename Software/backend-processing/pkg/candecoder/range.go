@@ -0,0 +1,146 @@
+// range.go
+//
+// Range validation. Decoded values are checked against the DBC minimum and
+// maximum of their signal; violations are counted per signal and handled
+// according to the configured RangePolicy.
+package candecoder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"telem-system/pkg/types"
+)
+
+// RangePolicy selects what the decoder does with out-of-range values.
+type RangePolicy int32
+
+const (
+	// RangeFlag keeps the value and marks it OutOfRange.
+	RangeFlag RangePolicy = iota
+	// RangeClamp replaces the value with the nearest limit.
+	RangeClamp
+	// RangeNull marks the value OutOfRange and invalid; processdata stores
+	// and broadcasts it as NULL.
+	RangeNull
+)
+
+// String returns the configuration name of the policy.
+func (p RangePolicy) String() string {
+	switch p {
+	case RangeClamp:
+		return "clamp"
+	case RangeNull:
+		return "null"
+	default:
+		return "flag"
+	}
+}
+
+// ParseRangePolicy parses a range_policy configuration value. An empty string
+// selects RangeFlag.
+func ParseRangePolicy(s string) (RangePolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "flag":
+		return RangeFlag, nil
+	case "clamp":
+		return RangeClamp, nil
+	case "null":
+		return RangeNull, nil
+	}
+	return RangeFlag, fmt.Errorf("unknown range policy %q", s)
+}
+
+var rangePolicy atomic.Int32
+
+// SetRangePolicy sets the policy applied by DecodeFrame and DecodeMessage.
+func SetRangePolicy(p RangePolicy) {
+	rangePolicy.Store(int32(p))
+}
+
+// CurrentRangePolicy returns the policy in effect.
+func CurrentRangePolicy() RangePolicy {
+	return RangePolicy(rangePolicy.Load())
+}
+
+// RangeViolation is the violation counter of one signal.
+type RangeViolation struct {
+	Message   string   `json:"message"`
+	Signal    string   `json:"signal"`
+	Count     uint64   `json:"count"`
+	LastValue float64  `json:"last_value"`
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+}
+
+var (
+	violationsMu sync.Mutex
+	violations   = make(map[string]*RangeViolation)
+)
+
+// RangeViolations returns a snapshot of all violation counters, sorted by
+// message and signal name.
+func RangeViolations() []RangeViolation {
+	violationsMu.Lock()
+	out := make([]RangeViolation, 0, len(violations))
+	for _, v := range violations {
+		out = append(out, *v)
+	}
+	violationsMu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Message != out[j].Message {
+			return out[i].Message < out[j].Message
+		}
+		return out[i].Signal < out[j].Signal
+	})
+	return out
+}
+
+// ResetRangeViolations clears all violation counters.
+func ResetRangeViolations() {
+	violationsMu.Lock()
+	violations = make(map[string]*RangeViolation)
+	violationsMu.Unlock()
+}
+
+// recordViolation increments the counter of a signal.
+func recordViolation(msgName string, signal types.Signal, value float64) {
+	key := msgName + "." + signal.Name
+	violationsMu.Lock()
+	v, ok := violations[key]
+	if !ok {
+		v = &RangeViolation{Message: msgName, Signal: signal.Name, Minimum: signal.Minimum, Maximum: signal.Maximum}
+		violations[key] = v
+	}
+	v.Count++
+	v.LastValue = value
+	violationsMu.Unlock()
+}
+
+// checkRange validates a decoded value against the signal limits, records any
+// violation and applies the current policy.
+func checkRange(sv *SignalValue, msgName string, signal types.Signal) {
+	if !sv.Valid {
+		return
+	}
+	limit := sv.Float
+	switch {
+	case signal.Minimum != nil && sv.Float < *signal.Minimum:
+		limit = *signal.Minimum
+	case signal.Maximum != nil && sv.Float > *signal.Maximum:
+		limit = *signal.Maximum
+	default:
+		return
+	}
+	recordViolation(msgName, signal, sv.Float)
+	sv.OutOfRange = true
+	switch CurrentRangePolicy() {
+	case RangeClamp:
+		sv.setPhysical(limit)
+	case RangeNull:
+		sv.Valid = false
+	}
+}
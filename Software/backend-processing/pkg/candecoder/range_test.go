@@ -0,0 +1,62 @@
+package candecoder
+
+import (
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+func TestDecodeRangeValidation(t *testing.T) {
+	minV, maxV := 0.0, 5.0
+	msg := types.Message{
+		Name:   "CellVoltage1",
+		Length: 2,
+		Signals: []types.Signal{
+			{Name: "Cell1", Start: 0, Length: 16, ByteOrder: "little_endian", Factor: 0.001,
+				Minimum: &minV, Maximum: &maxV},
+		},
+	}
+	data := []byte{0x70, 0x17} // 6000 raw -> 6.0 V
+	ResetRangeViolations()
+	defer SetRangePolicy(RangeFlag)
+	defer ResetRangeViolations()
+
+	tests := []struct {
+		policy RangePolicy
+		valid  bool
+		want   float64
+	}{
+		{RangeFlag, true, 6},
+		{RangeClamp, true, 5},
+		{RangeNull, false, 0},
+	}
+	for _, tc := range tests {
+		SetRangePolicy(tc.policy)
+		frame, err := DecodeFrame(data, msg)
+		if err != nil {
+			t.Fatalf("DecodeFrame: %v", err)
+		}
+		sv, _ := frame.Value("Cell1")
+		if !sv.OutOfRange || sv.Valid != tc.valid || frame.Float("Cell1") != tc.want {
+			t.Errorf("%v: got %+v", tc.policy, sv)
+		}
+	}
+
+	got := RangeViolations()
+	if len(got) != 1 || got[0].Signal != "Cell1" || got[0].Count != 3 || got[0].LastValue != 6 {
+		t.Errorf("violations = %+v", got)
+	}
+}
+
+func TestParseRangePolicy(t *testing.T) {
+	for in, want := range map[string]RangePolicy{"": RangeFlag, "flag": RangeFlag, " Clamp ": RangeClamp, "null": RangeNull} {
+		if got, err := ParseRangePolicy(in); err != nil || got != want {
+			t.Errorf("ParseRangePolicy(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"drop", "nan"} {
+		if _, err := ParseRangePolicy(in); err == nil {
+			t.Errorf("ParseRangePolicy(%q) accepted", in)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// reload.go
+//
+// Hot-reload of CAN definitions. A Registry holds the current definitions and
+// compiled plans; Watch reloads them when the definitions file changes and
+// swaps the new set in atomically, so decoding never sees a partial update.
+package candecoder
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"telem-system/pkg/types"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce groups the burst of events an editor produces when saving.
+const reloadDebounce = 250 * time.Millisecond
+
+// Definitions is an immutable snapshot of loaded CAN definitions.
+type Definitions struct {
+	Path     string
+	Hash     string // HashDefinitions of Content
+	Format   string // "dbc" or "json"
+	Content  []byte
+	Messages []types.Message
+	ByID     map[types.CANID]types.Message
+	Plans    map[types.CANID]*Plan
+}
+
+// LoadDefinitionSet loads, validates and compiles the definitions in path.
+func LoadDefinitionSet(path string) (*Definitions, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read definitions: %v", err)
+	}
+	defs, err := compileDefinitions(content, DefinitionsFormat(path))
+	if err != nil {
+		return nil, err
+	}
+	defs.Path = path
+	return defs, nil
+}
+
+// compileDefinitions parses, validates and compiles a definitions file.
+func compileDefinitions(content []byte, format string) (*Definitions, error) {
+	messages, err := ParseDefinitions(content, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateDefinitions(messages); err != nil {
+		return nil, err
+	}
+	byID := MessageMap(messages)
+	return &Definitions{
+		Hash:     HashDefinitions(content),
+		Format:   format,
+		Content:  content,
+		Messages: messages,
+		ByID:     byID,
+		Plans:    CompileAll(byID),
+	}, nil
+}
+
+// ValidateDefinitions rejects definition sets that cannot be used at all:
+// empty sets, duplicate frame IDs, impossible lengths and duplicate signal
+// names. Problems confined to single signals are left to the decoder, which
+// reports such signals as invalid.
+func ValidateDefinitions(messages []types.Message) error {
+	if len(messages) == 0 {
+		return fmt.Errorf("no messages defined")
+	}
+	seen := make(map[types.CANID]string, len(messages))
+	for _, msg := range messages {
+		if prev, dup := seen[msg.Key()]; dup {
+			return fmt.Errorf("messages %s and %s share ID %s", prev, msg.Name, msg.Key())
+		}
+		seen[msg.Key()] = msg.Name
+		if msg.Length < 0 || msg.Length > 64 {
+			return fmt.Errorf("message %s has invalid length %d", msg.Name, msg.Length)
+		}
+		names := make(map[string]bool, len(msg.Signals))
+		for _, sig := range msg.Signals {
+			if names[sig.Name] {
+				return fmt.Errorf("message %s defines signal %s twice", msg.Name, sig.Name)
+			}
+			names[sig.Name] = true
+		}
+	}
+	return nil
+}
+
+// DefinitionsDiff lists the messages that differ between two definition sets.
+type DefinitionsDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Empty reports whether the two sets were identical.
+func (d DefinitionsDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String summarises the diff for logging.
+func (d DefinitionsDiff) String() string {
+	if d.Empty() {
+		return "no changes"
+	}
+	var parts []string
+	for _, p := range []struct {
+		label string
+		names []string
+	}{{"added", d.Added}, {"removed", d.Removed}, {"changed", d.Changed}} {
+		if len(p.names) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", p.label, strings.Join(p.names, ", ")))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DiffDefinitions compares two message maps.
+func DiffDefinitions(old, new map[types.CANID]types.Message) DefinitionsDiff {
+	var d DefinitionsDiff
+	label := func(m types.Message) string { return fmt.Sprintf("%s (%s)", m.Name, m.Key()) }
+	for id, m := range new {
+		prev, ok := old[id]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, label(m))
+		case !reflect.DeepEqual(prev, m):
+			d.Changed = append(d.Changed, label(m))
+		}
+	}
+	for id, m := range old {
+		if _, ok := new[id]; !ok {
+			d.Removed = append(d.Removed, label(m))
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+	return d
+}
+
+// Registry holds the definitions currently in use.
+type Registry struct {
+	path string
+	cur  atomic.Pointer[Definitions]
+
+	// OnReload, if set, is called by Watch with every set it swaps in. Set it
+	// before starting Watch.
+	OnReload func(*Definitions)
+}
+
+// NewRegistry loads the definitions in path.
+func NewRegistry(path string) (*Registry, error) {
+	defs, err := LoadDefinitionSet(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Registry{path: path}
+	r.cur.Store(defs)
+	return r, nil
+}
+
+// Current returns the definitions in use. Callers should fetch it once per
+// frame and not hold on to it across frames.
+func (r *Registry) Current() *Definitions {
+	return r.cur.Load()
+}
+
+// Reload re-reads the definitions file and swaps it in if it is valid. On
+// error the current definitions stay in place.
+func (r *Registry) Reload() (DefinitionsDiff, error) {
+	defs, err := LoadDefinitionSet(r.path)
+	if err != nil {
+		return DefinitionsDiff{}, err
+	}
+	old := r.cur.Swap(defs)
+	return DiffDefinitions(old.ByID, defs.ByID), nil
+}
+
+// Watch reloads the definitions whenever the file changes, until ctx is done.
+// The containing directory is watched so that editors which save by
+// replacing the file are handled.
+func (r *Registry) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	target := filepath.Clean(r.path)
+	if err := watcher.Add(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("watch %s: %v", filepath.Dir(target), err)
+	}
+
+	timer := time.NewTimer(0)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(ev.Name) == target && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				timer.Reset(reloadDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("CAN definitions watcher error: %v", err)
+		case <-timer.C:
+			diff, err := r.Reload()
+			if err != nil {
+				log.Printf("CAN definitions reload failed, keeping previous set: %v", err)
+				continue
+			}
+			cur := r.Current()
+			log.Printf("Reloaded %d CAN messages from %s (%.12s): %s", len(cur.Messages), r.path, cur.Hash, diff)
+			if r.OnReload != nil {
+				r.OnReload(cur)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package candecoder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+func TestRegistryReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "car.dbc")
+	write := func(src string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(extendedTestDBC)
+	reg, err := NewRegistry(path)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reg.Watch(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	write(`VERSION ""
+
+BO_ 291 StdFrame: 2 TCU
+ SG_ A : 0|16@1+ (1,0) [0|0] "" Vector__XXX
+
+BO_ 292 NewFrame: 1 TCU
+ SG_ C : 0|8@1+ (1,0) [0|0] "" Vector__XXX
+`)
+	deadline := time.Now().Add(5 * time.Second)
+	for reg.Current().ByID[types.NewCANID(292, false)].Name != "NewFrame" {
+		if time.Now().After(deadline) {
+			t.Fatal("definitions were not reloaded")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if reg.Current().Plans[types.NewCANID(292, false)] == nil {
+		t.Error("no plan compiled for the new message")
+	}
+
+	// An invalid file keeps the previous definitions.
+	write("BO_ 1 Broken: 1 TCU\n SG_ X : 0|8@1+ (1,0")
+	if _, err := reg.Reload(); err == nil {
+		t.Error("Reload accepted a broken file")
+	}
+	if len(reg.Current().Messages) != 2 {
+		t.Errorf("previous definitions not kept: %d messages", len(reg.Current().Messages))
+	}
+
+	old := MessageMap([]types.Message{{FrameID: 1, Name: "Gone"}, {FrameID: 2, Name: "Same"}, {FrameID: 3, Name: "Edited", Length: 1}})
+	updated := MessageMap([]types.Message{{FrameID: 2, Name: "Same"}, {FrameID: 3, Name: "Edited", Length: 2}, {FrameID: 4, Name: "Fresh"}})
+	diff := DiffDefinitions(old, updated)
+	if want := "added: Fresh (0x004); removed: Gone (0x001); changed: Edited (0x003)"; diff.String() != want {
+		t.Errorf("diff = %q, want %q", diff, want)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+// serial_other.go
+//
+// Outside Linux serial devices are opened as they are; configure them for raw
+// 8N1 with stty first.
+package candecoder
+
+import "os"
+
+// openSerial opens a serial device for reading and writing.
+func openSerial(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDWR, 0)
+}
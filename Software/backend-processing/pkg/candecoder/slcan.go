@@ -0,0 +1,283 @@
+// slcan.go
+//
+// Parsing of the Lawicel SLCAN ASCII protocol spoken by USB-CAN dongles (the
+// adapters driven by Firmware/Scripts/slcan_send.py). Each received frame is
+// one '\r'-terminated line: tIIILDD.. for 11-bit IDs, TIIIIIIIILDD.. for
+// 29-bit IDs, r/R for remote requests and d/D (b/B with bit rate switch) for
+// CAN FD, where L is the DLC. With timestamps enabled the adapter appends
+// four hex digits of milliseconds that wrap every minute.
+package candecoder
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+const (
+	slcanStampWrap = 60000 // adapter timestamps count milliseconds modulo a minute
+	slcanMaxLine   = 256   // longer lines are line noise; a CAN FD frame needs 142
+	slcanResync    = 5 * time.Second
+)
+
+// slcanBitrates maps CAN bitrates to the setup command's S0-S8 codes.
+var slcanBitrates = map[int]byte{
+	10000: '0', 20000: '1', 50000: '2', 100000: '3', 125000: '4',
+	250000: '5', 500000: '6', 800000: '7', 1000000: '8',
+}
+
+// slcanFDLengths maps CAN FD DLC codes to data lengths.
+var slcanFDLengths = [16]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 12, 16, 20, 24, 32, 48, 64}
+
+// SLCANFrame is one frame received from an SLCAN adapter.
+type SLCANFrame struct {
+	Time   time.Time // host time of Stamp; zero for frames without one
+	Stamp  int       // adapter timestamp in ms within the minute, -1 if none
+	ID     types.CANID
+	FD     bool
+	BRS    bool // CAN FD frame sent with bit rate switch
+	Remote bool
+	Data   []byte
+}
+
+// ParseSLCANLine parses one received frame without its '\r' terminator.
+func ParseSLCANLine(line string) (SLCANFrame, error) {
+	f := SLCANFrame{Stamp: -1}
+	if line == "" {
+		return f, fmt.Errorf("empty SLCAN line")
+	}
+	idLen := 3
+	switch line[0] {
+	case 't':
+	case 'T':
+		idLen = 8
+	case 'r':
+		f.Remote = true
+	case 'R':
+		f.Remote, idLen = true, 8
+	case 'd':
+		f.FD = true
+	case 'D':
+		f.FD, idLen = true, 8
+	case 'b':
+		f.FD, f.BRS = true, true
+	case 'B':
+		f.FD, f.BRS, idLen = true, true, 8
+	default:
+		return f, fmt.Errorf("unknown SLCAN command '%c'", line[0])
+	}
+	if len(line) < 1+idLen+1 {
+		return f, fmt.Errorf("SLCAN frame '%s' is too short", line)
+	}
+	id, err := strconv.ParseUint(line[1:1+idLen], 16, 32)
+	if err != nil {
+		return f, fmt.Errorf("parse CAN id '%s': %v", line[1:1+idLen], err)
+	}
+	if idLen == 8 {
+		if id > 0x1FFFFFFF {
+			return f, fmt.Errorf("CAN id '%s' exceeds 29 bits", line[1:1+idLen])
+		}
+		f.ID = types.NewCANID(uint32(id), true)
+	} else {
+		if id > 0x7FF {
+			return f, fmt.Errorf("CAN id '%s' exceeds 11 bits", line[1:1+idLen])
+		}
+		f.ID = types.NewCANID(uint32(id), false)
+	}
+
+	dlc, err := strconv.ParseUint(line[1+idLen:2+idLen], 16, 8)
+	if err != nil {
+		return f, fmt.Errorf("parse DLC '%s': %v", line[1+idLen:2+idLen], err)
+	}
+	n := int(dlc)
+	if f.FD {
+		n = slcanFDLengths[dlc]
+	} else if n > 8 {
+		return f, fmt.Errorf("DLC %d exceeds 8", n)
+	}
+	rest := line[2+idLen:]
+	if !f.Remote {
+		if len(rest) < 2*n {
+			return f, fmt.Errorf("expected %d data bytes in '%s'", n, line)
+		}
+		if f.Data, err = hex.DecodeString(rest[:2*n]); err != nil {
+			return f, fmt.Errorf("parse data '%s': %v", rest[:2*n], err)
+		}
+		rest = rest[2*n:]
+	}
+	switch len(rest) {
+	case 0:
+	case 4:
+		stamp, err := strconv.ParseUint(rest, 16, 16)
+		if err != nil || stamp >= slcanStampWrap {
+			return f, fmt.Errorf("invalid timestamp '%s'", rest)
+		}
+		f.Stamp = int(stamp)
+	default:
+		return f, fmt.Errorf("unexpected '%s' after frame data", rest)
+	}
+	return f, nil
+}
+
+// SLCANSetup returns the commands that close the channel, set the CAN bitrate
+// and timestamp mode and reopen it in normal mode.
+func SLCANSetup(bitrate int, timestamps bool) (string, error) {
+	code, ok := slcanBitrates[bitrate]
+	if !ok {
+		return "", fmt.Errorf("SLCAN does not support a bitrate of %d", bitrate)
+	}
+	stamps := '0'
+	if timestamps {
+		stamps = '1'
+	}
+	return fmt.Sprintf("C\rS%c\rZ%c\rO\r", code, stamps), nil
+}
+
+// SLCANReader reads frames from an SLCAN adapter. Acknowledgements, version
+// replies and other responses are skipped. Adapter timestamps are anchored at
+// the host time the first stamped frame arrived and advanced by the adapter's
+// clock; the anchor is reset when the two drift more than slcanResync apart,
+// as after a gap of a minute or more between frames.
+type SLCANReader struct {
+	r     *bufio.Reader
+	dev   io.WriteCloser // the serial device, nil for plain streams
+	line  []byte
+	last  time.Time // host time of the last stamped frame
+	stamp int       // its adapter timestamp
+
+	Frames  uint64 // frames returned
+	Skipped uint64 // malformed lines
+}
+
+// NewSLCANReader returns a reader over the adapter output r, which must
+// already be set up.
+func NewSLCANReader(r io.Reader) *SLCANReader {
+	return &SLCANReader{r: bufio.NewReader(r), line: make([]byte, 0, slcanMaxLine)}
+}
+
+// OpenSLCAN opens the SLCAN adapter at a serial device path (or the slave side
+// of a pseudo-terminal), sets its CAN bitrate and timestamp mode and opens the
+// CAN channel.
+func OpenSLCAN(path string, bitrate int, timestamps bool) (*SLCANReader, error) {
+	setup, err := SLCANSetup(bitrate, timestamps)
+	if err != nil {
+		return nil, err
+	}
+	dev, err := openSerial(path)
+	if err != nil {
+		return nil, fmt.Errorf("open SLCAN device %s: %v", path, err)
+	}
+	if _, err := io.WriteString(dev, setup); err != nil {
+		dev.Close()
+		return nil, fmt.Errorf("set up SLCAN device %s: %v", path, err)
+	}
+	s := NewSLCANReader(dev)
+	s.dev = dev
+	return s, nil
+}
+
+// Close closes the CAN channel and the device.
+func (s *SLCANReader) Close() error {
+	if s.dev == nil {
+		return nil
+	}
+	io.WriteString(s.dev, "C\r")
+	return s.dev.Close()
+}
+
+// Next returns the next frame, or io.EOF at the end of the stream. Malformed
+// lines return an error wrapping ErrLogSyntax; reading may continue after
+// them.
+func (s *SLCANReader) Next() (SLCANFrame, error) {
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return SLCANFrame{}, err
+		}
+		if len(line) == 0 || !isSLCANFrameCommand(line[0]) {
+			continue
+		}
+		f, err := ParseSLCANLine(string(line))
+		if err != nil {
+			s.Skipped++
+			return f, fmt.Errorf("%w: %v", ErrLogSyntax, err)
+		}
+		if f.Stamp >= 0 {
+			f.Time = s.stampTime(f.Stamp, time.Now())
+		}
+		s.Frames++
+		return f, nil
+	}
+}
+
+// ReadFrame implements FrameReader. Frames without an adapter timestamp carry
+// no time.
+func (s *SLCANReader) ReadFrame() (Frame, error) {
+	for {
+		f, err := s.Next()
+		if err != nil {
+			return Frame{}, err
+		}
+		if !f.Remote {
+			return Frame{Time: f.Time, ID: f.ID, Data: f.Data}, nil
+		}
+	}
+}
+
+// readLine returns the next line terminated by '\r', '\n' or the BELL the
+// adapter sends for a failed command. Overlong lines are dropped.
+func (s *SLCANReader) readLine() ([]byte, error) {
+	s.line = s.line[:0]
+	overlong := false
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(s.line) > 0 && !overlong {
+				return s.line, nil
+			}
+			return nil, err
+		}
+		switch c {
+		case '\r', '\n', '\a':
+			if overlong {
+				s.Skipped++
+				s.line, overlong = s.line[:0], false
+				continue
+			}
+			return s.line, nil
+		}
+		if len(s.line) < slcanMaxLine {
+			s.line = append(s.line, c)
+		} else {
+			overlong = true
+		}
+	}
+}
+
+// stampTime converts an adapter timestamp received at host time now.
+func (s *SLCANReader) stampTime(stamp int, now time.Time) time.Time {
+	t := now
+	if !s.last.IsZero() {
+		delta := (stamp - s.stamp + slcanStampWrap) % slcanStampWrap
+		t = s.last.Add(time.Duration(delta) * time.Millisecond)
+		if d := t.Sub(now); d > slcanResync || d < -slcanResync {
+			t = now
+		}
+	}
+	s.last, s.stamp = t, stamp
+	return t
+}
+
+// isSLCANFrameCommand reports whether c starts a received frame.
+func isSLCANFrameCommand(c byte) bool {
+	switch c {
+	case 't', 'T', 'r', 'R', 'd', 'D', 'b', 'B':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package candecoder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"telem-system/pkg/types"
+)
+
+// TestOpenSLCANPseudoTerminal drives OpenSLCAN through a pseudo-terminal the
+// way a USB-SLCAN dongle would: the setup commands arrive on the master side
+// and frames written there are read back.
+func TestOpenSLCANPseudoTerminal(t *testing.T) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo-terminals not available: %v", err)
+	}
+	defer master.Close()
+	if err := unix.IoctlSetPointerInt(int(master.Fd()), unix.TIOCSPTLCK, 0); err != nil {
+		t.Fatal(err)
+	}
+	n, err := unix.IoctlGetInt(int(master.Fd()), unix.TIOCGPTN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenSLCAN(fmt.Sprintf("/dev/pts/%d", n), 500000, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	setup := make([]byte, len("C\rS6\rZ1\rO\r"))
+	if _, err := io.ReadFull(master, setup); err != nil || string(setup) != "C\rS6\rZ1\rO\r" {
+		t.Fatalf("setup = %q, %v", setup, err)
+	}
+	if _, err := io.WriteString(master, "\r\r\r\rT000012343DEADBE1F40\r"); err != nil {
+		t.Fatal(err)
+	}
+	f, err := r.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame: %v", err)
+	}
+	if f.ID != types.NewCANID(0x1234, true) || !bytes.Equal(f.Data, []byte{0xDE, 0xAD, 0xBE}) || f.Time.IsZero() {
+		t.Errorf("frame = %+v", f)
+	}
+}
@@ -0,0 +1,87 @@
+package candecoder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+func TestSLCANReader(t *testing.T) {
+	stream := "\r\aV1013\r" +
+		"t0653ABCDEF\r" +
+		"T18FF50E52A1B2\r" +
+		"r1232\r" +
+		"d0659" + strings.Repeat("00", 11) + "42\r" +
+		"t0061FF\rt0061GG\r" +
+		"t0652AAAA0010\rz\r"
+	r := NewSLCANReader(strings.NewReader(stream))
+	want := []struct {
+		id   types.CANID
+		data []byte
+	}{
+		{types.NewCANID(0x65, false), []byte{0xAB, 0xCD, 0xEF}},
+		{types.NewCANID(0x18FF50E5, true), []byte{0xA1, 0xB2}},
+		{types.NewCANID(0x65, false), append(make([]byte, 11), 0x42)},
+		{types.NewCANID(0x6, false), []byte{0xFF}},
+	}
+	for i, w := range want {
+		f, err := r.ReadFrame()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if f.ID != w.id || !bytes.Equal(f.Data, w.data) || !f.Time.IsZero() {
+			t.Errorf("frame %d = %+v, want %v % X", i, f, w.id, w.data)
+		}
+	}
+	if _, err := r.ReadFrame(); !errors.Is(err, ErrLogSyntax) {
+		t.Errorf("bad data: got %v, want ErrLogSyntax", err)
+	}
+	f, err := r.Next()
+	if err != nil || f.Stamp != 0x10 || f.Time.IsZero() {
+		t.Errorf("timestamped frame = %+v, %v", f, err)
+	}
+	if _, err := r.ReadFrame(); err != io.EOF {
+		t.Errorf("end of stream: got %v, want io.EOF", err)
+	}
+	if r.Frames != 6 || r.Skipped != 1 {
+		t.Errorf("Frames, Skipped = %d, %d; want 6, 1", r.Frames, r.Skipped)
+	}
+
+	for _, line := range []string{"t0659" + strings.Repeat("00", 9), "t80000", "T200000000", "t06520102FFFFF", "t0652"} {
+		if _, err := ParseSLCANLine(line); err == nil {
+			t.Errorf("ParseSLCANLine(%q) accepted", line)
+		}
+	}
+}
+
+func TestSLCANTimestamps(t *testing.T) {
+	var r SLCANReader
+	base := time.Date(2024, 11, 16, 12, 0, 0, 0, time.UTC)
+	if got := r.stampTime(59990, base); !got.Equal(base) {
+		t.Errorf("first stamp = %v, want host time %v", got, base)
+	}
+	// The adapter clock wraps after 59999 ms; host arrival jitter is ignored.
+	if got := r.stampTime(15, base.Add(40*time.Millisecond)); !got.Equal(base.Add(25 * time.Millisecond)) {
+		t.Errorf("wrapped stamp = %v, want %v", got, base.Add(25*time.Millisecond))
+	}
+	// After a silence longer than the wrap the host time is taken again.
+	later := base.Add(3 * time.Minute)
+	if got := r.stampTime(20, later); !got.Equal(later) {
+		t.Errorf("stamp after gap = %v, want %v", got, later)
+	}
+}
+
+func TestSLCANSetup(t *testing.T) {
+	got, err := SLCANSetup(500000, true)
+	if err != nil || got != "C\rS6\rZ1\rO\r" {
+		t.Errorf("SLCANSetup(500000, true) = %q, %v", got, err)
+	}
+	if _, err := SLCANSetup(33333, false); err == nil {
+		t.Error("unsupported bitrate accepted")
+	}
+}
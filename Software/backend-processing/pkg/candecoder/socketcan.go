@@ -0,0 +1,56 @@
+// socketcan.go
+//
+// Decoding of frames read from a Linux raw CAN socket. Reads return a struct
+// can_frame (16 bytes) or, with CAN_RAW_FD_FRAMES enabled, a struct
+// canfd_frame (72 bytes); both start with the 32-bit can_id in host byte
+// order, whose top bits flag extended, remote and error frames.
+package candecoder
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"telem-system/pkg/types"
+)
+
+// Sizes of struct can_frame and struct canfd_frame, and can_id flags.
+const (
+	socketCANMTU   = 16
+	socketCANFDMTU = 72
+
+	socketCANEFFFlag = 0x80000000
+	socketCANRTRFlag = 0x40000000
+	socketCANErrFlag = 0x20000000
+	socketCANEFFMask = 0x1FFFFFFF
+	socketCANSFFMask = 0x7FF
+)
+
+// parseSocketCANFrame decodes one frame as read from a raw CAN socket. It
+// returns false for remote and error frames.
+func parseSocketCANFrame(b []byte) (Frame, bool, error) {
+	var f Frame
+	var limit int
+	switch len(b) {
+	case socketCANMTU:
+		limit = 8
+	case socketCANFDMTU:
+		limit = 64
+	default:
+		return f, false, fmt.Errorf("unexpected SocketCAN frame size %d", len(b))
+	}
+	canID := binary.NativeEndian.Uint32(b)
+	if canID&(socketCANRTRFlag|socketCANErrFlag) != 0 {
+		return f, false, nil
+	}
+	if canID&socketCANEFFFlag != 0 {
+		f.ID = types.NewCANID(canID&socketCANEFFMask, true)
+	} else {
+		f.ID = types.NewCANID(canID&socketCANSFFMask, false)
+	}
+	n := int(b[4])
+	if n > limit {
+		return f, false, fmt.Errorf("SocketCAN frame length %d exceeds %d", n, limit)
+	}
+	f.Data = b[8 : 8+n]
+	return f, true, nil
+}
@@ -0,0 +1,96 @@
+// socketcan_linux.go
+//
+// Live frames from a SocketCAN interface (a USB-CAN adapter as can0, or
+// vcan0 for testing). The socket accepts CAN FD frames where the kernel
+// supports them and asks for a nanosecond receive timestamp with every frame,
+// so frames carry the time the kernel received them rather than the time
+// they were decoded.
+package candecoder
+
+import (
+	"fmt"
+	"net"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// SocketCANReader reads frames from a raw CAN socket bound to one interface.
+type SocketCANReader struct {
+	fd  int
+	buf []byte
+	oob []byte
+}
+
+// OpenSocketCAN opens a raw CAN socket on the named interface.
+func OpenSocketCAN(iface string) (*SocketCANReader, error) {
+	ifi, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, fmt.Errorf("SocketCAN interface %s: %v", iface, err)
+	}
+	fd, err := unix.Socket(unix.AF_CAN, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.CAN_RAW)
+	if err != nil {
+		return nil, fmt.Errorf("open SocketCAN socket: %v", err)
+	}
+	// Without CAN FD support in the kernel only classic frames are read.
+	unix.SetsockoptInt(fd, unix.SOL_CAN_RAW, unix.CAN_RAW_FD_FRAMES, 1)
+	if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_TIMESTAMPNS, 1); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("enable SocketCAN timestamps: %v", err)
+	}
+	if err := unix.Bind(fd, &unix.SockaddrCAN{Ifindex: ifi.Index}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("bind SocketCAN %s: %v", iface, err)
+	}
+	return &SocketCANReader{
+		fd:  fd,
+		buf: make([]byte, socketCANFDMTU),
+		oob: make([]byte, unix.CmsgSpace(int(unsafe.Sizeof(unix.Timespec{})))),
+	}, nil
+}
+
+// ReadFrame implements FrameReader. Frames are timed by the kernel; Data is
+// only valid until the next call.
+func (s *SocketCANReader) ReadFrame() (Frame, error) {
+	for {
+		n, oobn, _, _, err := unix.Recvmsg(s.fd, s.buf, s.oob, 0)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return Frame{}, fmt.Errorf("read SocketCAN: %v", err)
+		}
+		f, ok, err := parseSocketCANFrame(s.buf[:n])
+		if err != nil {
+			return Frame{}, err
+		}
+		if !ok {
+			continue
+		}
+		f.Time = receiveTime(s.oob[:oobn])
+		return f, nil
+	}
+}
+
+// Close closes the socket.
+func (s *SocketCANReader) Close() error {
+	return unix.Close(s.fd)
+}
+
+// receiveTime returns the SO_TIMESTAMPNS time of a received message, or now
+// if the kernel did not supply one.
+func receiveTime(oob []byte) time.Time {
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return time.Now()
+	}
+	for _, m := range msgs {
+		if m.Header.Level == unix.SOL_SOCKET && m.Header.Type == unix.SCM_TIMESTAMPNS &&
+			len(m.Data) >= int(unsafe.Sizeof(unix.Timespec{})) {
+			ts := (*unix.Timespec)(unsafe.Pointer(&m.Data[0]))
+			return time.Unix(ts.Unix())
+		}
+	}
+	return time.Now()
+}
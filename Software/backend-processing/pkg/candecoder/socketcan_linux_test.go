@@ -0,0 +1,65 @@
+package candecoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"telem-system/pkg/types"
+)
+
+// TestSocketCANVirtualInterface sends frames on vcan0 and reads them back. It
+// is skipped unless vcan0 exists:
+//
+//	ip link add dev vcan0 type vcan && ip link set up vcan0
+func TestSocketCANVirtualInterface(t *testing.T) {
+	r, err := OpenSocketCAN("vcan0")
+	if err != nil {
+		t.Skipf("vcan0 not available: %v", err)
+	}
+	defer r.Close()
+
+	ifi, _ := net.InterfaceByName("vcan0")
+	tx, err := unix.Socket(unix.AF_CAN, unix.SOCK_RAW, unix.CAN_RAW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(tx)
+	unix.SetsockoptInt(tx, unix.SOL_CAN_RAW, unix.CAN_RAW_FD_FRAMES, 1)
+	if err := unix.Bind(tx, &unix.SockaddrCAN{Ifindex: ifi.Index}); err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Now()
+	classic := make([]byte, socketCANMTU)
+	binary.NativeEndian.PutUint32(classic, 0x006)
+	classic[4] = 3
+	copy(classic[8:], []byte{1, 2, 3})
+	if _, err := unix.Write(tx, classic); err != nil {
+		t.Fatal(err)
+	}
+	f, err := r.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame: %v", err)
+	}
+	if f.ID != types.NewCANID(6, false) || !bytes.Equal(f.Data, []byte{1, 2, 3}) {
+		t.Errorf("frame = %+v", f)
+	}
+	if f.Time.Before(before.Add(-time.Second)) || f.Time.After(time.Now()) {
+		t.Errorf("kernel timestamp %v not near %v", f.Time, before)
+	}
+
+	fd := make([]byte, socketCANFDMTU)
+	binary.NativeEndian.PutUint32(fd, 0x1234|socketCANEFFFlag)
+	fd[4] = 16
+	if _, err := unix.Write(tx, fd); err != nil {
+		t.Skipf("vcan0 does not accept CAN FD frames: %v", err)
+	}
+	if f, err := r.ReadFrame(); err != nil || f.ID != types.NewCANID(0x1234, true) || len(f.Data) != 16 {
+		t.Errorf("FD frame = %+v, %v", f, err)
+	}
+}
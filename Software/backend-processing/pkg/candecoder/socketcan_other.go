@@ -0,0 +1,24 @@
+//go:build !linux
+
+// socketcan_other.go
+//
+// SocketCAN exists only on Linux; elsewhere OpenSocketCAN reports an error.
+package candecoder
+
+import "errors"
+
+// SocketCANReader reads frames from a raw CAN socket bound to one interface.
+type SocketCANReader struct{}
+
+// OpenSocketCAN opens a raw CAN socket on the named interface.
+func OpenSocketCAN(iface string) (*SocketCANReader, error) {
+	return nil, errors.New("SocketCAN is only supported on Linux")
+}
+
+// ReadFrame implements FrameReader.
+func (s *SocketCANReader) ReadFrame() (Frame, error) {
+	return Frame{}, errors.New("SocketCAN is only supported on Linux")
+}
+
+// Close closes the socket.
+func (s *SocketCANReader) Close() error { return nil }
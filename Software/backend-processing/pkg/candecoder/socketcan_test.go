@@ -0,0 +1,42 @@
+package candecoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+func TestParseSocketCANFrame(t *testing.T) {
+	classic := make([]byte, socketCANMTU)
+	binary.NativeEndian.PutUint32(classic, 0x065)
+	classic[4] = 2
+	copy(classic[8:], []byte{0xAB, 0xCD, 0xEE})
+	f, ok, err := parseSocketCANFrame(classic)
+	if err != nil || !ok || f.ID != types.NewCANID(0x65, false) || !bytes.Equal(f.Data, []byte{0xAB, 0xCD}) {
+		t.Errorf("classic frame = %+v, %v, %v", f, ok, err)
+	}
+
+	fd := make([]byte, socketCANFDMTU)
+	binary.NativeEndian.PutUint32(fd, 0x18FF50E5|socketCANEFFFlag)
+	fd[4] = 12
+	fd[8+11] = 0x42
+	f, ok, err = parseSocketCANFrame(fd)
+	if err != nil || !ok || f.ID != types.NewCANID(0x18FF50E5, true) || len(f.Data) != 12 || f.Data[11] != 0x42 {
+		t.Errorf("FD frame = %+v, %v, %v", f, ok, err)
+	}
+
+	binary.NativeEndian.PutUint32(classic, 0x065|socketCANRTRFlag)
+	if _, ok, err := parseSocketCANFrame(classic); ok || err != nil {
+		t.Errorf("remote frame accepted: %v", err)
+	}
+	classic[4] = 9
+	binary.NativeEndian.PutUint32(classic, 0x065)
+	if _, _, err := parseSocketCANFrame(classic); err == nil {
+		t.Error("classic frame with 9 bytes accepted")
+	}
+	if _, _, err := parseSocketCANFrame(make([]byte, 20)); err == nil {
+		t.Error("short read accepted")
+	}
+}
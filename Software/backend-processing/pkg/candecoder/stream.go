@@ -0,0 +1,141 @@
+// stream.go
+//
+// A common frame stream over the log and live parsers. Every reader yields
+// its data frames through FrameReader, skipping remote requests and error
+// frames, so importers and replay tools handle all formats alike.
+package candecoder
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// Frame is a CAN data frame from any source.
+type Frame struct {
+	Time time.Time // capture time; zero for frames received live
+	ID   types.CANID
+	Data []byte
+}
+
+// FrameReader yields data frames until io.EOF. Errors wrapping ErrLogSyntax
+// skip one malformed record; reading may continue after them.
+type FrameReader interface {
+	ReadFrame() (Frame, error)
+}
+
+// ReadFrame implements FrameReader.
+func (c *CandumpReader) ReadFrame() (Frame, error) {
+	for {
+		f, err := c.Next()
+		if err != nil {
+			return Frame{}, err
+		}
+		if !f.Remote && !f.ErrorFrame {
+			return Frame{Time: f.Time, ID: f.ID, Data: f.Data}, nil
+		}
+	}
+}
+
+// ReadFrame implements FrameReader. Frames of logs without a date header are
+// timed from Fallback.
+func (a *ASCReader) ReadFrame() (Frame, error) {
+	for {
+		f, err := a.Next()
+		if err != nil {
+			return Frame{}, err
+		}
+		if f.Remote || f.ErrorFrame {
+			continue
+		}
+		if f.Time.IsZero() && !a.Fallback.IsZero() {
+			f.Time = a.Fallback.Add(f.Offset)
+		}
+		return Frame{Time: f.Time, ID: f.ID, Data: f.Data}, nil
+	}
+}
+
+// ReadFrame implements FrameReader. Bridge frames are live and carry no time;
+// Data is only valid until the next call.
+func (b *BridgeReader) ReadFrame() (Frame, error) {
+	f, err := b.Next()
+	if err != nil {
+		return Frame{}, err
+	}
+	return Frame{ID: f.ID, Data: f.Data}, nil
+}
+
+// KvaserReader reads a Kvaser CSV log. Columns follow the header row, or the
+// default Kvaser order until one is seen. Frame times are anchored at the
+// first row's AbsTime and advanced by the Time column, which has sub-second
+// resolution; logs without AbsTime keep zero times.
+type KvaserReader struct {
+	csv    *csv.Reader
+	layout *KvaserLayout
+	anchor time.Time // AbsTime of the first timed row minus its Time
+}
+
+// NewKvaserReader returns a reader over r.
+func NewKvaserReader(r io.Reader) *KvaserReader {
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = -1
+	c.ReuseRecord = true
+	return &KvaserReader{csv: c, layout: DefaultKvaserLayout()}
+}
+
+// ReadFrame implements FrameReader.
+func (k *KvaserReader) ReadFrame() (Frame, error) {
+	for {
+		record, err := k.csv.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return Frame{}, fmt.Errorf("%w: %v", ErrLogSyntax, err)
+			}
+			return Frame{}, err
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if l, ok := ParseKvaserHeader(record); ok {
+			k.layout = l
+			continue
+		}
+		row, err := k.layout.ParseRow(record)
+		if err != nil {
+			line, _ := k.csv.FieldPos(0)
+			return Frame{}, fmt.Errorf("line %d: %w: %v", line, ErrLogSyntax, err)
+		}
+		if row.IsRemote() || row.IsErrorFrame() {
+			continue
+		}
+		if k.anchor.IsZero() && !row.AbsTime.IsZero() {
+			k.anchor = row.AbsTime.Add(-secondsDuration(row.Time))
+		}
+		f := Frame{ID: row.ID, Data: row.Data}
+		if !k.anchor.IsZero() {
+			f.Time = k.anchor.Add(secondsDuration(row.Time))
+		}
+		return f, nil
+	}
+}
+
+// isBlankRecord reports whether every field of a CSV record is empty.
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// secondsDuration converts fractional seconds to a Duration.
+func secondsDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,111 @@
+// udp.go
+//
+// Datagram format of the UDP telemetry link. Every datagram is self-contained
+// so a lost or late datagram never holds up the ones behind it:
+//
+//	[1-byte version = 1][1-byte flags = 0][2-byte sender ID][4-byte sequence]
+//	[8-byte sender timestamp, µs][2-byte frame count]
+//	count × [4-byte ID][4-byte time offset, µs][1-byte length][data]
+//
+// All integers are big-endian. The sequence number counts datagrams per sender
+// and wraps; the sender timestamp is the capture time of the batch on the
+// sender's clock (any epoch) and each frame's offset is added to it. IDs have
+// bit 31 set for extended frames, as on the ESP32 bridge, and CAN FD frames
+// carry up to 64 bytes.
+package candecoder
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"telem-system/pkg/types"
+)
+
+// UDPVersion is the version byte of the datagram format.
+const UDPVersion = 1
+
+const (
+	udpHeaderLen      = 18
+	udpFrameHeaderLen = 9
+	udpMaxData        = 64
+)
+
+// UDPFrame is one CAN frame of a datagram.
+type UDPFrame struct {
+	Offset uint32 // µs after the datagram's sender timestamp
+	ID     types.CANID
+	Data   []byte
+}
+
+// UDPDatagram is one datagram of the UDP telemetry link.
+type UDPDatagram struct {
+	Sender     uint16
+	Seq        uint32
+	SenderTime uint64 // µs on the sender's clock
+	Frames     []UDPFrame
+}
+
+// ParseUDPDatagram parses a datagram. Frame data aliases b.
+func ParseUDPDatagram(b []byte) (UDPDatagram, error) {
+	var d UDPDatagram
+	if len(b) < udpHeaderLen {
+		return d, fmt.Errorf("UDP datagram of %d bytes is shorter than its header", len(b))
+	}
+	if b[0] != UDPVersion {
+		return d, fmt.Errorf("unsupported UDP datagram version %d", b[0])
+	}
+	d.Sender = binary.BigEndian.Uint16(b[2:])
+	d.Seq = binary.BigEndian.Uint32(b[4:])
+	d.SenderTime = binary.BigEndian.Uint64(b[8:])
+	count := int(binary.BigEndian.Uint16(b[16:]))
+	d.Frames = make([]UDPFrame, 0, count)
+	rest := b[udpHeaderLen:]
+	for i := 0; i < count; i++ {
+		if len(rest) < udpFrameHeaderLen {
+			return d, fmt.Errorf("UDP datagram truncated in frame %d of %d", i+1, count)
+		}
+		id := types.CANID(binary.BigEndian.Uint32(rest))
+		if id.Extended() && id.ID() > 0x1FFFFFFF || !id.Extended() && id.ID() > 0x7FF {
+			return d, fmt.Errorf("invalid CAN id %08X in frame %d", uint32(id), i+1)
+		}
+		n := int(rest[8])
+		if n > udpMaxData {
+			return d, fmt.Errorf("frame %d length %d exceeds %d", i+1, n, udpMaxData)
+		}
+		if len(rest) < udpFrameHeaderLen+n {
+			return d, fmt.Errorf("UDP datagram truncated in frame %d of %d", i+1, count)
+		}
+		d.Frames = append(d.Frames, UDPFrame{
+			Offset: binary.BigEndian.Uint32(rest[4:]),
+			ID:     id,
+			Data:   rest[udpFrameHeaderLen : udpFrameHeaderLen+n],
+		})
+		rest = rest[udpFrameHeaderLen+n:]
+	}
+	if len(rest) != 0 {
+		return d, fmt.Errorf("%d bytes after the last frame", len(rest))
+	}
+	return d, nil
+}
+
+// AppendUDPDatagram appends the encoding of d to dst.
+func AppendUDPDatagram(dst []byte, d UDPDatagram) ([]byte, error) {
+	if len(d.Frames) > 0xFFFF {
+		return dst, fmt.Errorf("%d frames do not fit one datagram", len(d.Frames))
+	}
+	dst = append(dst, UDPVersion, 0)
+	dst = binary.BigEndian.AppendUint16(dst, d.Sender)
+	dst = binary.BigEndian.AppendUint32(dst, d.Seq)
+	dst = binary.BigEndian.AppendUint64(dst, d.SenderTime)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(d.Frames)))
+	for _, f := range d.Frames {
+		if len(f.Data) > udpMaxData {
+			return dst, fmt.Errorf("frame %v length %d exceeds %d", f.ID, len(f.Data), udpMaxData)
+		}
+		dst = binary.BigEndian.AppendUint32(dst, uint32(f.ID))
+		dst = binary.BigEndian.AppendUint32(dst, f.Offset)
+		dst = append(dst, byte(len(f.Data)))
+		dst = append(dst, f.Data...)
+	}
+	return dst, nil
+}
@@ -0,0 +1,45 @@
+package candecoder
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"telem-system/pkg/types"
+)
+
+func TestUDPDatagramRoundTrip(t *testing.T) {
+	want := UDPDatagram{
+		Sender:     3,
+		Seq:        0xFFFFFFFF,
+		SenderTime: 12345678901,
+		Frames: []UDPFrame{
+			{Offset: 0, ID: types.NewCANID(0x65, false), Data: []byte{1, 2, 3}},
+			{Offset: 950, ID: types.NewCANID(0x18FF50E5, true), Data: []byte{}},
+			{Offset: 1800, ID: types.NewCANID(0x6, false), Data: bytes.Repeat([]byte{0xAA}, 64)},
+		},
+	}
+	b, err := AppendUDPDatagram(nil, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseUDPDatagram(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+
+	for name, bad := range map[string][]byte{
+		"short header":   b[:10],
+		"truncated":      b[:len(b)-1],
+		"trailing bytes": append(append([]byte{}, b...), 0),
+		"version":        append([]byte{2}, b[1:]...),
+		"standard id":    append(append([]byte{}, b[:18]...), 0x00, 0x00, 0x08, 0x00, 0, 0, 0, 0, 0),
+	} {
+		if _, err := ParseUDPDatagram(bad); err == nil {
+			t.Errorf("%s: datagram accepted", name)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// versions.go
+//
+// Versioned CAN definitions. A definition set is identified by the SHA-256 of
+// its file content, and each vehicle has a history of versions with effective
+// date ranges. Versions picks the set that was in use when a log was recorded,
+// so older sessions are re-decoded with the definitions they were sent with.
+package candecoder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// Definition file formats.
+const (
+	FormatDBC  = "dbc"
+	FormatJSON = "json"
+)
+
+// ErrNoDefinitionVersion is returned when no stored version covers a vehicle
+// at the requested time or matches the requested hash.
+var ErrNoDefinitionVersion = errors.New("no definition version")
+
+// HashDefinitions returns the hex SHA-256 of a definitions file.
+func HashDefinitions(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// DefinitionsFormat returns the format of a definitions file from its
+// extension, as LoadDefinitions decides it.
+func DefinitionsFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".dbc") {
+		return FormatDBC
+	}
+	return FormatJSON
+}
+
+// ParseDefinitions parses the content of a DBC file or cantools JSON export.
+func ParseDefinitions(content []byte, format string) ([]types.Message, error) {
+	switch format {
+	case FormatDBC:
+		messages, err := ParseDBC(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("parse DBC: %v", err)
+		}
+		return messages, nil
+	case FormatJSON:
+		var messages []types.Message
+		if err := json.Unmarshal(content, &messages); err != nil {
+			return nil, fmt.Errorf("parse JSON: %v", err)
+		}
+		return messages, nil
+	}
+	return nil, fmt.Errorf("unknown definitions format '%s'", format)
+}
+
+// Version returns the stored form of the definitions for vehicle, effective
+// from the given time.
+func (d *Definitions) Version(vehicle string, from time.Time) types.DefinitionVersion {
+	return types.DefinitionVersion{
+		Vehicle:       vehicle,
+		Hash:          d.Hash,
+		Format:        d.Format,
+		Content:       d.Content,
+		EffectiveFrom: from,
+	}
+}
+
+// Versions resolves stored definition versions by time or hash. Each version
+// is compiled on first use and cached; Versions is safe for concurrent use.
+type Versions struct {
+	mu       sync.Mutex
+	list     []types.DefinitionVersion // by vehicle, then EffectiveFrom
+	compiled map[string]*Definitions   // by hash
+}
+
+// NewVersions returns a resolver over the given versions.
+func NewVersions(list []types.DefinitionVersion) *Versions {
+	v := &Versions{compiled: make(map[string]*Definitions)}
+	v.Update(list)
+	return v
+}
+
+// Update replaces the known versions, for example after a new version has
+// been activated. Compiled sets are kept, as a hash always maps to the same
+// content.
+func (v *Versions) Update(list []types.DefinitionVersion) {
+	sorted := append([]types.DefinitionVersion(nil), list...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Vehicle != sorted[j].Vehicle {
+			return sorted[i].Vehicle < sorted[j].Vehicle
+		}
+		return sorted[i].EffectiveFrom.Before(sorted[j].EffectiveFrom)
+	})
+	v.mu.Lock()
+	v.list = sorted
+	v.mu.Unlock()
+}
+
+// At returns the definitions that were in effect for vehicle at time t: the
+// latest version starting at or before t that had not yet been replaced.
+func (v *Versions) At(vehicle string, t time.Time) (*Definitions, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for i := len(v.list) - 1; i >= 0; i-- {
+		ver := v.list[i]
+		if ver.Vehicle != vehicle || ver.EffectiveFrom.After(t) {
+			continue
+		}
+		if ver.EffectiveTo != nil && !t.Before(*ver.EffectiveTo) {
+			break
+		}
+		return v.compile(ver)
+	}
+	return nil, fmt.Errorf("%w for %s at %s", ErrNoDefinitionVersion, vehicle, t.Format(time.RFC3339))
+}
+
+// Resolve returns the definitions in effect for vehicle at time t, or
+// fallback if t is zero or no stored version covers it.
+func (v *Versions) Resolve(vehicle string, t time.Time, fallback *Definitions) *Definitions {
+	if t.IsZero() {
+		return fallback
+	}
+	if defs, err := v.At(vehicle, t); err == nil {
+		return defs
+	}
+	return fallback
+}
+
+// ByHash returns the definitions with the given content hash, as recorded on
+// a session.
+func (v *Versions) ByHash(hash string) (*Definitions, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, ver := range v.list {
+		if ver.Hash == hash {
+			return v.compile(ver)
+		}
+	}
+	return nil, fmt.Errorf("%w with hash %s", ErrNoDefinitionVersion, hash)
+}
+
+// compile returns the cached definitions for ver, compiling them on first
+// use. The caller holds v.mu.
+func (v *Versions) compile(ver types.DefinitionVersion) (*Definitions, error) {
+	if defs, ok := v.compiled[ver.Hash]; ok {
+		return defs, nil
+	}
+	if got := HashDefinitions(ver.Content); got != ver.Hash {
+		return nil, fmt.Errorf("definition version %d: content hash %s does not match %s", ver.ID, got, ver.Hash)
+	}
+	defs, err := compileDefinitions(ver.Content, ver.Format)
+	if err != nil {
+		return nil, fmt.Errorf("definition version %d: %v", ver.ID, err)
+	}
+	defs.Path = fmt.Sprintf("%s@%.12s", ver.Vehicle, ver.Hash)
+	v.compiled[ver.Hash] = defs
+	return defs, nil
+}
@@ -0,0 +1,48 @@
+package candecoder
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+func TestVersionsPickByTimeAndHash(t *testing.T) {
+	v1 := []byte("BO_ 291 Frame: 1 TCU\n SG_ A : 0|8@1+ (1,0) [0|0] \"\" Vector__XXX\n")
+	v2 := []byte("BO_ 291 Frame: 1 TCU\n SG_ A : 0|8@1+ (2,0) [0|0] \"\" Vector__XXX\n")
+	day := func(d int) time.Time { return time.Date(2024, 11, d, 0, 0, 0, 0, time.UTC) }
+	until := day(10)
+	versions := NewVersions([]types.DefinitionVersion{
+		{ID: 2, Vehicle: "UCR-01", Hash: HashDefinitions(v2), Format: FormatDBC, Content: v2, EffectiveFrom: day(10)},
+		{ID: 1, Vehicle: "UCR-01", Hash: HashDefinitions(v1), Format: FormatDBC, Content: v1, EffectiveFrom: day(1), EffectiveTo: &until},
+		{ID: 3, Vehicle: "UCR-02", Hash: HashDefinitions(v2), Format: FormatDBC, Content: v2, EffectiveFrom: day(1)},
+	})
+
+	factor := func(d *Definitions) float64 { return d.ByID[types.NewCANID(291, false)].Signals[0].Factor }
+	for _, tc := range []struct {
+		at   time.Time
+		want float64
+	}{{day(1), 1}, {day(9), 1}, {day(10), 2}, {day(20), 2}} {
+		defs, err := versions.At("UCR-01", tc.at)
+		if err != nil || factor(defs) != tc.want {
+			t.Errorf("At(%v) = factor %v, %v; want %v", tc.at, factor(defs), err, tc.want)
+		}
+	}
+	if _, err := versions.At("UCR-01", day(0)); !errors.Is(err, ErrNoDefinitionVersion) {
+		t.Errorf("At before the first version = %v", err)
+	}
+	if defs, err := versions.ByHash(HashDefinitions(v1)); err != nil || factor(defs) != 1 {
+		t.Errorf("ByHash = %v", err)
+	}
+	if a, _ := versions.At("UCR-01", day(15)); a != nil {
+		if b, _ := versions.At("UCR-02", day(15)); a != b {
+			t.Error("identical content compiled twice")
+		}
+	}
+
+	bad := NewVersions([]types.DefinitionVersion{{Vehicle: "UCR-01", Hash: HashDefinitions(v1), Format: FormatDBC, Content: v2, EffectiveFrom: day(1)}})
+	if _, err := bad.At("UCR-01", day(2)); err == nil {
+		t.Error("version with mismatched content hash accepted")
+	}
+}
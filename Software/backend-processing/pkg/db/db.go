@@ -9,6 +9,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"telem-system/pkg/types"
 
@@ -230,7 +231,8 @@ func (q *Queries) FetchFrontAeroDataPaginated(ctx context.Context, limit, offset
 // GPS Best Position Data
 func (q *Queries) FetchGPSBestPosDataPaginated(ctx context.Context, limit, offset int) ([]types.GPSBestPos_Data, error) {
 	query := `
-		SELECT timestamp, latitude, longitude, altitude, std_latitude, std_longitude, std_altitude, gps_status
+		SELECT timestamp, latitude, longitude, altitude, std_latitude, std_longitude, std_altitude, gps_status,
+			COALESCE(gps_status_label, '')
 		FROM gps_best_pos
 		ORDER BY timestamp ASC
 		LIMIT $1 OFFSET $2
@@ -253,6 +255,7 @@ func (q *Queries) FetchGPSBestPosDataPaginated(ctx context.Context, limit, offse
 			&rec.StdLongitude,
 			&rec.StdAltitude,
 			&rec.GPSStatus,
+			&rec.GPSStatusLabel,
 		); err != nil {
 			return nil, err
 		}
@@ -414,7 +417,8 @@ func (q *Queries) FetchACULV2DataPaginated(ctx context.Context, limit, offset in
 // PDM1 Data
 func (q *Queries) FetchPDM1DataPaginated(ctx context.Context, limit, offset int) ([]types.PDM1_Data, error) {
 	query := `
-		SELECT timestamp, compound_id, pdm_int_temperature, pdm_batt_voltage, global_error_flag, total_current, internal_rail_voltage, reset_source
+		SELECT timestamp, compound_id, pdm_int_temperature, pdm_batt_voltage, global_error_flag, total_current, internal_rail_voltage, reset_source,
+			COALESCE(reset_source_label, '')
 		FROM pdm1
 		ORDER BY timestamp ASC
 		LIMIT $1 OFFSET $2
@@ -437,6 +441,7 @@ func (q *Queries) FetchPDM1DataPaginated(ctx context.Context, limit, offset int)
 			&rec.TotalCurrent,
 			&rec.InternalRailVoltage,
 			&rec.ResetSource,
+			&rec.ResetSourceLabel,
 		); err != nil {
 			return nil, err
 		}
@@ -728,7 +733,8 @@ func (q *Queries) FetchPDMCurrentDataPaginated(ctx context.Context, limit, offse
 // FetchPDMReTransmitDataPaginated returns paginated PDM Re-transmit data.
 func (q *Queries) FetchPDMReTransmitDataPaginated(ctx context.Context, limit, offset int) ([]types.PDMReTransmit_Data, error) {
 	query := `
-		SELECT timestamp, pdm_int_temperature, pdm_batt_voltage, global_error_flag, total_current, internal_rail_voltage, reset_source
+		SELECT timestamp, pdm_int_temperature, pdm_batt_voltage, global_error_flag, total_current, internal_rail_voltage, reset_source,
+			COALESCE(reset_source_label, '')
 		FROM pdm_re_transmit
 		ORDER BY timestamp ASC
 		LIMIT $1 OFFSET $2
@@ -750,6 +756,7 @@ func (q *Queries) FetchPDMReTransmitDataPaginated(ctx context.Context, limit, of
 			&rec.TotalCurrent,
 			&rec.InternalRailVoltage,
 			&rec.ResetSource,
+			&rec.ResetSourceLabel,
 		); err != nil {
 			return nil, err
 		}
@@ -927,7 +934,7 @@ func (q *Queries) FetchFrontAnalogDataPaginated(ctx context.Context, limit, offs
 // FetchACULVFD1DataPaginated returns paginated ACULV FD 1 data.
 func (q *Queries) FetchACULVFD1DataPaginated(ctx context.Context, limit, offset int) ([]types.ACULV_FD_1_Data, error) {
 	query := `
-		SELECT timestamp, ams_status, fld, state_of_charge, accumulator_voltage, tractive_voltage, cell_current, isolation_monitoring, isolation_monitoring1
+		SELECT timestamp, ams_status, COALESCE(ams_status_label, ''), fld, state_of_charge, accumulator_voltage, tractive_voltage, cell_current, isolation_monitoring, isolation_monitoring1
 		FROM aculv_fd_1
 		ORDER BY timestamp ASC
 		LIMIT $1 OFFSET $2
@@ -944,6 +951,7 @@ func (q *Queries) FetchACULVFD1DataPaginated(ctx context.Context, limit, offset
 		if err := rows.Scan(
 			&rec.Timestamp,
 			&rec.AMSStatus,
+			&rec.AMSStatusLabel,
 			&rec.FLD,
 			&rec.StateOfCharge,
 			&rec.AccumulatorVoltage,
@@ -963,133 +971,159 @@ func (q *Queries) FetchACULVFD1DataPaginated(ctx context.Context, limit, offset
 // --- INSERT FUNCTIONS ---
 //
 
-func (q *Queries) InsertRearStrainGauges2Data(ctx context.Context, data types.RearStrainGauges2_Data) error {
+// execInsert runs an INSERT whose VALUES follow its column list and writes
+// NULL into the columns named in nulls, the invalid signals of a frame.
+// Names match the columns by ColumnKey.
+func execInsert(ctx context.Context, db *sql.DB, query string, nulls []string, args ...interface{}) error {
+	_, err := db.ExecContext(ctx, query, nullArgs(query, nulls, args)...)
+	return err
+}
+
+// nullArgs returns args with the values of the null columns replaced by nil.
+func nullArgs(query string, nulls []string, args []interface{}) []interface{} {
+	if len(nulls) == 0 {
+		return args
+	}
+	open, end := strings.Index(query, "("), strings.Index(query, ")")
+	if open < 0 || end < open {
+		return args
+	}
+	null := make(map[string]bool, len(nulls))
+	for _, name := range nulls {
+		null[ColumnKey(name)] = true
+	}
+	out := append([]interface{}(nil), args...)
+	for i, col := range strings.Split(query[open+1:end], ",") {
+		if i < len(out) && null[ColumnKey(strings.TrimSpace(col))] {
+			out[i] = nil
+		}
+	}
+	return out
+}
+
+// ColumnKey normalises a signal or column name for matching, so that
+// "StateOfCharge" and "state_of_charge" compare equal.
+func ColumnKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+func (q *Queries) InsertRearStrainGauges2Data(ctx context.Context, data types.RearStrainGauges2_Data, nulls ...string) error {
 	query := `
         INSERT INTO rear_strain_gauges_2 (
             timestamp, gauge1, gauge2, gauge3, gauge4, gauge5, gauge6
         )
         VALUES ($1, $2, $3, $4, $5, $6, $7)
     `
-	_, err := q.db.ExecContext(ctx, query,
+	return execInsert(ctx, q.db, query, nulls,
 		data.Timestamp, data.Gauge1, data.Gauge2, data.Gauge3, data.Gauge4, data.Gauge5, data.Gauge6,
 	)
-	return err
 }
 
-func (q *Queries) InsertRearStrainGauges1Data(ctx context.Context, data types.RearStrainGauges1_Data) error {
+func (q *Queries) InsertRearStrainGauges1Data(ctx context.Context, data types.RearStrainGauges1_Data, nulls ...string) error {
 	query := `
         INSERT INTO rear_strain_gauges_1 (
             timestamp, gauge1, gauge2, gauge3, gauge4, gauge5, gauge6
         )
         VALUES ($1, $2, $3, $4, $5, $6, $7)
     `
-	_, err := q.db.ExecContext(ctx, query,
+	return execInsert(ctx, q.db, query, nulls,
 		data.Timestamp, data.Gauge1, data.Gauge2, data.Gauge3, data.Gauge4, data.Gauge5, data.Gauge6,
 	)
-	return err
 }
 
-func (q *Queries) InsertBamocarRxData(ctx context.Context, data types.BamocarRxData_Data) error {
+func (q *Queries) InsertBamocarRxData(ctx context.Context, data types.BamocarRxData_Data, nulls ...string) error {
 	query := `
         INSERT INTO bamocar_rx_data (
             timestamp, regid, byte1, byte2, byte3, byte4, byte5
         )
         VALUES ($1, $2, $3, $4, $5, $6, $7)
     `
-	_, err := q.db.ExecContext(ctx, query,
+	return execInsert(ctx, q.db, query, nulls,
 		data.Timestamp, data.REGID, data.Byte1, data.Byte2, data.Byte3, data.Byte4, data.Byte5,
 	)
-	return err
 }
 
-func (q *Queries) InsertRearAnalogData(ctx context.Context, data types.RearAnalog_Data) error {
+func (q *Queries) InsertRearAnalogData(ctx context.Context, data types.RearAnalog_Data, nulls ...string) error {
 	query := `
         INSERT INTO rear_analog (
             timestamp, analog1, analog2, analog3, analog4, analog5, analog6, analog7, analog8
         )
         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
     `
-	_, err := q.db.ExecContext(ctx, query,
+	return execInsert(ctx, q.db, query, nulls,
 		data.Timestamp, data.Analog1, data.Analog2, data.Analog3, data.Analog4,
 		data.Analog5, data.Analog6, data.Analog7, data.Analog8,
 	)
-	return err
 }
 
-func (q *Queries) InsertRearAeroData(ctx context.Context, data types.RearAero_Data) error {
+func (q *Queries) InsertRearAeroData(ctx context.Context, data types.RearAero_Data, nulls ...string) error {
 	query := `
         INSERT INTO rear_aero (
             timestamp, pressure1, pressure2, pressure3, temperature1, temperature2, temperature3
         )
         VALUES ($1, $2, $3, $4, $5, $6, $7)
     `
-	_, err := q.db.ExecContext(ctx, query,
+	return execInsert(ctx, q.db, query, nulls,
 		data.Timestamp, data.Pressure1, data.Pressure2, data.Pressure3,
 		data.Temperature1, data.Temperature2, data.Temperature3,
 	)
-	return err
 }
 
-func (q *Queries) InsertFrontAeroData(ctx context.Context, data types.FrontAero_Data) error {
+func (q *Queries) InsertFrontAeroData(ctx context.Context, data types.FrontAero_Data, nulls ...string) error {
 	query := `
         INSERT INTO front_aero (
             timestamp, pressure1, pressure2, pressure3, temperature1, temperature2, temperature3
         )
         VALUES ($1, $2, $3, $4, $5, $6, $7)
     `
-	_, err := q.db.ExecContext(ctx, query,
+	return execInsert(ctx, q.db, query, nulls,
 		data.Timestamp, data.Pressure1, data.Pressure2, data.Pressure3,
 		data.Temperature1, data.Temperature2, data.Temperature3,
 	)
-	return err
 }
 
-func (q *Queries) InsertPDM1Data(ctx context.Context, data types.PDM1_Data) error {
+func (q *Queries) InsertPDM1Data(ctx context.Context, data types.PDM1_Data, nulls ...string) error {
 	query := `
         INSERT INTO pdm1 (
             timestamp, compound_id, pdm_int_temperature, pdm_batt_voltage,
-            global_error_flag, total_current, internal_rail_voltage, reset_source
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            global_error_flag, total_current, internal_rail_voltage, reset_source, reset_source_label
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''))
     `
-	_, err := q.db.ExecContext(ctx, query,
+	return execInsert(ctx, q.db, query, nulls,
 		data.Timestamp, data.CompoundID, data.PDMIntTemperature, data.PDMBattVoltage,
-		data.GlobalErrorFlag, data.TotalCurrent, data.InternalRailVoltage, data.ResetSource,
+		data.GlobalErrorFlag, data.TotalCurrent, data.InternalRailVoltage, data.ResetSource, data.ResetSourceLabel,
 	)
-	return err
 }
 
-func (q *Queries) InsertRearFrequencyData(ctx context.Context, data types.RearFrequency_Data) error {
+func (q *Queries) InsertRearFrequencyData(ctx context.Context, data types.RearFrequency_Data, nulls ...string) error {
 	query := `
         INSERT INTO rear_frequency (timestamp, freq1, freq2, freq3, freq4)
         VALUES ($1, $2, $3, $4, $5)
     `
-	_, err := q.db.ExecContext(ctx, query, data.Timestamp, data.Freq1, data.Freq2, data.Freq3, data.Freq4)
-	return err
+	return execInsert(ctx, q.db, query, nulls, data.Timestamp, data.Freq1, data.Freq2, data.Freq3, data.Freq4)
 }
 
-func (q *Queries) InsertGPSBestPosData(ctx context.Context, data types.GPSBestPos_Data) error {
+func (q *Queries) InsertGPSBestPosData(ctx context.Context, data types.GPSBestPos_Data, nulls ...string) error {
 	query := `
         INSERT INTO gps_best_pos (
             timestamp, latitude, longitude, altitude, 
-            std_latitude, std_longitude, std_altitude, gps_status
+            std_latitude, std_longitude, std_altitude, gps_status, gps_status_label
         )
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''))
     `
-	_, err := q.db.ExecContext(ctx, query,
+	return execInsert(ctx, q.db, query, nulls,
 		data.Timestamp, data.Latitude, data.Longitude, data.Altitude,
-		data.StdLatitude, data.StdLongitude, data.StdAltitude, data.GPSStatus,
+		data.StdLatitude, data.StdLongitude, data.StdAltitude, data.GPSStatus, data.GPSStatusLabel,
 	)
-	return err
 }
 
 // InsertTCUData inserts a TCU_Data record.
-func (q *Queries) InsertTCUData(ctx context.Context, data types.TCU_Data) error {
+func (q *Queries) InsertTCUData(ctx context.Context, data types.TCU_Data, nulls ...string) error {
 	query := `INSERT INTO tcu1 (timestamp, apps1, apps2, bse, status) VALUES ($1, $2, $3, $4, $5)`
-	_, err := q.db.ExecContext(ctx, query, data.Timestamp, data.APPS1, data.APPS2, data.BSE, data.Status)
-	return err
+	return execInsert(ctx, q.db, query, nulls, data.Timestamp, data.APPS1, data.APPS2, data.BSE, data.Status)
 }
 
-func (q *Queries) InsertThermData(ctx context.Context, data types.Therm_Data) error {
+func (q *Queries) InsertThermData(ctx context.Context, data types.Therm_Data, nulls ...string) error {
 	query := `
         INSERT INTO therm_data (
             timestamp, thermistor_id, therm1, therm2, therm3, therm4, 
@@ -1097,33 +1131,30 @@ func (q *Queries) InsertThermData(ctx context.Context, data types.Therm_Data) er
             therm11, therm12, therm13, therm14, therm15, therm16
         ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
     `
-	_, err := q.db.ExecContext(ctx, query,
+	return execInsert(ctx, q.db, query, nulls,
 		data.Timestamp, data.ThermistorID, data.Therm1, data.Therm2, data.Therm3, data.Therm4,
 		data.Therm5, data.Therm6, data.Therm7, data.Therm8, data.Therm9, data.Therm10,
 		data.Therm11, data.Therm12, data.Therm13, data.Therm14, data.Therm15, data.Therm16,
 	)
-	return err
 }
 
-func (q *Queries) InsertACULV2Data(ctx context.Context, data types.ACULV2_Data) error {
+func (q *Queries) InsertACULV2Data(ctx context.Context, data types.ACULV2_Data, nulls ...string) error {
 	query := `
         INSERT INTO aculv2 (timestamp, charge_request)
         VALUES ($1, $2)
     `
-	_, err := q.db.ExecContext(ctx, query, data.Timestamp, data.ChargeRequest)
-	return err
+	return execInsert(ctx, q.db, query, nulls, data.Timestamp, data.ChargeRequest)
 }
 
-func (q *Queries) InsertACULV_FD_2_Data(ctx context.Context, data types.ACULV_FD_2_Data) error {
+func (q *Queries) InsertACULV_FD_2_Data(ctx context.Context, data types.ACULV_FD_2_Data, nulls ...string) error {
 	query := `
         INSERT INTO aculv_fd_2 (timestamp, fan_set_point, rpm)
         VALUES ($1, $2, $3)
     `
-	_, err := q.db.ExecContext(ctx, query, data.Timestamp, data.FanSetPoint, data.RPM)
-	return err
+	return execInsert(ctx, q.db, query, nulls, data.Timestamp, data.FanSetPoint, data.RPM)
 }
 
-func InsertCellData(ctx context.Context, data types.Cell_Data) error {
+func InsertCellData(ctx context.Context, data types.Cell_Data, nulls ...string) error {
 	query := `
 INSERT INTO cell_data (
     timestamp,
@@ -1180,135 +1211,118 @@ INSERT INTO cell_data (
 		data.Cell113, data.Cell114, data.Cell115, data.Cell116, data.Cell117, data.Cell118, data.Cell119, data.Cell120,
 		data.Cell121, data.Cell122, data.Cell123, data.Cell124, data.Cell125, data.Cell126, data.Cell127, data.Cell128,
 	}
-	_, err := DB.ExecContext(ctx, query, args...)
-	return err
+	return execInsert(ctx, DB, query, nulls, args...)
 }
 
-func (q *Queries) InsertACULV1Data(ctx context.Context, data types.ACULV1_Data) error {
+func (q *Queries) InsertACULV1Data(ctx context.Context, data types.ACULV1_Data, nulls ...string) error {
 	query := `
         INSERT INTO aculv1 (timestamp, charge_status1, charge_status2)
         VALUES ($1, $2, $3)
     `
-	_, err := q.db.ExecContext(ctx, query, data.Timestamp, data.ChargeStatus1, data.ChargeStatus2)
-	return err
+	return execInsert(ctx, q.db, query, nulls, data.Timestamp, data.ChargeStatus1, data.ChargeStatus2)
 }
 
 // InsertACULV_FD_1_Data inserts an ACULV_FD_1_Data record.
-func (q *Queries) InsertACULV_FD_1_Data(ctx context.Context, data types.ACULV_FD_1_Data) error {
+func (q *Queries) InsertACULV_FD_1_Data(ctx context.Context, data types.ACULV_FD_1_Data, nulls ...string) error {
 	query := `
     INSERT INTO aculv_fd_1 (
-        timestamp, ams_status, fld, state_of_charge, accumulator_voltage, 
+        timestamp, ams_status, ams_status_label, fld, state_of_charge, accumulator_voltage, 
         tractive_voltage, cell_current, isolation_monitoring, isolation_monitoring1
-    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := q.db.ExecContext(ctx, query,
-		data.Timestamp, data.AMSStatus, data.FLD, data.StateOfCharge,
+    ) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10)`
+	return execInsert(ctx, q.db, query, nulls,
+		data.Timestamp, data.AMSStatus, data.AMSStatusLabel, data.FLD, data.StateOfCharge,
 		data.AccumulatorVoltage, data.TractiveVoltage, data.CellCurrent,
 		data.IsolationMonitoring, data.IsolationMonitoring1)
-	return err
 }
 
 // InsertPackCurrentData inserts a PackCurrent_Data record.
-func InsertPackCurrentData(ctx context.Context, data types.PackCurrent_Data) error {
+func InsertPackCurrentData(ctx context.Context, data types.PackCurrent_Data, nulls ...string) error {
 	query := `INSERT INTO pack_current (timestamp, current) VALUES ($1, $2)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.Current)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.Current)
 }
 
 // InsertPackVoltageData inserts a PackVoltage_Data record.
-func InsertPackVoltageData(ctx context.Context, data types.PackVoltage_Data) error {
+func InsertPackVoltageData(ctx context.Context, data types.PackVoltage_Data, nulls ...string) error {
 	query := `INSERT INTO pack_voltage (timestamp, voltage) VALUES ($1, $2)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.Voltage)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.Voltage)
 }
 
 // InsertBamocarData inserts a Bamocar_Data record.
-func InsertBamocarData(ctx context.Context, data types.TCU2_data) error {
+func InsertBamocarData(ctx context.Context, data types.TCU2_data, nulls ...string) error {
 	// Insert into tcu2 with correct column names.
 	query := `INSERT INTO tcu2 (timestamp, brake_light, bamocar_rfe, bamocar_frg) VALUES ($1, $2, $3, $4)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.BrakeLight, data.BamocarRFE, data.BamocarFRG)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.BrakeLight, data.BamocarRFE, data.BamocarFRG)
 }
 
 // InsertBamocarTxData inserts a BamocarTxData_Data record.
-func InsertBamocarTxData(ctx context.Context, data types.BamocarTxData_Data) error {
+func InsertBamocarTxData(ctx context.Context, data types.BamocarTxData_Data, nulls ...string) error {
 	query := `INSERT INTO bamocar_tx_data (timestamp, regid, data) VALUES ($1, $2, $3)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.REGID, data.Data)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.REGID, data.Data)
 }
 
 // InsertBamoCarReTransmitData inserts a BamoCarReTransmit_Data record.
-func InsertBamoCarReTransmitData(ctx context.Context, data types.BamoCarReTransmit_Data) error {
+func InsertBamoCarReTransmitData(ctx context.Context, data types.BamoCarReTransmit_Data, nulls ...string) error {
 	query := `INSERT INTO bamo_car_re_transmit (timestamp, motor_temp, controller_temp) VALUES ($1, $2, $3)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.MotorTemp, data.ControllerTemp)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.MotorTemp, data.ControllerTemp)
 }
 
 // InsertINS_GPS_Data inserts an INS_GPS_Data record.
-func InsertINS_GPS_Data(ctx context.Context, data types.INS_GPS_Data) error {
+func InsertINS_GPS_Data(ctx context.Context, data types.INS_GPS_Data, nulls ...string) error {
 	query := `INSERT INTO ins_gps (timestamp, gnss_week, gnss_seconds, gnss_lat, gnss_long, gnss_height)
 VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.GNSSWeek, data.GNSSSeconds, data.GNSSLat, data.GNSSLong, data.GNSSHeight)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.GNSSWeek, data.GNSSSeconds, data.GNSSLat, data.GNSSLong, data.GNSSHeight)
 }
 
-func InsertEncoderData(ctx context.Context, data types.Encoder_Data) error {
+func InsertEncoderData(ctx context.Context, data types.Encoder_Data, nulls ...string) error {
 	query := `INSERT INTO encoder_data (timestamp, encoder1, encoder2, encoder3, encoder4) VALUES ($1, $2, $3, $4, $5)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.Encoder1, data.Encoder2, data.Encoder3, data.Encoder4)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.Encoder1, data.Encoder2, data.Encoder3, data.Encoder4)
 }
 
 // InsertINS_IMUData inserts an INS_IMU_Data record.
-func InsertINS_IMUData(ctx context.Context, data types.INS_IMU_Data) error {
+func InsertINS_IMUData(ctx context.Context, data types.INS_IMU_Data, nulls ...string) error {
 	query := `INSERT INTO ins_imu (timestamp, north_vel, east_vel, up_vel, roll, pitch, azimuth, status)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.NorthVel, data.EastVel, data.UpVel, data.Roll, data.Pitch, data.Azimuth, data.Status)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.NorthVel, data.EastVel, data.UpVel, data.Roll, data.Pitch, data.Azimuth, data.Status)
 }
 
 // InsertFrontFrequencyData inserts a FrontFrequency_Data record.
-func InsertFrontFrequencyData(ctx context.Context, data types.FrontFrequency_Data) error {
+func InsertFrontFrequencyData(ctx context.Context, data types.FrontFrequency_Data, nulls ...string) error {
 	query := `INSERT INTO front_frequency (timestamp, rear_right, front_right, rear_left, front_left)
 VALUES ($1, $2, $3, $4, $5)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.RearRight, data.FrontRight, data.RearLeft, data.FrontLeft)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.RearRight, data.FrontRight, data.RearLeft, data.FrontLeft)
 }
 
 // InsertFrontAnalogData inserts a FrontAnalog_Data record.
-func InsertFrontAnalogData(ctx context.Context, data types.FrontAnalog_Data) error {
+func InsertFrontAnalogData(ctx context.Context, data types.FrontAnalog_Data, nulls ...string) error {
 	query := `INSERT INTO front_analog (timestamp, left_rad, right_rad, front_right_pot, front_left_pot, rear_right_pot, rear_left_pot, steering_angle, analog8)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.LeftRad, data.RightRad, data.FrontRightPot, data.FrontLeftPot, data.RearRightPot, data.RearLeftPot, data.SteeringAngle, data.Analog8)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.LeftRad, data.RightRad, data.FrontRightPot, data.FrontLeftPot, data.RearRightPot, data.RearLeftPot, data.SteeringAngle, data.Analog8)
 }
 
 // InsertFrontStrainGauges1Data inserts a FrontStrainGauges1_Data record.
-func InsertFrontStrainGauges1Data(ctx context.Context, data types.FrontStrainGauges1_Data) error {
+func InsertFrontStrainGauges1Data(ctx context.Context, data types.FrontStrainGauges1_Data, nulls ...string) error {
 	query := `INSERT INTO front_strain_gauges_1 (timestamp, gauge1, gauge2, gauge3, gauge4, gauge5, gauge6)
 VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.Gauge1, data.Gauge2, data.Gauge3, data.Gauge4, data.Gauge5, data.Gauge6)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.Gauge1, data.Gauge2, data.Gauge3, data.Gauge4, data.Gauge5, data.Gauge6)
 }
 
 // InsertFrontStrainGauges2Data inserts a FrontStrainGauges2_Data record.
-func InsertFrontStrainGauges2Data(ctx context.Context, data types.FrontStrainGauges2_Data) error {
+func InsertFrontStrainGauges2Data(ctx context.Context, data types.FrontStrainGauges2_Data, nulls ...string) error {
 	query := `INSERT INTO front_strain_gauges_2 (timestamp, gauge1, gauge2, gauge3, gauge4, gauge5, gauge6)
 VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.Gauge1, data.Gauge2, data.Gauge3, data.Gauge4, data.Gauge5, data.Gauge6)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.Gauge1, data.Gauge2, data.Gauge3, data.Gauge4, data.Gauge5, data.Gauge6)
 }
 
 // InsertPDMCurrentData inserts a PDMCurrent_Data record.
-func InsertPDMCurrentData(ctx context.Context, data types.PDMCurrent_Data) error {
+func InsertPDMCurrentData(ctx context.Context, data types.PDMCurrent_Data, nulls ...string) error {
 	query := `INSERT INTO pdm_current (timestamp, accumulator_current, tcu_current, bamocar_current, pumps_current, tsal_current, daq_current, display_kvaser_current, shutdown_reset_current)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.AccumulatorCurrent, data.TCUCurrent, data.BamocarCurrent, data.PumpsCurrent, data.TSALCurrent, data.DAQCurrent, data.DisplayKvaserCurrent, data.ShutdownResetCurrent)
-	return err
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.AccumulatorCurrent, data.TCUCurrent, data.BamocarCurrent, data.PumpsCurrent, data.TSALCurrent, data.DAQCurrent, data.DisplayKvaserCurrent, data.ShutdownResetCurrent)
 }
 
 // InsertPDMReTransmitData inserts a PDMReTransmit_Data record.
-func InsertPDMReTransmitData(ctx context.Context, data types.PDMReTransmit_Data) error {
-	query := `INSERT INTO pdm_re_transmit (timestamp, pdm_int_temperature, pdm_batt_voltage, global_error_flag, total_current, internal_rail_voltage, reset_source)
-VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	_, err := DB.ExecContext(ctx, query, data.Timestamp, data.PDMIntTemperature, data.PDMBattVoltage, data.GlobalErrorFlag, data.TotalCurrent, data.InternalRailVoltage, data.ResetSource)
-	return err
+func InsertPDMReTransmitData(ctx context.Context, data types.PDMReTransmit_Data, nulls ...string) error {
+	query := `INSERT INTO pdm_re_transmit (timestamp, pdm_int_temperature, pdm_batt_voltage, global_error_flag, total_current, internal_rail_voltage, reset_source, reset_source_label)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))`
+	return execInsert(ctx, DB, query, nulls, data.Timestamp, data.PDMIntTemperature, data.PDMBattVoltage, data.GlobalErrorFlag, data.TotalCurrent, data.InternalRailVoltage, data.ResetSource, data.ResetSourceLabel)
 }
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNullArgs(t *testing.T) {
+	query := `
+        INSERT INTO aculv_fd_1 (
+            timestamp, ams_status, ams_status_label, fld, state_of_charge
+        ) VALUES ($1, $2, NULLIF($3, ''), $4, $5)`
+	args := []interface{}{"ts", 1, "Ready", 2, 3.5}
+	got := nullArgs(query, []string{"StateOfCharge", "AMSStatus", "NotStored"}, args)
+	want := []interface{}{"ts", nil, "Ready", 2, nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nullArgs = %v, want %v", got, want)
+	}
+	if args[1] != 1 {
+		t.Error("nullArgs modified its argument")
+	}
+	if got := nullArgs(query, nil, args); !reflect.DeepEqual(got, args) {
+		t.Errorf("nullArgs without nulls = %v", got)
+	}
+}
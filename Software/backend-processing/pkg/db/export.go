@@ -0,0 +1,231 @@
+// export.go
+//
+// Generic time-range reads of the per-message hypertables for file exports.
+// MessageTables maps every CAN message stored by processdata to its table;
+// FetchMessageRange and FetchMessageResampled return a table's numeric
+// columns without per-table code.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// MessageTable is the hypertable that stores the decoded signals of a CAN
+// message. Tables shared by several messages select one with Key = KeyValue.
+type MessageTable struct {
+	ID       types.CANID
+	Name     string // group name for tables that combine several messages
+	Table    string
+	Key      string
+	KeyValue int
+
+	// Sources lists further messages whose signals the table stores.
+	Sources []types.CANID
+	// Columns names the signal of each column whose name is not the signal
+	// name in snake case.
+	Columns map[string]string
+}
+
+// MessageTables lists the stored messages in CAN ID order. The cell voltage
+// messages 50-57 are stored as one row per pack scan and exported as one.
+var MessageTables = []MessageTable{
+	{ID: 4, Table: "pack_current", Columns: map[string]string{"current": "PackCurrent"}},
+	{ID: 5, Table: "pack_voltage", Columns: map[string]string{"voltage": "PackVoltage"}},
+	{ID: 6, Table: "tcu1"},
+	{ID: 8, Table: "aculv_fd_1"},
+	{ID: 30, Table: "aculv_fd_2"},
+	{ID: 40, Table: "aculv1"},
+	{ID: 41, Table: "aculv2"},
+	{ID: 50, Name: "CellVoltages", Table: "cell_data", Sources: []types.CANID{51, 52, 53, 54, 55, 56, 57}},
+	{ID: 60, Table: "therm_data", Key: "thermistor_id", KeyValue: 1},
+	{ID: 61, Table: "therm_data", Key: "thermistor_id", KeyValue: 2},
+	{ID: 62, Table: "therm_data", Key: "thermistor_id", KeyValue: 3},
+	{ID: 63, Table: "therm_data", Key: "thermistor_id", KeyValue: 4},
+	{ID: 64, Table: "therm_data", Key: "thermistor_id", KeyValue: 5},
+	{ID: 65, Table: "therm_data", Key: "thermistor_id", KeyValue: 6},
+	{ID: 66, Table: "therm_data", Key: "thermistor_id", KeyValue: 7},
+	{ID: 67, Table: "therm_data", Key: "thermistor_id", KeyValue: 8},
+	{ID: 68, Table: "therm_data", Key: "thermistor_id", KeyValue: 9},
+	{ID: 69, Table: "therm_data", Key: "thermistor_id", KeyValue: 10},
+	{ID: 70, Table: "therm_data", Key: "thermistor_id", KeyValue: 11},
+	{ID: 71, Table: "therm_data", Key: "thermistor_id", KeyValue: 12},
+	{ID: 80, Table: "gps_best_pos"},
+	{ID: 81, Table: "ins_gps"},
+	{ID: 82, Table: "ins_imu"},
+	{ID: 100, Table: "tcu2"},
+	{ID: 101, Table: "front_frequency"},
+	{ID: 102, Table: "rear_frequency"},
+	{ID: 200, Table: "encoder_data"},
+	{ID: 258, Table: "rear_analog"},
+	{ID: 259, Table: "front_analog"},
+	{ID: 385, Table: "bamocar_tx_data"},
+	{ID: 513, Table: "bamocar_rx_data"},
+	{ID: 600, Table: "bamo_car_re_transmit"},
+	{ID: 1280, Table: "pdm1"},
+	{ID: 1312, Table: "pdm_current"},
+	{ID: 1536, Table: "front_aero"},
+	{ID: 1537, Table: "rear_aero"},
+	{ID: 1552, Table: "front_strain_gauges_1"},
+	{ID: 1553, Table: "front_strain_gauges_2"},
+	{ID: 1554, Table: "rear_strain_gauges_1"},
+	{ID: 1555, Table: "rear_strain_gauges_2"},
+	{ID: 1680, Table: "pdm_re_transmit"},
+}
+
+// FetchMessageRange returns the rows of a message table with from <= timestamp
+// < to, oldest first. Text columns (choice labels) and the key column are left
+// out; NULLs are returned as NaN.
+func (q *Queries) FetchMessageRange(ctx context.Context, t MessageTable, from, to time.Time) (types.MessageRows, error) {
+	var res types.MessageRows
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE timestamp >= $1 AND timestamp < $2`, t.Table)
+	args := []any{from, to}
+	if t.Key != "" {
+		query += fmt.Sprintf(` AND %s = $3`, t.Key)
+		args = append(args, t.KeyValue)
+	}
+	rows, err := q.db.QueryContext(ctx, query+` ORDER BY timestamp ASC`, args...)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return res, err
+	}
+	timeCol, valueCols := -1, []int(nil)
+	for i, c := range colTypes {
+		switch {
+		case c.Name() == "timestamp":
+			timeCol = i
+		case isValueColumn(t, c):
+			valueCols = append(valueCols, i)
+			res.Columns = append(res.Columns, c.Name())
+		}
+	}
+	if timeCol < 0 {
+		return res, fmt.Errorf("table %s has no timestamp column", t.Table)
+	}
+
+	dest := make([]any, len(colTypes))
+	vals := make([]any, len(colTypes))
+	for i := range dest {
+		dest[i] = &vals[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return res, err
+		}
+		ts, ok := vals[timeCol].(time.Time)
+		if !ok {
+			continue
+		}
+		row := make([]float64, len(valueCols))
+		for j, i := range valueCols {
+			row[j] = numericValue(vals[i])
+		}
+		res.Times = append(res.Times, ts)
+		res.Values = append(res.Values, row)
+	}
+	return res, rows.Err()
+}
+
+// FetchMessageResampled returns a message table resampled on a regular grid
+// of interval from from to to, with TimescaleDB gap filling: each value is the
+// average of the bucket, or the last value before it if the bucket is empty.
+// Buckets before the first stored row hold NaN.
+func (q *Queries) FetchMessageResampled(ctx context.Context, t MessageTable, from, to time.Time, interval time.Duration) (types.MessageRows, error) {
+	var res types.MessageRows
+	probe, err := q.db.QueryContext(ctx, fmt.Sprintf(`SELECT * FROM %s LIMIT 0`, t.Table))
+	if err != nil {
+		return res, err
+	}
+	colTypes, err := probe.ColumnTypes()
+	probe.Close()
+	if err != nil {
+		return res, err
+	}
+	var selects []string
+	for _, c := range colTypes {
+		if c.Name() != "timestamp" && isValueColumn(t, c) {
+			res.Columns = append(res.Columns, c.Name())
+			selects = append(selects, fmt.Sprintf("locf(avg(%s)::double precision)", c.Name()))
+		}
+	}
+	if len(selects) == 0 {
+		return res, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT time_bucket_gapfill($1::interval, timestamp, $2, $3) AS bucket, %s
+		FROM %s
+		WHERE timestamp >= $2 AND timestamp < $3`, strings.Join(selects, ", "), t.Table)
+	args := []any{fmt.Sprintf("%d microseconds", interval.Microseconds()), from, to}
+	if t.Key != "" {
+		query += fmt.Sprintf(` AND %s = $4`, t.Key)
+		args = append(args, t.KeyValue)
+	}
+	rows, err := q.db.QueryContext(ctx, query+` GROUP BY bucket ORDER BY bucket`, args...)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+
+	dest := make([]any, 1+len(selects))
+	vals := make([]sql.NullFloat64, len(selects))
+	var bucket time.Time
+	dest[0] = &bucket
+	for i := range vals {
+		dest[i+1] = &vals[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return res, err
+		}
+		row := make([]float64, len(vals))
+		for i, v := range vals {
+			row[i] = math.NaN()
+			if v.Valid {
+				row[i] = v.Float64
+			}
+		}
+		res.Times = append(res.Times, bucket)
+		res.Values = append(res.Values, row)
+	}
+	return res, rows.Err()
+}
+
+// isValueColumn reports whether a column of t holds a signal value: any
+// numeric column except the key. Text columns hold choice labels.
+func isValueColumn(t MessageTable, c *sql.ColumnType) bool {
+	if c.Name() == t.Key {
+		return false
+	}
+	switch strings.ToUpper(c.DatabaseTypeName()) {
+	case "INT2", "INT4", "INT8", "FLOAT4", "FLOAT8":
+		return true
+	}
+	return false
+}
+
+// numericValue converts a scanned column value to float64.
+func numericValue(v any) float64 {
+	switch x := v.(type) {
+	case int64:
+		return float64(x)
+	case int32:
+		return float64(x)
+	case float64:
+		return x
+	case float32:
+		return float64(x)
+	}
+	return math.NaN()
+}
@@ -0,0 +1,178 @@
+// versions.go
+//
+// Storage for CAN definition versions and telemetry sessions. Every
+// definition set activated for a vehicle is stored with its content hash and
+// effective date range; every session records the hash it was decoded with.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"telem-system/pkg/types"
+)
+
+// ActivateDefinitionVersion makes v the vehicle's current definition set. If
+// the open version already has the same hash it is returned unchanged;
+// otherwise the open version is closed at v.EffectiveFrom and v is stored.
+func ActivateDefinitionVersion(ctx context.Context, v types.DefinitionVersion) (types.DefinitionVersion, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return v, err
+	}
+	defer tx.Rollback()
+
+	var openID int64
+	var openHash string
+	var openFrom time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, hash, effective_from
+		FROM definition_versions
+		WHERE vehicle = $1 AND effective_to IS NULL
+		ORDER BY effective_from DESC
+		LIMIT 1
+		FOR UPDATE
+	`, v.Vehicle).Scan(&openID, &openHash, &openFrom)
+	switch {
+	case err == nil && openHash == v.Hash:
+		v.ID, v.EffectiveFrom = openID, openFrom
+		return v, tx.Commit()
+	case err == nil:
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE definition_versions SET effective_to = $1 WHERE id = $2`,
+			v.EffectiveFrom, openID); err != nil {
+			return v, err
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return v, err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO definition_versions (vehicle, hash, format, content, effective_from)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, v.Vehicle, v.Hash, v.Format, v.Content, v.EffectiveFrom).Scan(&v.ID)
+	if err != nil {
+		return v, err
+	}
+	v.EffectiveTo = nil
+	return v, tx.Commit()
+}
+
+// FetchDefinitionVersions returns every stored definition version of a
+// vehicle, including content, oldest first.
+func (q *Queries) FetchDefinitionVersions(ctx context.Context, vehicle string) ([]types.DefinitionVersion, error) {
+	query := `
+		SELECT id, vehicle, hash, format, content, effective_from, effective_to
+		FROM definition_versions
+		WHERE vehicle = $1
+		ORDER BY effective_from ASC
+	`
+	rows, err := q.db.QueryContext(ctx, query, vehicle)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var data []types.DefinitionVersion
+	for rows.Next() {
+		var rec types.DefinitionVersion
+		var to sql.NullTime
+		if err := rows.Scan(&rec.ID, &rec.Vehicle, &rec.Hash, &rec.Format, &rec.Content, &rec.EffectiveFrom, &to); err != nil {
+			return nil, err
+		}
+		if to.Valid {
+			rec.EffectiveTo = &to.Time
+		}
+		data = append(data, rec)
+	}
+	return data, rows.Err()
+}
+
+// StartSession records the start of a telemetry session and returns its ID.
+func StartSession(ctx context.Context, vehicle, source, definitionsHash string) (int64, error) {
+	var id int64
+	err := DB.QueryRowContext(ctx, `
+		INSERT INTO sessions (vehicle, source, definitions_hash)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, vehicle, source, definitionsHash).Scan(&id)
+	return id, err
+}
+
+// SetSessionDefinitions records the definitions a session was decoded with,
+// for sessions that switch to a stored version when replaying an older log.
+func SetSessionDefinitions(ctx context.Context, id int64, definitionsHash string) error {
+	_, err := DB.ExecContext(ctx, `UPDATE sessions SET definitions_hash = $1 WHERE id = $2`, definitionsHash, id)
+	return err
+}
+
+// EndSession records the end of a telemetry session.
+func EndSession(ctx context.Context, id int64) error {
+	_, err := DB.ExecContext(ctx, `UPDATE sessions SET ended_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// UpdateSessionMetadata sets the driver, venue, event and comment of a
+// session. It returns sql.ErrNoRows if the session does not exist.
+func (q *Queries) UpdateSessionMetadata(ctx context.Context, id int64, m types.SessionMetadata) error {
+	res, err := q.db.ExecContext(ctx, `
+		UPDATE sessions SET driver = $1, venue = $2, event = $3, comment = $4
+		WHERE id = $5
+	`, m.Driver, m.Venue, m.Event, m.Comment, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// FetchSession returns one session.
+func (q *Queries) FetchSession(ctx context.Context, id int64) (types.Session, error) {
+	var rec types.Session
+	var ended sql.NullTime
+	err := q.db.QueryRowContext(ctx, `
+		SELECT id, vehicle, source, definitions_hash, started_at, ended_at, driver, venue, event, comment
+		FROM sessions
+		WHERE id = $1
+	`, id).Scan(&rec.ID, &rec.Vehicle, &rec.Source, &rec.DefinitionsHash, &rec.StartedAt, &ended,
+		&rec.Driver, &rec.Venue, &rec.Event, &rec.Comment)
+	if ended.Valid {
+		rec.EndedAt = &ended.Time
+	}
+	return rec, err
+}
+
+// FetchSessionsPaginated returns sessions with pagination, newest first.
+func (q *Queries) FetchSessionsPaginated(ctx context.Context, limit, offset int) ([]types.Session, error) {
+	query := `
+		SELECT id, vehicle, source, definitions_hash, started_at, ended_at, driver, venue, event, comment
+		FROM sessions
+		ORDER BY started_at DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := q.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var data []types.Session
+	for rows.Next() {
+		var rec types.Session
+		var ended sql.NullTime
+		if err := rows.Scan(&rec.ID, &rec.Vehicle, &rec.Source, &rec.DefinitionsHash, &rec.StartedAt, &ended,
+			&rec.Driver, &rec.Venue, &rec.Event, &rec.Comment); err != nil {
+			return nil, err
+		}
+		if ended.Valid {
+			rec.EndedAt = &ended.Time
+		}
+		data = append(data, rec)
+	}
+	return data, rows.Err()
+}
@@ -0,0 +1,189 @@
+// export.go
+//
+// Package export writes stored telemetry to measurement file formats used by
+// analysis tools. Load reads a time range of every message table and labels
+// the columns with the signals of the CAN definitions in effect, so each
+// writer gets one group per CAN message with names, units and choices.
+package export
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/db"
+	"telem-system/pkg/types"
+)
+
+// Channel is one exported signal.
+type Channel struct {
+	Name    string
+	Unit    string
+	Choices map[float64]string // physical value to label
+}
+
+// Group is the exported data of one CAN message.
+type Group struct {
+	Name     string
+	ID       types.CANID
+	Channels []Channel
+	Times    []time.Time
+	Values   [][]float64 // Values[i][j] is channel j at Times[i]
+}
+
+// Selection is the data of one export: a time range and the definitions that
+// name its signals.
+type Selection struct {
+	From, To time.Time
+	Defs     *candecoder.Definitions
+	Session  *types.Session // nil for a plain time range
+}
+
+// SelectSession selects the time range of a session, decoded with the
+// definitions it was recorded with. Sessions still running end now.
+func SelectSession(ctx context.Context, q *db.Queries, versions *candecoder.Versions, id int64, current *candecoder.Definitions) (Selection, error) {
+	s, err := q.FetchSession(ctx, id)
+	if err != nil {
+		return Selection{}, fmt.Errorf("session %d: %v", id, err)
+	}
+	sel := Selection{From: s.StartedAt, To: time.Now(), Defs: current, Session: &s}
+	if s.EndedAt != nil {
+		sel.To = *s.EndedAt
+	}
+	if s.DefinitionsHash != current.Hash {
+		if defs, err := versions.ByHash(s.DefinitionsHash); err == nil {
+			sel.Defs = defs
+		}
+	}
+	return sel, nil
+}
+
+// SelectRange selects a time range, decoded with the vehicle's definitions in
+// effect at its start.
+func SelectRange(versions *candecoder.Versions, vehicle string, from, to time.Time, current *candecoder.Definitions) (Selection, error) {
+	if !to.After(from) {
+		return Selection{}, fmt.Errorf("empty time range %s to %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	return Selection{From: from, To: to, Defs: versions.Resolve(vehicle, from, current)}, nil
+}
+
+// FileName returns a download name for the selection with the given extension.
+func (s Selection) FileName(ext string) string {
+	if s.Session != nil {
+		return fmt.Sprintf("session-%d%s", s.Session.ID, ext)
+	}
+	return "telemetry-" + s.From.UTC().Format("20060102T150405Z") + ext
+}
+
+// LDInfo returns the MoTeC header of the selection, filled in from the
+// session metadata.
+func (s Selection) LDInfo(vehicle string) LDInfo {
+	info := LDInfo{Start: s.From, Vehicle: vehicle}
+	if s.Session != nil {
+		m := s.Session.SessionMetadata
+		info.Vehicle = s.Session.Vehicle
+		info.Driver, info.Venue, info.Event, info.Comment = m.Driver, m.Venue, m.Event, m.Comment
+		info.Session = fmt.Sprintf("Session %d", s.Session.ID)
+	}
+	return info
+}
+
+// Load reads every stored message in the selected range. Messages without
+// rows in the range are left out.
+func Load(ctx context.Context, q *db.Queries, sel Selection) ([]Group, error) {
+	return load(sel.Defs, func(t db.MessageTable) (types.MessageRows, error) {
+		return q.FetchMessageRange(ctx, t, sel.From, sel.To)
+	})
+}
+
+// LoadResampled is Load with every message resampled at rate Hz by the
+// database, so all groups share the same time grid from sel.From.
+func LoadResampled(ctx context.Context, q *db.Queries, sel Selection, rate int) ([]Group, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate %d", rate)
+	}
+	interval := time.Second / time.Duration(rate)
+	return load(sel.Defs, func(t db.MessageTable) (types.MessageRows, error) {
+		return q.FetchMessageResampled(ctx, t, sel.From, sel.To, interval)
+	})
+}
+
+// load fetches every message table and names the columns after the signals
+// of defs.
+func load(defs *candecoder.Definitions, fetch func(db.MessageTable) (types.MessageRows, error)) ([]Group, error) {
+	var groups []Group
+	for _, t := range db.MessageTables {
+		rows, err := fetch(t)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %v", t.Table, err)
+		}
+		if len(rows.Times) == 0 {
+			continue
+		}
+		msg := defs.ByID[t.ID]
+		signals := tableSignals(defs, t)
+
+		g := Group{Name: t.Name, ID: t.ID, Times: rows.Times, Values: rows.Values}
+		if g.Name == "" {
+			g.Name = msg.Name
+		}
+		if g.Name == "" {
+			g.Name = t.Table
+		}
+		for _, col := range rows.Columns {
+			key := columnKey(col)
+			if name, ok := t.Columns[col]; ok {
+				key = columnKey(name)
+			}
+			s, ok := signals[key]
+			if !ok {
+				g.Channels = append(g.Channels, Channel{Name: col})
+				continue
+			}
+			g.Channels = append(g.Channels, signalChannel(s))
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// tableSignals indexes the signals of the messages stored in t by columnKey.
+func tableSignals(defs *candecoder.Definitions, t db.MessageTable) map[string]types.Signal {
+	signals := make(map[string]types.Signal)
+	for _, id := range append([]types.CANID{t.ID}, t.Sources...) {
+		for _, s := range defs.ByID[id].Signals {
+			if _, dup := signals[columnKey(s.Name)]; !dup {
+				signals[columnKey(s.Name)] = s
+			}
+		}
+	}
+	return signals
+}
+
+// columnKey normalises a signal or column name for matching.
+func columnKey(name string) string {
+	return db.ColumnKey(name)
+}
+
+// signalChannel describes a signal as a channel. Choices are keyed by raw
+// value in the definitions and by physical value in the export.
+func signalChannel(s types.Signal) Channel {
+	c := Channel{Name: s.Name, Unit: s.Unit}
+	factor := s.Factor
+	if factor == 0 {
+		factor = 1
+	}
+	for k, label := range s.Choices {
+		raw, err := strconv.ParseFloat(k, 64)
+		if err != nil {
+			continue
+		}
+		if c.Choices == nil {
+			c.Choices = make(map[float64]string, len(s.Choices))
+		}
+		c.Choices[raw*factor+s.Offset] = label
+	}
+	return c
+}
@@ -0,0 +1,238 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/db"
+	"telem-system/pkg/types"
+)
+
+// mdfBlock reads the block at off of an MDF file.
+func mdfBlock(t *testing.T, file []byte, off uint64) (id string, links []uint64, data []byte) {
+	t.Helper()
+	if off == 0 || off+24 > uint64(len(file)) || off%8 != 0 {
+		t.Fatalf("bad block offset %d", off)
+	}
+	le := binary.LittleEndian
+	length := le.Uint64(file[off+8:])
+	count := le.Uint64(file[off+16:])
+	for i := uint64(0); i < count; i++ {
+		links = append(links, le.Uint64(file[off+24+8*i:]))
+	}
+	return string(file[off+2 : off+4]), links, file[off+24+8*count : off+length]
+}
+
+// mdfText reads the string of the TX block at off.
+func mdfText(t *testing.T, file []byte, off uint64) string {
+	_, _, data := mdfBlock(t, file, off)
+	return string(bytes.TrimRight(data, "\x00"))
+}
+
+func TestWriteMDF4(t *testing.T) {
+	start := time.Date(2024, 11, 16, 12, 0, 0, 0, time.UTC)
+	status := signalChannel(types.Signal{Name: "AMSStatus", Factor: 1, Choices: map[string]string{"0": "OK", "1": "Fault"}})
+	groups := []Group{
+		{Name: "TCU1", ID: 6,
+			Channels: []Channel{{Name: "APPS1", Unit: "%"}, {Name: "BSE"}},
+			Times:    []time.Time{start.Add(10 * time.Millisecond), start.Add(20 * time.Millisecond)},
+			Values:   [][]float64{{12.5, 1}, {13, math.NaN()}}},
+		{Name: "Empty", ID: 7},
+		{Name: "ACULV_FD_1", ID: 8, Channels: []Channel{status},
+			Times:  []time.Time{start.Add(time.Second)},
+			Values: [][]float64{{1}}},
+	}
+	var out bytes.Buffer
+	if err := WriteMDF4(&out, start, groups); err != nil {
+		t.Fatalf("WriteMDF4: %v", err)
+	}
+	file := out.Bytes()
+	le := binary.LittleEndian
+	if string(file[:8]) != "MDF     " || le.Uint16(file[28:]) != 410 {
+		t.Fatalf("identification block %q", file[:32])
+	}
+	id, hdLinks, hdData := mdfBlock(t, file, 64)
+	if id != "HD" || le.Uint64(hdData) != uint64(start.UnixNano()) {
+		t.Fatalf("header block %s", id)
+	}
+
+	var names []string
+	for dg := hdLinks[0]; dg != 0; {
+		_, dgLinks, _ := mdfBlock(t, file, dg)
+		_, cgLinks, cgData := mdfBlock(t, file, dgLinks[1])
+		names = append(names, mdfText(t, file, cgLinks[2]))
+		cycles, size := le.Uint64(cgData[8:]), le.Uint32(cgData[24:])
+
+		var channels []string
+		for cn := cgLinks[1]; cn != 0; {
+			_, cnLinks, _ := mdfBlock(t, file, cn)
+			channels = append(channels, mdfText(t, file, cnLinks[2]))
+			if cnLinks[4] != 0 {
+				_, ccLinks, ccData := mdfBlock(t, file, cnLinks[4])
+				if ccData[0] != mdfCCValueToText || mdfText(t, file, ccLinks[mdfCCLinks+1]) != "Fault" {
+					t.Errorf("conversion of %s", channels[len(channels)-1])
+				}
+			}
+			cn = cnLinks[0]
+		}
+		_, _, records := mdfBlock(t, file, dgLinks[2])
+		if uint64(len(records)) != cycles*uint64(size) || int(size) != 8*len(channels) {
+			t.Errorf("group %s: %d bytes for %d records of %d", names[len(names)-1], len(records), cycles, size)
+		}
+		if names[len(names)-1] == "TCU1" {
+			if len(channels) != 3 || channels[0] != "t" || channels[1] != "APPS1" {
+				t.Errorf("TCU1 channels %v", channels)
+			}
+			tm := math.Float64frombits(le.Uint64(records[24:]))
+			apps := math.Float64frombits(le.Uint64(records[32:]))
+			bse := math.Float64frombits(le.Uint64(records[40:]))
+			if math.Abs(tm-0.02) > 1e-9 || apps != 13 || !math.IsNaN(bse) {
+				t.Errorf("second TCU1 record = %v %v %v", tm, apps, bse)
+			}
+		}
+		dg = dgLinks[0]
+	}
+	if len(names) != 2 || names[0] != "TCU1" || names[1] != "ACULV_FD_1" {
+		t.Errorf("groups %v", names)
+	}
+}
+
+func TestColumnKeyMatchesSignals(t *testing.T) {
+	for sig, col := range map[string]string{
+		"StateOfCharge":     "state_of_charge",
+		"stdLatitude":       "std_latitude",
+		"Analog8":           "analog8",
+		"PDMIntTemperature": "pdm_int_temperature",
+	} {
+		if columnKey(sig) != columnKey(col) {
+			t.Errorf("%s does not match column %s", sig, col)
+		}
+	}
+	c := signalChannel(types.Signal{Name: "Gear", Factor: 2, Offset: 1, Choices: map[string]string{"3": "Third"}})
+	if c.Choices[7] != "Third" {
+		t.Errorf("choices = %v", c.Choices)
+	}
+}
+
+func TestWriteLD(t *testing.T) {
+	start := time.Date(2024, 11, 16, 12, 0, 0, 0, time.Local)
+	groups := []Group{
+		{Name: "FrontStrainGauges1", Channels: []Channel{{Name: "Gauge1", Unit: "ue"}, {Name: "Unused"}},
+			Times:  []time.Time{start.Add(15 * time.Millisecond), start.Add(40 * time.Millisecond)},
+			Values: [][]float64{{5, math.NaN()}, {7, math.NaN()}}},
+		{Name: "RearStrainGauges1", Channels: []Channel{{Name: "Gauge1"}},
+			Times:  []time.Time{start},
+			Values: [][]float64{{-1}}},
+	}
+	info := LDInfo{Start: start, Driver: "A. Driver", Vehicle: "UCR-01", Venue: "Michigan", Event: "Endurance", Session: "Session 12"}
+	var out bytes.Buffer
+	if err := WriteLD(&out, info, start.Add(50*time.Millisecond), 100, groups); err != nil {
+		t.Fatalf("WriteLD: %v", err)
+	}
+	file := out.Bytes()
+	le := binary.LittleEndian
+	str := func(b []byte) string { return string(bytes.TrimRight(b, "\x00")) }
+
+	if le.Uint32(file) != 0x40 || le.Uint32(file[86:]) != 2 {
+		t.Fatalf("header marker %x, %d channels", le.Uint32(file), le.Uint32(file[86:]))
+	}
+	if str(file[94:110]) != "16/11/2024" || str(file[126:142]) != "12:00:00" || str(file[158:222]) != "A. Driver" || str(file[350:414]) != "Michigan" {
+		t.Errorf("header date %q time %q driver %q venue %q", file[94:110], file[126:142], file[158:222], file[350:414])
+	}
+	event := le.Uint32(file[36:])
+	if str(file[event:event+64]) != "Endurance" || str(file[event+64:event+128]) != "Session 12" {
+		t.Errorf("event block %q", file[event:event+128])
+	}
+	venue := uint32(le.Uint16(file[event+1152:]))
+	vehicle := uint32(le.Uint16(file[venue+1098:]))
+	if str(file[venue:venue+64]) != "Michigan" || str(file[vehicle:vehicle+64]) != "UCR-01" {
+		t.Errorf("venue %q vehicle %q", file[venue:venue+64], file[vehicle:vehicle+64])
+	}
+
+	want := map[string][]float32{
+		"Gauge1":                   {5, 5, 5, 5, 7},
+		"RearStrainGauges1.Gauge1": {-1, -1, -1, -1, -1},
+	}
+	meta := le.Uint32(file[8:])
+	for meta != 0 {
+		m := file[meta : meta+124]
+		name := str(m[32:64])
+		data, n := le.Uint32(m[8:]), le.Uint32(m[12:])
+		if le.Uint16(m[22:]) != 100 || n != 5 {
+			t.Errorf("%s: %d samples at %d Hz", name, n, le.Uint16(m[22:]))
+		}
+		var got []float32
+		for i := uint32(0); i < n; i++ {
+			got = append(got, math.Float32frombits(le.Uint32(file[data+4*i:])))
+		}
+		if w, ok := want[name]; !ok || fmt.Sprint(got) != fmt.Sprint(w) {
+			t.Errorf("channel %s = %v", name, got)
+		}
+		delete(want, name)
+		meta = le.Uint32(m[4:])
+	}
+	if len(want) != 0 {
+		t.Errorf("missing channels %v", want)
+	}
+}
+
+// TestEveryColumnHasASignal loads every message table of the schema with the
+// UCR-01 definitions and checks that each numeric column is described by a
+// signal, so no channel is exported without its unit and conversions.
+func TestEveryColumnHasASignal(t *testing.T) {
+	defs, err := candecoder.LoadDefinitionSet("../../configs/UCR-01.dbc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := os.ReadFile("../../db/telem_data.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := make(map[string][]string)
+	for _, m := range regexp.MustCompile(`(?s)CREATE TABLE IF NOT EXISTS (\w+) \((.*?)\n\);`).FindAllStringSubmatch(string(schema), -1) {
+		for _, line := range strings.Split(m[2], "\n") {
+			f := strings.Fields(line)
+			if len(f) < 2 || strings.HasPrefix(f[0], "--") || f[0] == "timestamp" ||
+				strings.HasPrefix(f[1], "TEXT") || strings.HasPrefix(f[1], "VARCHAR") {
+				continue
+			}
+			columns[m[1]] = append(columns[m[1]], f[0])
+		}
+	}
+
+	groups, err := load(defs, func(tab db.MessageTable) (types.MessageRows, error) {
+		var cols []string
+		for _, c := range columns[tab.Table] {
+			if c != tab.Key {
+				cols = append(cols, c)
+			}
+		}
+		return types.MessageRows{Columns: cols, Times: []time.Time{time.Unix(0, 0)}, Values: [][]float64{make([]float64, len(cols))}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != len(db.MessageTables) {
+		t.Fatalf("got %d groups, want %d", len(groups), len(db.MessageTables))
+	}
+	for i, g := range groups {
+		tab := db.MessageTables[i]
+		if len(g.Channels) == 0 {
+			t.Errorf("%s: no columns in schema", tab.Table)
+		}
+		signals := tableSignals(defs, tab)
+		for _, c := range g.Channels {
+			if s, ok := signals[columnKey(c.Name)]; !ok || s.Name != c.Name {
+				t.Errorf("%s: column %s matches no signal", tab.Table, c.Name)
+			}
+		}
+	}
+}
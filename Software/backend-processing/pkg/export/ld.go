@@ -0,0 +1,197 @@
+// ld.go
+//
+// MoTeC i2 log (.ld) writer. An .ld file is a fixed header with pointers to
+// an event, venue and vehicle block, followed by a linked list of channel
+// headers and the channel data. Every channel is written as float32 samples
+// at one common rate; names must be unique, so a signal whose name is taken
+// by an earlier message is prefixed with its message name.
+package export
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Block sizes and layout constants of the .ld format.
+const (
+	ldHeaderSize  = 1762
+	ldEventSize   = 1154
+	ldVenueSize   = 1100
+	ldVehicleSize = 260
+	ldChannelSize = 124
+
+	ldFloat32TypeA = 0x07
+	ldFloat32Type  = 4
+)
+
+// DefaultLDSampleRate is the MoTeC export rate in Hz when none is configured.
+const DefaultLDSampleRate = 50
+
+// LDInfo fills in the header of a MoTeC log.
+type LDInfo struct {
+	Start   time.Time
+	Driver  string
+	Vehicle string
+	Venue   string
+	Event   string
+	Session string
+	Comment string
+}
+
+// ldChannel is one channel of an .ld file.
+type ldChannel struct {
+	name, unit string
+	samples    []float32
+}
+
+// WriteLD writes the groups to w as a MoTeC log from info.Start to end at rate
+// Hz. Each channel holds the last value at or before every sample time; empty
+// channels are left out.
+func WriteLD(w io.Writer, info LDInfo, end time.Time, rate int, groups []Group) error {
+	if rate <= 0 || rate > math.MaxUint16 {
+		return fmt.Errorf("invalid sample rate %d", rate)
+	}
+	interval := time.Second / time.Duration(rate)
+	n := int(end.Sub(info.Start) / interval)
+	if n <= 0 {
+		return fmt.Errorf("empty time range")
+	}
+
+	var channels []ldChannel
+	used := make(map[string]bool)
+	for _, g := range groups {
+		for j, c := range g.Channels {
+			samples, ok := resample(g, j, info.Start, interval, n)
+			if !ok {
+				continue
+			}
+			name := truncate(c.Name, 31)
+			if used[name] {
+				name = truncate(g.Name+"."+c.Name, 31)
+			}
+			used[name] = true
+			channels = append(channels, ldChannel{name: name, unit: c.Unit, samples: samples})
+		}
+	}
+
+	eventPtr := ldHeaderSize
+	venuePtr := eventPtr + ldEventSize
+	vehiclePtr := venuePtr + ldVenueSize
+	metaPtr := vehiclePtr + ldVehicleSize
+	dataPtr := metaPtr + ldChannelSize*len(channels)
+	le := binary.LittleEndian
+
+	buf := make([]byte, dataPtr, dataPtr+4*n*len(channels))
+	head := buf[:ldHeaderSize]
+	le.PutUint32(head[0:], 0x40)
+	if len(channels) > 0 {
+		le.PutUint32(head[8:], uint32(metaPtr))
+		le.PutUint32(head[12:], uint32(dataPtr))
+	}
+	le.PutUint32(head[36:], uint32(eventPtr))
+	le.PutUint16(head[64:], 1)
+	le.PutUint16(head[66:], 0x4240)
+	le.PutUint16(head[68:], 0xf)
+	le.PutUint32(head[70:], 0x1f44)
+	copy(head[74:82], "ADL")
+	le.PutUint16(head[82:], 420)
+	le.PutUint16(head[84:], 0xadb0)
+	le.PutUint32(head[86:], uint32(len(channels)))
+	start := info.Start.Local()
+	copy(head[94:110], start.Format("02/01/2006"))
+	copy(head[126:142], start.Format("15:04:05"))
+	copy(head[158:222], info.Driver)
+	copy(head[222:286], info.Vehicle)
+	copy(head[350:414], info.Venue)
+	le.PutUint32(head[1502:], 0xc81a4)
+	copy(head[1572:1636], info.Comment)
+
+	event := buf[eventPtr:venuePtr]
+	copy(event[0:64], info.Event)
+	copy(event[64:128], info.Session)
+	copy(event[128:1152], info.Comment)
+	le.PutUint16(event[1152:], uint16(venuePtr))
+
+	venue := buf[venuePtr:vehiclePtr]
+	copy(venue[0:64], info.Venue)
+	le.PutUint16(venue[1098:], uint16(vehiclePtr))
+
+	copy(buf[vehiclePtr:vehiclePtr+64], info.Vehicle)
+
+	for i, c := range channels {
+		off := metaPtr + i*ldChannelSize
+		meta := buf[off : off+ldChannelSize]
+		if i > 0 {
+			le.PutUint32(meta[0:], uint32(off-ldChannelSize))
+		}
+		if i < len(channels)-1 {
+			le.PutUint32(meta[4:], uint32(off+ldChannelSize))
+		}
+		le.PutUint32(meta[8:], uint32(dataPtr+i*4*n))
+		le.PutUint32(meta[12:], uint32(n))
+		le.PutUint16(meta[16:], uint16(0x2ee1+i))
+		le.PutUint16(meta[18:], ldFloat32TypeA)
+		le.PutUint16(meta[20:], ldFloat32Type)
+		le.PutUint16(meta[22:], uint16(rate))
+		le.PutUint16(meta[26:], 1) // mul
+		le.PutUint16(meta[28:], 1) // scale
+		copy(meta[32:64], c.name)
+		copy(meta[64:72], truncate(c.name, 8))
+		copy(meta[72:84], truncate(c.unit, 12))
+	}
+	for _, c := range channels {
+		for _, v := range c.samples {
+			buf = le.AppendUint32(buf, math.Float32bits(v))
+		}
+	}
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// resample returns n samples of channel j of g at start + i*interval, holding
+// the last value at or before each sample. Samples before the first value
+// take the first value. It returns false if the channel has no values.
+func resample(g Group, j int, start time.Time, interval time.Duration, n int) ([]float32, bool) {
+	first := math.NaN()
+	for i := range g.Times {
+		if v := value(g, i, j); !math.IsNaN(v) {
+			first = v
+			break
+		}
+	}
+	if math.IsNaN(first) {
+		return nil, false
+	}
+	samples := make([]float32, n)
+	last, k := first, 0
+	for i := range samples {
+		t := start.Add(time.Duration(i) * interval)
+		for ; k < len(g.Times) && !g.Times[k].After(t); k++ {
+			if v := value(g, k, j); !math.IsNaN(v) {
+				last = v
+			}
+		}
+		samples[i] = float32(last)
+	}
+	return samples, true
+}
+
+// value returns channel j of row i of g, or NaN if the row is short.
+func value(g Group, i, j int) float64 {
+	if i < len(g.Values) && j < len(g.Values[i]) {
+		return g.Values[i][j]
+	}
+	return math.NaN()
+}
+
+// truncate shortens s to at most n bytes.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,200 @@
+// mdf4.go
+//
+// ASAM MDF 4.10 writer. Every group becomes its own data group holding one
+// channel group: a float64 time master channel in seconds from the start of
+// the file followed by one float64 channel per signal, with the signal's unit
+// and a value-to-text conversion for its choices. Missing values are NaN.
+package export
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// MDF block link counts and data types.
+const (
+	mdfHDLinks = 6
+	mdfFHLinks = 2
+	mdfDGLinks = 4
+	mdfCGLinks = 6
+	mdfCNLinks = 8
+	mdfCCLinks = 4 // plus one reference per value and the default
+
+	mdfChannelValue  = 0
+	mdfChannelMaster = 2
+	mdfSyncTime      = 1
+	mdfFloatLE       = 4
+	mdfCCValueToText = 7
+)
+
+// WriteMDF4 writes the groups to w as an MDF4 file whose measurement starts
+// at start.
+func WriteMDF4(w io.Writer, start time.Time, groups []Group) error {
+	f := &mdfFile{}
+	f.buf = append(f.buf, mdfIdentification()...)
+
+	hd := f.block("HD", mdfHDLinks, mdfHeaderData(start))
+	fh := f.block("FH", mdfFHLinks, mdfHistoryData(time.Now()))
+	f.link(hd, 1, fh)
+	f.link(fh, 1, f.block("MD", 0, mdfString(
+		"<FHcomment><TX>Exported from the telemetry database</TX>"+
+			"<tool_id>telem-system</tool_id><tool_vendor>UCR Formula SAE</tool_vendor>"+
+			"<tool_version>1.0</tool_version></FHcomment>")))
+
+	prevDG, prevLink := hd, 0 // hd_dg_first, then dg_dg_next
+	for _, g := range groups {
+		if len(g.Times) == 0 {
+			continue
+		}
+		dg := f.block("DG", mdfDGLinks, make([]byte, 8))
+		f.link(prevDG, prevLink, dg)
+		prevDG, prevLink = dg, 0
+
+		recordSize := 8 * (1 + len(g.Channels))
+		cgData := make([]byte, 32)
+		binary.LittleEndian.PutUint64(cgData[8:], uint64(len(g.Times)))
+		binary.LittleEndian.PutUint32(cgData[24:], uint32(recordSize))
+		cg := f.block("CG", mdfCGLinks, cgData)
+		f.link(dg, 1, cg)
+		f.link(cg, 2, f.text(g.Name))
+		f.link(cg, 5, f.text(fmt.Sprintf("CAN ID %s", g.ID)))
+
+		master := f.channel("t", "s", nil, mdfChannelMaster, 0)
+		f.link(cg, 1, master)
+		prevCN := master
+		for i, c := range g.Channels {
+			cn := f.channel(c.Name, c.Unit, c.Choices, mdfChannelValue, 8*(i+1))
+			f.link(prevCN, 0, cn)
+			prevCN = cn
+		}
+
+		records := make([]byte, 0, recordSize*len(g.Times))
+		for i, t := range g.Times {
+			records = binary.LittleEndian.AppendUint64(records, math.Float64bits(t.Sub(start).Seconds()))
+			for j := range g.Channels {
+				records = binary.LittleEndian.AppendUint64(records, math.Float64bits(value(g, i, j)))
+			}
+		}
+		f.link(dg, 2, f.block("DT", 0, records))
+	}
+
+	_, err := w.Write(f.buf)
+	return err
+}
+
+// mdfFile builds an MDF file in memory; blocks are appended 8-byte aligned
+// and linked by patching their link sections.
+type mdfFile struct {
+	buf []byte
+}
+
+// block appends a block with links zeroed and returns its offset.
+func (f *mdfFile) block(id string, links int, data []byte) int64 {
+	for len(f.buf)%8 != 0 {
+		f.buf = append(f.buf, 0)
+	}
+	off := int64(len(f.buf))
+	length := 24 + 8*links + len(data)
+	f.buf = append(f.buf, '#', '#', id[0], id[1], 0, 0, 0, 0)
+	f.buf = binary.LittleEndian.AppendUint64(f.buf, uint64(length))
+	f.buf = binary.LittleEndian.AppendUint64(f.buf, uint64(links))
+	f.buf = append(f.buf, make([]byte, 8*links)...)
+	f.buf = append(f.buf, data...)
+	return off
+}
+
+// link sets link i of the block at off to target.
+func (f *mdfFile) link(off int64, i int, target int64) {
+	binary.LittleEndian.PutUint64(f.buf[off+24+8*int64(i):], uint64(target))
+}
+
+// text appends a TX block, or returns a nil link for an empty string.
+func (f *mdfFile) text(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	return f.block("TX", 0, mdfString(s))
+}
+
+// channel appends a float64 channel at byte offset in the record and returns
+// its offset.
+func (f *mdfFile) channel(name, unit string, choices map[float64]string, kind byte, offset int) int64 {
+	data := make([]byte, 72)
+	data[0] = kind
+	if kind == mdfChannelMaster {
+		data[1] = mdfSyncTime
+	}
+	data[2] = mdfFloatLE
+	binary.LittleEndian.PutUint32(data[4:], uint32(offset))
+	binary.LittleEndian.PutUint32(data[8:], 64)
+	cn := f.block("CN", mdfCNLinks, data)
+	f.link(cn, 2, f.text(name))
+	f.link(cn, 6, f.text(unit))
+	if len(choices) > 0 {
+		f.link(cn, 4, f.valueToText(choices))
+	}
+	return cn
+}
+
+// valueToText appends a value-to-text conversion (CC type 7) with a nil
+// default, so values without a label are shown as numbers.
+func (f *mdfFile) valueToText(choices map[float64]string) int64 {
+	keys := make([]float64, 0, len(choices))
+	for k := range choices {
+		keys = append(keys, k)
+	}
+	sort.Float64s(keys)
+	labels := make([]int64, len(keys))
+	for i, k := range keys {
+		labels[i] = f.text(choices[k])
+	}
+
+	data := make([]byte, 24, 24+8*len(keys))
+	data[0] = mdfCCValueToText
+	binary.LittleEndian.PutUint16(data[4:], uint16(len(keys)+1))
+	binary.LittleEndian.PutUint16(data[6:], uint16(len(keys)))
+	for _, k := range keys {
+		data = binary.LittleEndian.AppendUint64(data, math.Float64bits(k))
+	}
+	cc := f.block("CC", mdfCCLinks+len(keys)+1, data)
+	for i, l := range labels {
+		f.link(cc, mdfCCLinks+i, l)
+	}
+	return cc
+}
+
+// mdfIdentification returns the 64-byte identification block.
+func mdfIdentification() []byte {
+	id := make([]byte, 64)
+	copy(id, "MDF     4.10    telem   ")
+	binary.LittleEndian.PutUint16(id[28:], 410)
+	return id
+}
+
+// mdfHeaderData returns the data section of the HD block: the start time in
+// UTC nanoseconds, with zero offsets and flags.
+func mdfHeaderData(start time.Time) []byte {
+	data := make([]byte, 32)
+	binary.LittleEndian.PutUint64(data, uint64(start.UnixNano()))
+	return data
+}
+
+// mdfHistoryData returns the data section of an FH block.
+func mdfHistoryData(t time.Time) []byte {
+	data := make([]byte, 16)
+	binary.LittleEndian.PutUint64(data, uint64(t.UnixNano()))
+	return data
+}
+
+// mdfString returns s zero-terminated and padded to a multiple of 8 bytes.
+func mdfString(s string) []byte {
+	data := append([]byte(s), 0)
+	for len(data)%8 != 0 {
+		data = append(data, 0)
+	}
+	return data
+}
@@ -0,0 +1,187 @@
+// linkstats.go
+//
+// Package linkstats tracks the quality of the UDP telemetry link per sender.
+// Datagrams may be lost, duplicated or arrive out of order; the tracker
+// accepts each sequence number once within a sliding window, counts what was
+// lost, reordered or arrived too late, and estimates interarrival jitter as
+// in RFC 3550. It also maps sender timestamps to host time, using the
+// smallest recent transit time so that queuing delays on the link do not
+// shift frame times.
+package linkstats
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"telem-system/pkg/candecoder"
+)
+
+const (
+	// window is how far behind the newest sequence number a datagram may
+	// arrive and still be accepted.
+	window = 64
+	// restartGap is how far the sequence number must jump back to be taken
+	// as a restarted sender rather than a late datagram. Smaller jumps are
+	// restarts too if the sender timestamp is newer than that of the newest
+	// datagram, or more than lateLimit older.
+	restartGap = 1024
+	lateLimit  = 10 * time.Second
+	// transitSamples is the number of recent transit times whose minimum
+	// maps sender timestamps to host time.
+	transitSamples = 64
+)
+
+// LinkStats are the link statistics of one sender.
+type LinkStats struct {
+	Sender     uint16    `json:"sender"`
+	Addr       string    `json:"addr"` // source address of the last datagram
+	Packets    uint64    `json:"packets"`
+	Frames     uint64    `json:"frames"`
+	Lost       uint64    `json:"lost"` // never arrived, or arrived late
+	LossRatio  float64   `json:"loss_ratio"`
+	Reordered  uint64    `json:"reordered"`
+	Duplicates uint64    `json:"duplicates"`
+	Late       uint64    `json:"late"` // arrived behind the window and dropped
+	Restarts   uint64    `json:"restarts"`
+	JitterMs   float64   `json:"jitter_ms"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+// sender is the tracking state of one sender.
+type sender struct {
+	stats    LinkStats
+	expected uint64 // datagrams expected before the last restart
+	base     uint32 // first sequence number since the last restart
+	highest  uint32
+	newest   uint64 // sender timestamp of highest
+	seen     uint64 // bit i: highest-i was received
+
+	lastTransit int64 // µs; transit times are host minus sender clock
+	jitter      float64
+	transits    [transitSamples]int64
+	nTransits   int
+}
+
+// Tracker tracks the link statistics of every sender. It is safe for
+// concurrent use.
+type Tracker struct {
+	mu        sync.Mutex
+	senders   map[uint16]*sender
+	malformed uint64
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{senders: make(map[uint16]*sender)}
+}
+
+// Observe records a datagram that arrived from addr at arrival. It returns
+// false for duplicates and datagrams too late to accept, whose frames must be
+// dropped; otherwise it returns the host time of the datagram's sender
+// timestamp.
+func (t *Tracker) Observe(d candecoder.UDPDatagram, addr string, arrival time.Time) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.senders[d.Sender]
+	if !ok {
+		s = &sender{stats: LinkStats{Sender: d.Sender}}
+		t.senders[d.Sender] = s
+		s.start(d)
+	} else {
+		diff := int32(d.Seq - s.highest)
+		switch {
+		case diff <= 0 && s.restarted(d, diff):
+			s.expected += uint64(s.highest-s.base) + 1
+			s.stats.Restarts++
+			s.start(d)
+		case diff > 0:
+			if diff >= window {
+				s.seen = 0
+			} else {
+				s.seen <<= uint(diff)
+			}
+			s.seen |= 1
+			s.highest, s.newest = d.Seq, d.SenderTime
+		case diff > -window:
+			bit := uint64(1) << uint(-diff)
+			if s.seen&bit != 0 {
+				s.stats.Duplicates++
+				return time.Time{}, false
+			}
+			s.seen |= bit
+			s.stats.Reordered++
+		default:
+			s.stats.Late++
+			return time.Time{}, false
+		}
+	}
+	s.stats.Packets++
+	s.stats.Frames += uint64(len(d.Frames))
+	s.stats.Addr = addr
+	s.stats.LastSeen = arrival
+	return s.hostTime(d.SenderTime, arrival), true
+}
+
+// Malformed counts a datagram that could not be parsed.
+func (t *Tracker) Malformed() {
+	t.mu.Lock()
+	t.malformed++
+	t.mu.Unlock()
+}
+
+// Stats returns a snapshot of every sender's statistics, sorted by sender,
+// and the number of malformed datagrams.
+func (t *Tracker) Stats() ([]LinkStats, uint64) {
+	t.mu.Lock()
+	out := make([]LinkStats, 0, len(t.senders))
+	for _, s := range t.senders {
+		st := s.stats
+		expected := s.expected + uint64(s.highest-s.base) + 1
+		if expected > st.Packets {
+			st.Lost = expected - st.Packets
+			st.LossRatio = float64(st.Lost) / float64(expected)
+		}
+		st.JitterMs = s.jitter / 1000
+		out = append(out, st)
+	}
+	malformed := t.malformed
+	t.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Sender < out[j].Sender })
+	return out, malformed
+}
+
+// restarted reports whether d, diff datagrams behind the newest, comes from
+// a restarted sender rather than arriving late.
+func (s *sender) restarted(d candecoder.UDPDatagram, diff int32) bool {
+	return diff <= -restartGap || d.SenderTime > s.newest ||
+		s.newest-d.SenderTime > uint64(lateLimit.Microseconds())
+}
+
+// start begins a new sequence at d, the first datagram of a sender or of a
+// restart. Transit times are discarded since the sender's clock may have been
+// reset too.
+func (s *sender) start(d candecoder.UDPDatagram) {
+	s.base, s.highest, s.newest, s.seen = d.Seq, d.Seq, d.SenderTime, 1
+	s.nTransits = 0
+}
+
+// hostTime updates the jitter estimate with a datagram's transit time and
+// maps its sender timestamp to host time.
+func (s *sender) hostTime(senderTime uint64, arrival time.Time) time.Time {
+	transit := arrival.UnixMicro() - int64(senderTime)
+	if s.nTransits > 0 {
+		d := math.Abs(float64(transit - s.lastTransit))
+		s.jitter += (d - s.jitter) / 16
+	}
+	s.lastTransit = transit
+	s.transits[s.nTransits%transitSamples] = transit
+	s.nTransits++
+
+	shortest := transit
+	for _, tr := range s.transits[:min(s.nTransits, transitSamples)] {
+		shortest = min(shortest, tr)
+	}
+	return time.UnixMicro(int64(senderTime) + shortest)
+}
@@ -0,0 +1,72 @@
+package linkstats
+
+import (
+	"testing"
+	"time"
+
+	"telem-system/pkg/candecoder"
+)
+
+func TestTrackerLossAndReordering(t *testing.T) {
+	tr := NewTracker()
+	host := time.Unix(1700000000, 0)
+	// The sender's clock started a minute before the first datagram; every
+	// datagram is 10 ms after the previous one and takes 2 ms to arrive,
+	// except 1002, which is held up behind 1003.
+	observe := func(seq uint32, delayMs int) (time.Time, bool) {
+		k := time.Duration(int(seq)-1000) * 10 * time.Millisecond
+		d := candecoder.UDPDatagram{Sender: 7, Seq: seq, SenderTime: uint64((time.Minute + k).Microseconds())}
+		return tr.Observe(d, "192.168.4.2:4000", host.Add(k+time.Duration(delayMs)*time.Millisecond))
+	}
+	for _, step := range []struct {
+		seq     uint32
+		delayMs int
+		accept  bool
+	}{
+		{1000, 2, true}, {1001, 2, true}, {1003, 2, true}, {1002, 15, true},
+		{1005, 2, true}, {1005, 2, false}, {1006, 2, true}, {1007, 2, true},
+		{1008, 2, true}, {1009, 2, true},
+		{900, 2, false}, // 100 behind and a second old: late
+	} {
+		if _, ok := observe(step.seq, step.delayMs); ok != step.accept {
+			t.Errorf("seq %d accepted = %v, want %v", step.seq, ok, step.accept)
+		}
+	}
+	// Frames are timed by the sender clock plus the shortest transit time,
+	// so the delayed datagram keeps its place.
+	if got, _ := observe(1010, 9); !got.Equal(host.Add(102 * time.Millisecond)) {
+		t.Errorf("host time of 1010 = %v, want %v", got, host.Add(102*time.Millisecond))
+	}
+
+	stats, _ := tr.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("got %d senders, want 1", len(stats))
+	}
+	s := stats[0]
+	if s.Sender != 7 || s.Packets != 10 || s.Lost != 1 || s.Duplicates != 1 || s.Reordered != 1 || s.Late != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+	if s.LossRatio < 0.09 || s.LossRatio > 0.091 || s.JitterMs <= 0 || s.Addr != "192.168.4.2:4000" {
+		t.Errorf("loss ratio %v, jitter %v ms, addr %q", s.LossRatio, s.JitterMs, s.Addr)
+	}
+}
+
+func TestTrackerSenderRestart(t *testing.T) {
+	tr := NewTracker()
+	now := time.Now()
+	for seq := uint32(0); seq < 50; seq++ {
+		tr.Observe(candecoder.UDPDatagram{Sender: 1, Seq: seq, SenderTime: 30e6 + uint64(seq)*1000}, "a", now)
+	}
+	// A rebooted sender starts over with its clock near zero.
+	if _, ok := tr.Observe(candecoder.UDPDatagram{Sender: 1, Seq: 0, SenderTime: 500}, "a", now); !ok {
+		t.Fatal("first datagram after a restart dropped")
+	}
+	if _, ok := tr.Observe(candecoder.UDPDatagram{Sender: 1, Seq: 2, SenderTime: 2500}, "a", now); !ok {
+		t.Fatal("second datagram after a restart dropped")
+	}
+	tr.Malformed()
+	stats, malformed := tr.Stats()
+	if s := stats[0]; s.Restarts != 1 || s.Packets != 52 || s.Lost != 1 || s.Late != 0 || malformed != 1 {
+		t.Errorf("stats = %+v, malformed %d", s, malformed)
+	}
+}
@@ -0,0 +1,126 @@
+// bridge.go
+//
+// Package mqttbridge feeds CAN frames received over MQTT into the decoder.
+// Messages on the subscribed topics carry raw frames, either in the binary
+// framing of the ESP32 bridge ([4-byte ID][1-byte DLC][data], several per
+// message) or as the JSON objects of the MQTT test sketch. Decoded frames are
+// handed to Handle and, with a republish prefix, every valid signal is
+// published to <prefix>/<message>/<signal> as its formatted value. The broker
+// connection itself is left to the caller's MQTT client.
+package mqttbridge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/types"
+)
+
+// Payload formats of the subscribed topics.
+const (
+	FormatBinary = "binary"
+	FormatJSON   = "json"
+)
+
+// Definitions provides the definitions each frame is decoded with. A
+// *candecoder.Registry is one.
+type Definitions interface {
+	Current() *candecoder.Definitions
+}
+
+// Bridge decodes the frames of one MQTT connection. Receive is not safe for
+// concurrent use.
+type Bridge struct {
+	Defs            Definitions
+	Format          string // FormatBinary or FormatJSON
+	RepublishPrefix string // empty disables republishing
+	Handle          func(id types.CANID, frame *candecoder.DecodedFrame)
+	Publish         func(topic string, payload []byte) error // used when republishing
+
+	Messages  uint64 // MQTT messages received
+	Decoded   uint64 // frames decoded
+	Unknown   uint64 // frames whose ID has no definition
+	Malformed uint64 // messages that could not be parsed
+
+	frame candecoder.DecodedFrame
+}
+
+// ParsePayload returns the frames of one message.
+func ParsePayload(format string, payload []byte) ([]candecoder.Frame, error) {
+	switch format {
+	case FormatBinary:
+		r := candecoder.NewBridgeReader(bytes.NewReader(payload))
+		var frames []candecoder.Frame
+		for {
+			f, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				if r.Resyncs > 0 {
+					return frames, fmt.Errorf("%d corrupt bytes in payload", r.Skipped)
+				}
+				return frames, nil
+			}
+			if err != nil {
+				return frames, err
+			}
+			frames = append(frames, candecoder.Frame{ID: f.ID, Data: append([]byte(nil), f.Data...)})
+		}
+	case FormatJSON:
+		f, err := candecoder.ParseKvaserJSON(payload)
+		if err != nil || f.IsRemote() || f.IsErrorFrame() {
+			return nil, err
+		}
+		return []candecoder.Frame{{ID: f.ID, Data: f.Data}}, nil
+	}
+	return nil, fmt.Errorf("unknown MQTT payload format %q", format)
+}
+
+// Receive decodes the frames of one message. It returns an error only when
+// republishing fails.
+func (b *Bridge) Receive(topic string, payload []byte) error {
+	b.Messages++
+	frames, err := ParsePayload(b.Format, payload)
+	if err != nil {
+		b.Malformed++
+		if b.Malformed == 1 {
+			log.Printf("MQTT %s: %v", topic, err)
+		}
+	}
+	for _, f := range frames {
+		plan, exists := b.Defs.Current().Plans[f.ID]
+		if !exists {
+			b.Unknown++
+			continue
+		}
+		if err := plan.Decode(f.Data, &b.frame); err != nil {
+			continue
+		}
+		b.Decoded++
+		if b.Handle != nil {
+			b.Handle(f.ID, &b.frame)
+		}
+		if b.RepublishPrefix != "" {
+			if err := b.republish(&b.frame); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// republish publishes each valid signal of a decoded frame.
+func (b *Bridge) republish(frame *candecoder.DecodedFrame) error {
+	for _, sv := range frame.Signals {
+		if !sv.Valid {
+			continue
+		}
+		topic := b.RepublishPrefix + "/" + frame.Message + "/" + sv.Name
+		if err := b.Publish(topic, []byte(candecoder.FormatValue(sv))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
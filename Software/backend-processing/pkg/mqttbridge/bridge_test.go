@@ -0,0 +1,74 @@
+package mqttbridge
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/types"
+)
+
+const testDBC = `VERSION ""
+
+BO_ 291 TCU: 3 TCU
+ SG_ Speed : 0|16@1+ (0.5,0) [0|0] "km/h" Vector__XXX
+ SG_ Gear : 16|8@1+ (1,0) [0|0] "" Vector__XXX
+`
+
+func TestBridgeDecodesAndRepublishes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "car.dbc")
+	if err := os.WriteFile(path, []byte(testDBC), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defs, err := candecoder.NewRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var handled []float64
+	published := map[string]string{}
+	b := &Bridge{
+		Defs:            defs,
+		Format:          FormatBinary,
+		RepublishPrefix: "signals",
+		Handle: func(id types.CANID, frame *candecoder.DecodedFrame) {
+			handled = append(handled, frame.Float("Speed"))
+		},
+		Publish: func(topic string, payload []byte) error {
+			published[topic] = string(payload)
+			return nil
+		},
+	}
+
+	// One message with a known frame and one without a definition.
+	payload := []byte{0x00, 0x00, 0x01, 0x23, 3, 0x2C, 0x01, 4, 0x00, 0x00, 0x04, 0x56, 1, 0xFF}
+	if err := b.Receive("car/raw", payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(handled) != 1 || handled[0] != 150 {
+		t.Errorf("handled Speed = %v, want [150]", handled)
+	}
+	if b.Messages != 1 || b.Decoded != 1 || b.Unknown != 1 || b.Malformed != 0 {
+		t.Errorf("counts = %d messages, %d decoded, %d unknown, %d malformed", b.Messages, b.Decoded, b.Unknown, b.Malformed)
+	}
+	want := map[string]string{"signals/TCU/Speed": "150", "signals/TCU/Gear": "4"}
+	if !reflect.DeepEqual(published, want) {
+		t.Errorf("published %v, want %v", published, want)
+	}
+}
+
+func TestParseJSONPayload(t *testing.T) {
+	frames, err := ParsePayload(FormatJSON, []byte(`{"Time":0.34959, "Channel":1, "ID":513, "Flags":2, "DLC":3, "Data":["90", "00", "00"], "Counter":1, "AbsTime":"2024-10-16 0:16"}`))
+	if err != nil || len(frames) != 1 {
+		t.Fatalf("ParsePayload = %v, %v", frames, err)
+	}
+	if frames[0].ID != types.NewCANID(513, false) || !bytes.Equal(frames[0].Data, []byte{0x90, 0, 0}) {
+		t.Errorf("frame = %+v", frames[0])
+	}
+	if _, err := ParsePayload(FormatJSON, []byte(`{"ID":"513","DLC":3,"Data":["90"]}`)); err == nil {
+		t.Error("short JSON frame accepted")
+	}
+}
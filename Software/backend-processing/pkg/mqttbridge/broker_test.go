@@ -0,0 +1,105 @@
+package mqttbridge
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"telem-system/pkg/candecoder"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	mqttserver "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+// startBroker runs an in-process broker on a free local port for the rest of
+// the test and returns its URL.
+func startBroker(t *testing.T) string {
+	t.Helper()
+	server := mqttserver.New(&mqttserver.Options{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if err := server.AddHook(new(auth.AllowHook), nil); err != nil {
+		t.Fatal(err)
+	}
+	ln := listeners.NewTCP(listeners.Config{ID: "test", Address: "127.0.0.1:0"})
+	if err := server.AddListener(ln); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Serve(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return "tcp://" + ln.Address()
+}
+
+// TestBridgeThroughBroker runs the bridge and its clients against an
+// in-process broker.
+func TestBridgeThroughBroker(t *testing.T) {
+	broker := startBroker(t)
+	path := filepath.Join(t.TempDir(), "car.dbc")
+	if err := os.WriteFile(path, []byte(testDBC), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defs, err := candecoder.NewRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wait := func(tok mqtt.Token) {
+		t.Helper()
+		if !tok.WaitTimeout(5*time.Second) || tok.Error() != nil {
+			t.Fatalf("broker %s: %v", broker, tok.Error())
+		}
+	}
+	connect := func(id string) mqtt.Client {
+		t.Helper()
+		c := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(broker).SetClientID(id))
+		wait(c.Connect())
+		t.Cleanup(func() { c.Disconnect(250) })
+		return c
+	}
+
+	server := connect("telemetry-test")
+	b := &Bridge{
+		Defs:            defs,
+		Format:          FormatBinary,
+		RepublishPrefix: "telemetry-test/signals",
+		Publish: func(topic string, payload []byte) error {
+			tok := server.Publish(topic, 0, false, payload)
+			tok.Wait()
+			return tok.Error()
+		},
+	}
+	raw := make(chan []byte, 4)
+	wait(server.Subscribe("telemetry-test/raw", 1, func(_ mqtt.Client, m mqtt.Message) { raw <- m.Payload() }))
+
+	signals := make(chan mqtt.Message, 4)
+	dashboard := connect("telemetry-test-dashboard")
+	wait(dashboard.Subscribe("telemetry-test/signals/#", 1, func(_ mqtt.Client, m mqtt.Message) { signals <- m }))
+
+	car := connect("telemetry-test-car")
+	wait(car.Publish("telemetry-test/raw", 1, false, []byte{0x00, 0x00, 0x01, 0x23, 3, 0x2C, 0x01, 4}))
+	select {
+	case payload := <-raw:
+		if err := b.Receive("telemetry-test/raw", payload); err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("raw frame not delivered")
+	}
+
+	want := map[string]string{"telemetry-test/signals/TCU/Speed": "150", "telemetry-test/signals/TCU/Gear": "4"}
+	for range want {
+		select {
+		case m := <-signals:
+			if string(m.Payload()) != want[m.Topic()] {
+				t.Errorf("%s = %q, want %q", m.Topic(), m.Payload(), want[m.Topic()])
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("republished signal not delivered")
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// linkstats.go
+//
+// Live link statistics. The UDP ingest reports the loss and jitter of every
+// sender periodically, so the dashboard can show link quality next to the
+// data it affects.
+package processdata
+
+import (
+	"time"
+
+	"telem-system/pkg/linkstats"
+)
+
+// BroadcastLinkStats broadcasts the statistics of each UDP sender as a
+// "udp_link" message.
+func BroadcastLinkStats(stats []linkstats.LinkStats) {
+	now := time.Now()
+	for _, s := range stats {
+		broadcastTelemetry(map[string]interface{}{
+			"type": "udp_link",
+			"payload": map[string]interface{}{
+				"sender":     int(s.Sender),
+				"addr":       s.Addr,
+				"packets":    s.Packets,
+				"frames":     s.Frames,
+				"lost":       s.Lost,
+				"loss_ratio": s.LossRatio,
+				"reordered":  s.Reordered,
+				"duplicates": s.Duplicates,
+				"late":       s.Late,
+				"restarts":   s.Restarts,
+				"jitter_ms":  s.JitterMs,
+				"last_seen":  s.LastSeen.Unix(),
+			},
+			"time": now.Format("2006-01-02 15:04:05.000"),
+		})
+	}
+}
@@ -10,10 +10,13 @@ package processdata
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"telem-system/pkg/candecoder"
 	"telem-system/pkg/db"
 	"telem-system/pkg/types"
 	"telem-system/pkg/utils"
@@ -27,6 +30,79 @@ import (
 // BroadcastFunc is assigned by main to push real‑time messages to the WebSocket hub.
 var BroadcastFunc func(msg []byte)
 
+// insertCellData stores an aggregated cell_data row; tests replace it.
+var insertCellData = db.InsertCellData
+
+// cellMu serialises access to cell aggregators, which ingest goroutines may
+// share.
+var cellMu sync.Mutex
+
+// signalColumns maps the signals of stored messages to the columns whose
+// names differ from them, as listed in db.MessageTables.
+var signalColumns = func() map[types.CANID]map[string]string {
+	m := make(map[types.CANID]map[string]string)
+	for _, t := range db.MessageTables {
+		for col, signal := range t.Columns {
+			for _, id := range append([]types.CANID{t.ID}, t.Sources...) {
+				if m[id] == nil {
+					m[id] = make(map[string]string)
+				}
+				m[id][signal] = col
+			}
+		}
+	}
+	return m
+}()
+
+// signalColumn returns the column that stores a signal of frame id.
+func signalColumn(id types.CANID, signal string) string {
+	if col, ok := signalColumns[id][signal]; ok {
+		return col
+	}
+	return signal
+}
+
+// nullColumns returns the columns of the frame's invalid signals, which are
+// stored as NULL rather than 0.
+func nullColumns(frame *candecoder.DecodedFrame) []string {
+	var cols []string
+	for _, sv := range frame.Signals {
+		if !sv.Valid {
+			cols = append(cols, signalColumn(frame.ID, sv.Name))
+		}
+	}
+	return cols
+}
+
+// broadcastFrame broadcasts a payload built from frame. Invalid signals are
+// sent as null and out-of-range ones are listed under "out_of_range", so the
+// dashboard can mark them.
+func broadcastFrame(frame *candecoder.DecodedFrame, payloadMap map[string]interface{}) {
+	if content, ok := payloadMap["payload"].(map[string]interface{}); ok {
+		keys := make(map[string]string, len(content))
+		for k := range content {
+			keys[db.ColumnKey(k)] = k
+		}
+		var outOfRange []interface{}
+		for _, sv := range frame.Signals {
+			key, ok := keys[db.ColumnKey(signalColumn(frame.ID, sv.Name))]
+			if !ok {
+				continue
+			}
+			if !sv.Valid {
+				content[key] = nil
+			}
+			if sv.OutOfRange {
+				outOfRange = append(outOfRange, key)
+			}
+		}
+		if outOfRange != nil {
+			content["out_of_range"] = outOfRange
+		}
+	}
+	broadcastTelemetry(payloadMap)
+}
+
 // broadcastTelemetry converts a map payload into a TelemetryMessage proto,
 // marshals it into binary format and then calls BroadcastFunc.
 func broadcastTelemetry(payloadMap map[string]interface{}) {
@@ -55,115 +131,116 @@ func broadcastTelemetry(payloadMap map[string]interface{}) {
 	}
 }
 
+// frameHandler processes one decoded frame of a routed ID.
+type frameHandler func(frameID types.CANID, frame *candecoder.DecodedFrame, cellDataBuffers map[float64]*types.Cell_Data)
+
+// frameHandlers routes frame IDs to their processing functions. The car's
+// messages all use standard IDs, so extended frames never match a route.
+var frameHandlers = map[types.CANID]frameHandler{
+	4:    frameOnly(processPackCurrentData),
+	5:    frameOnly(processPackVoltageData),
+	6:    frameOnly(processTCUData),
+	8:    frameOnly(processACULVFD1Data),
+	30:   frameOnly(processACULVFD2Data),
+	40:   frameOnly(processACULV1Data),
+	41:   frameOnly(processACULV2Data),
+	50:   processCellData,
+	51:   processCellData,
+	52:   processCellData,
+	53:   processCellData,
+	54:   processCellData,
+	55:   processCellData,
+	56:   processCellData,
+	57:   processCellData,
+	60:   thermHandler(1),
+	61:   thermHandler(2),
+	62:   thermHandler(3),
+	63:   thermHandler(4),
+	64:   thermHandler(5),
+	65:   thermHandler(6),
+	66:   thermHandler(7),
+	67:   thermHandler(8),
+	68:   thermHandler(9),
+	69:   thermHandler(10),
+	70:   thermHandler(11),
+	71:   thermHandler(12),
+	80:   frameOnly(processGPSBestPosData),
+	81:   frameOnly(processINS_GPS_Data),
+	82:   frameOnly(processINS_IMUData),
+	100:  frameOnly(processBamocarData),
+	101:  frameOnly(processFrontFrequencyData),
+	102:  frameOnly(processRearFrequencyData),
+	1280: frameOnly(processPDM1Data),
+	1536: frameOnly(processFrontAeroData),
+	1537: frameOnly(processRearAeroData),
+	200:  frameOnly(processEncoderData),
+	258:  frameOnly(processRearAnalogData),
+	259:  frameOnly(processFrontAnalogData),
+	385:  frameOnly(processBamocarTxData),
+	513:  frameOnly(processBamocarRxData),
+	600:  frameOnly(processBamoCarReTransmitData),
+	1312: frameOnly(processPDMCurrentData),
+	1552: frameOnly(processFrontStrainGauges1Data),
+	1553: frameOnly(processFrontStrainGauges2Data),
+	1554: frameOnly(processRearStrainGauges1Data),
+	1555: frameOnly(processRearStrainGauges2Data),
+	1680: frameOnly(processPDMReTransmitData),
+}
+
+// frameOnly adapts a processing function that only needs the frame.
+func frameOnly(process func(frame *candecoder.DecodedFrame)) frameHandler {
+	return func(_ types.CANID, frame *candecoder.DecodedFrame, _ map[float64]*types.Cell_Data) {
+		process(frame)
+	}
+}
+
+// thermHandler processes the frame of one thermistor board.
+func thermHandler(thermID int) frameHandler {
+	return func(_ types.CANID, frame *candecoder.DecodedFrame, _ map[float64]*types.Cell_Data) {
+		processThermData(frame, thermID)
+	}
+}
+
+// HandledFrameIDs returns the frame IDs routed by HandleDataInsertions in
+// ascending order.
+func HandledFrameIDs() []types.CANID {
+	ids := make([]types.CANID, 0, len(frameHandlers))
+	for id := range frameHandlers {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
 // HandleDataInsertions routes decoded CAN frame data to its appropriate processing function.
+// Frames whose ID is not in HandledFrameIDs are ignored.
+// cellDataBuffers collects the cell voltages of one stream until its last cell
+// frame arrives; give every connection its own so rows are never mixed.
 func HandleDataInsertions(
-	frameID uint32,
-	decoded map[string]string,
+	frameID types.CANID,
+	frame *candecoder.DecodedFrame,
 	cellDataBuffers map[float64]*types.Cell_Data,
 	recordCount int,
 	path string,
 ) {
-	switch frameID {
-	case 4:
-		processPackCurrentData(decoded)
-	case 5:
-		processPackVoltageData(decoded)
-	case 6:
-		processTCUData(decoded)
-	case 8:
-		processACULVFD1Data(decoded)
-	case 30:
-		processACULVFD2Data(decoded)
-	case 40:
-		processACULV1Data(decoded)
-	case 41:
-		processACULV2Data(decoded)
-	case 50, 51, 52, 53, 54, 55, 56, 57:
-		processCellData(frameID, decoded, cellDataBuffers)
-	case 60:
-		processThermData(decoded, 1)
-	case 61:
-		processThermData(decoded, 2)
-	case 62:
-		processThermData(decoded, 3)
-	case 63:
-		processThermData(decoded, 4)
-	case 64:
-		processThermData(decoded, 5)
-	case 65:
-		processThermData(decoded, 6)
-	case 66:
-		processThermData(decoded, 7)
-	case 67:
-		processThermData(decoded, 8)
-	case 68:
-		processThermData(decoded, 9)
-	case 69:
-		processThermData(decoded, 10)
-	case 70:
-		processThermData(decoded, 11)
-	case 71:
-		processThermData(decoded, 12)
-	case 80:
-		processGPSBestPosData(decoded)
-	case 81:
-		processINS_GPS_Data(decoded)
-	case 82:
-		processINS_IMUData(decoded)
-	case 100:
-		processBamocarData(decoded)
-	case 101:
-		processFrontFrequencyData(decoded)
-	case 102:
-		processRearFrequencyData(decoded)
-	case 1280:
-		processPDM1Data(decoded)
-	case 1536:
-		processFrontAeroData(decoded)
-	case 1537:
-		processRearAeroData(decoded)
-	case 200:
-		processEncoderData(decoded)
-	case 258:
-		processRearAnalogData(decoded)
-	case 259:
-		processFrontAnalogData(decoded)
-	case 385:
-		processBamocarTxData(decoded)
-	case 513:
-		processBamocarRxData(decoded)
-	case 600:
-		processBamoCarReTransmitData(decoded)
-	case 1312:
-		processPDMCurrentData(decoded)
-	case 1552:
-		processFrontStrainGauges1Data(decoded)
-	case 1553:
-		processFrontStrainGauges2Data(decoded)
-	case 1554:
-		processRearStrainGauges1Data(decoded)
-	case 1555:
-		processRearStrainGauges2Data(decoded)
-	case 1680:
-		processPDMReTransmitData(decoded)
-	default:
-		// Unrecognized frame; no action taken.
+	if handle, ok := frameHandlers[frameID]; ok {
+		handle(frameID, frame, cellDataBuffers)
 	}
 }
 
 // --- Processing Functions ---
 
-func processRearStrainGauges2Data(decoded map[string]string) {
+func processRearStrainGauges2Data(frame *candecoder.DecodedFrame) {
 	d := types.RearStrainGauges2_Data{
-		Timestamp: time.Now(),
-		Gauge1:    utils.ParseIntSignal(decoded, "Gauge1"),
-		Gauge2:    utils.ParseIntSignal(decoded, "Gauge2"),
-		Gauge3:    utils.ParseIntSignal(decoded, "Gauge3"),
-		Gauge4:    utils.ParseIntSignal(decoded, "Gauge4"),
-		Gauge5:    utils.ParseIntSignal(decoded, "Gauge5"),
-		Gauge6:    utils.ParseIntSignal(decoded, "Gauge6"),
-	}
-	if err := db.New(db.DB).InsertRearStrainGauges2Data(context.Background(), d); err != nil {
+		Timestamp: frame.Time(),
+		Gauge1:    frame.Int("Gauge1"),
+		Gauge2:    frame.Int("Gauge2"),
+		Gauge3:    frame.Int("Gauge3"),
+		Gauge4:    frame.Int("Gauge4"),
+		Gauge5:    frame.Int("Gauge5"),
+		Gauge6:    frame.Int("Gauge6"),
+	}
+	if err := db.New(db.DB).InsertRearStrainGauges2Data(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -179,20 +256,20 @@ func processRearStrainGauges2Data(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processRearStrainGauges1Data(decoded map[string]string) {
+func processRearStrainGauges1Data(frame *candecoder.DecodedFrame) {
 	d := types.RearStrainGauges1_Data{
-		Timestamp: time.Now(),
-		Gauge1:    utils.ParseIntSignal(decoded, "Gauge1"),
-		Gauge2:    utils.ParseIntSignal(decoded, "Gauge2"),
-		Gauge3:    utils.ParseIntSignal(decoded, "Gauge3"),
-		Gauge4:    utils.ParseIntSignal(decoded, "Gauge4"),
-		Gauge5:    utils.ParseIntSignal(decoded, "Gauge5"),
-		Gauge6:    utils.ParseIntSignal(decoded, "Gauge6"),
-	}
-	if err := db.New(db.DB).InsertRearStrainGauges1Data(context.Background(), d); err != nil {
+		Timestamp: frame.Time(),
+		Gauge1:    frame.Int("Gauge1"),
+		Gauge2:    frame.Int("Gauge2"),
+		Gauge3:    frame.Int("Gauge3"),
+		Gauge4:    frame.Int("Gauge4"),
+		Gauge5:    frame.Int("Gauge5"),
+		Gauge6:    frame.Int("Gauge6"),
+	}
+	if err := db.New(db.DB).InsertRearStrainGauges1Data(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -208,20 +285,20 @@ func processRearStrainGauges1Data(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processBamocarRxData(decoded map[string]string) {
+func processBamocarRxData(frame *candecoder.DecodedFrame) {
 	data := types.BamocarRxData_Data{
-		Timestamp: time.Now(),
-		REGID:     utils.ParseIntSignal(decoded, "REGID"),
-		Byte1:     utils.ParseIntSignal(decoded, "Byte1"),
-		Byte2:     utils.ParseIntSignal(decoded, "Byte2"),
-		Byte3:     utils.ParseIntSignal(decoded, "Byte3"),
-		Byte4:     utils.ParseIntSignal(decoded, "Byte4"),
-		Byte5:     utils.ParseIntSignal(decoded, "Byte5"),
-	}
-	if err := db.New(db.DB).InsertBamocarRxData(context.Background(), data); err != nil {
+		Timestamp: frame.Time(),
+		REGID:     frame.Int("REGID"),
+		Byte1:     frame.Int("Byte1"),
+		Byte2:     frame.Int("Byte2"),
+		Byte3:     frame.Int("Byte3"),
+		Byte4:     frame.Int("Byte4"),
+		Byte5:     frame.Int("Byte5"),
+	}
+	if err := db.New(db.DB).InsertBamocarRxData(context.Background(), data, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -237,20 +314,20 @@ func processBamocarRxData(decoded map[string]string) {
 		},
 		"time": data.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processRearAeroData(decoded map[string]string) {
+func processRearAeroData(frame *candecoder.DecodedFrame) {
 	rearAero := types.RearAero_Data{
-		Timestamp:    time.Now(),
-		Pressure1:    utils.ParseIntSignal(decoded, "Pressure1"),
-		Pressure2:    utils.ParseIntSignal(decoded, "Pressure2"),
-		Pressure3:    utils.ParseIntSignal(decoded, "Pressure3"),
-		Temperature1: utils.ParseIntSignal(decoded, "Temperature1"),
-		Temperature2: utils.ParseIntSignal(decoded, "Temperature2"),
-		Temperature3: utils.ParseIntSignal(decoded, "Temperature3"),
-	}
-	if err := db.New(db.DB).InsertRearAeroData(context.Background(), rearAero); err != nil {
+		Timestamp:    frame.Time(),
+		Pressure1:    frame.Int("Pressure1"),
+		Pressure2:    frame.Int("Pressure2"),
+		Pressure3:    frame.Int("Pressure3"),
+		Temperature1: frame.Int("Temperature1"),
+		Temperature2: frame.Int("Temperature2"),
+		Temperature3: frame.Int("Temperature3"),
+	}
+	if err := db.New(db.DB).InsertRearAeroData(context.Background(), rearAero, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -266,22 +343,22 @@ func processRearAeroData(decoded map[string]string) {
 		},
 		"time": rearAero.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processRearAnalogData(decoded map[string]string) {
+func processRearAnalogData(frame *candecoder.DecodedFrame) {
 	rearAnalog := types.RearAnalog_Data{
-		Timestamp: time.Now(),
-		Analog1:   utils.ParseIntSignal(decoded, "Analog1"),
-		Analog2:   utils.ParseIntSignal(decoded, "Analog2"),
-		Analog3:   utils.ParseIntSignal(decoded, "Analog3"),
-		Analog4:   utils.ParseIntSignal(decoded, "Analog4"),
-		Analog5:   utils.ParseIntSignal(decoded, "Analog5"),
-		Analog6:   utils.ParseIntSignal(decoded, "Analog6"),
-		Analog7:   utils.ParseIntSignal(decoded, "Analog7"),
-		Analog8:   utils.ParseIntSignal(decoded, "Analog8"),
-	}
-	if err := db.New(db.DB).InsertRearAnalogData(context.Background(), rearAnalog); err != nil {
+		Timestamp: frame.Time(),
+		Analog1:   frame.Int("Analog1"),
+		Analog2:   frame.Int("Analog2"),
+		Analog3:   frame.Int("Analog3"),
+		Analog4:   frame.Int("Analog4"),
+		Analog5:   frame.Int("Analog5"),
+		Analog6:   frame.Int("Analog6"),
+		Analog7:   frame.Int("Analog7"),
+		Analog8:   frame.Int("Analog8"),
+	}
+	if err := db.New(db.DB).InsertRearAnalogData(context.Background(), rearAnalog, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -299,18 +376,18 @@ func processRearAnalogData(decoded map[string]string) {
 		},
 		"time": rearAnalog.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processRearFrequencyData(decoded map[string]string) {
+func processRearFrequencyData(frame *candecoder.DecodedFrame) {
 	d := types.RearFrequency_Data{
-		Timestamp: time.Now(),
-		Freq1:     utils.ParseFloatSignal(decoded, "Freq1"),
-		Freq2:     utils.ParseFloatSignal(decoded, "Freq2"),
-		Freq3:     utils.ParseFloatSignal(decoded, "Freq3"),
-		Freq4:     utils.ParseFloatSignal(decoded, "Freq4"),
+		Timestamp: frame.Time(),
+		Freq1:     frame.Float("Freq1"),
+		Freq2:     frame.Float("Freq2"),
+		Freq3:     frame.Float("Freq3"),
+		Freq4:     frame.Float("Freq4"),
 	}
-	if err := db.New(db.DB).InsertRearFrequencyData(context.Background(), d); err != nil {
+	if err := db.New(db.DB).InsertRearFrequencyData(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -324,20 +401,20 @@ func processRearFrequencyData(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processFrontAeroData(decoded map[string]string) {
+func processFrontAeroData(frame *candecoder.DecodedFrame) {
 	fa := types.FrontAero_Data{
-		Timestamp:    time.Now(),
-		Pressure1:    utils.ParseIntSignal(decoded, "Pressure1"),
-		Pressure2:    utils.ParseIntSignal(decoded, "Pressure2"),
-		Pressure3:    utils.ParseIntSignal(decoded, "Pressure3"),
-		Temperature1: utils.ParseIntSignal(decoded, "Temperature1"),
-		Temperature2: utils.ParseIntSignal(decoded, "Temperature2"),
-		Temperature3: utils.ParseIntSignal(decoded, "Temperature3"),
-	}
-	if err := db.New(db.DB).InsertFrontAeroData(context.Background(), fa); err != nil {
+		Timestamp:    frame.Time(),
+		Pressure1:    frame.Int("Pressure1"),
+		Pressure2:    frame.Int("Pressure2"),
+		Pressure3:    frame.Int("Pressure3"),
+		Temperature1: frame.Int("Temperature1"),
+		Temperature2: frame.Int("Temperature2"),
+		Temperature3: frame.Int("Temperature3"),
+	}
+	if err := db.New(db.DB).InsertFrontAeroData(context.Background(), fa, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -353,21 +430,22 @@ func processFrontAeroData(decoded map[string]string) {
 		},
 		"time": fa.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processPDM1Data(decoded map[string]string) {
+func processPDM1Data(frame *candecoder.DecodedFrame) {
 	pdm1 := types.PDM1_Data{
-		Timestamp:           time.Now(),
-		CompoundID:          utils.ParseIntSignal(decoded, "CompoundID"),
-		PDMIntTemperature:   utils.ParseIntSignal(decoded, "PDMIntTemperature"),
-		PDMBattVoltage:      utils.ParseFloatSignal(decoded, "PDMBattVoltage"),
-		GlobalErrorFlag:     utils.ParseIntSignal(decoded, "GlobalErrorFlag"),
-		TotalCurrent:        utils.ParseIntSignal(decoded, "TotalCurrent"),
-		InternalRailVoltage: utils.ParseFloatSignal(decoded, "InternalRailVoltage"),
-		ResetSource:         utils.ParseIntSignal(decoded, "ResetSource"),
-	}
-	if err := db.New(db.DB).InsertPDM1Data(context.Background(), pdm1); err != nil {
+		Timestamp:           frame.Time(),
+		CompoundID:          frame.Int("CompoundID"),
+		PDMIntTemperature:   frame.Int("PDMIntTemperature"),
+		PDMBattVoltage:      frame.Float("PDMBattVoltage"),
+		GlobalErrorFlag:     frame.Int("GlobalErrorFlag"),
+		TotalCurrent:        frame.Int("TotalCurrent"),
+		InternalRailVoltage: frame.Float("InternalRailVoltage"),
+		ResetSource:         frame.Int("ResetSource"),
+		ResetSourceLabel:    frame.Label("ResetSource"),
+	}
+	if err := db.New(db.DB).InsertPDM1Data(context.Background(), pdm1, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -381,93 +459,113 @@ func processPDM1Data(decoded map[string]string) {
 			"total_current":         pdm1.TotalCurrent,
 			"internal_rail_voltage": pdm1.InternalRailVoltage,
 			"reset_source":          pdm1.ResetSource,
+			"reset_source_label":    pdm1.ResetSourceLabel,
 		},
 		"time": pdm1.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
 func processCellData(
-	frameID uint32,
-	decoded map[string]string,
+	frameID types.CANID,
+	frame *candecoder.DecodedFrame,
 	cellDataBuffers map[float64]*types.Cell_Data,
 ) {
+	cellMu.Lock()
+	defer cellMu.Unlock()
 	// Use key 0 as the aggregator.
 	if _, ok := cellDataBuffers[0]; !ok {
 		cellDataBuffers[0] = &types.Cell_Data{}
 	}
 	agg := cellDataBuffers[0]
-	for sigName := range decoded {
-		if strings.HasPrefix(sigName, "Cell") {
-			idxStr := sigName[4:]
-			if idx, err := utils.AtoiSafe(idxStr); err == nil && idx >= 1 && idx <= 128 {
-				val := utils.ParseFloatSignal(decoded, sigName)
-				setCellValue(agg, idx, val)
-			}
-		}
-	}
+	addCellValues(agg, frame)
 	if frameID == 57 {
-		agg.Timestamp = time.Now()
-		if err := db.InsertCellData(context.Background(), *agg); err == nil {
+		agg.Timestamp = frame.Time()
+		if err := insertCellData(context.Background(), *agg, agg.Nulls...); err == nil {
 			broadcastCells(agg)
 		}
 		delete(cellDataBuffers, 0)
 	}
 }
 
-func processGPSBestPosData(decoded map[string]string) {
+// addCellValues stores the CellN signals of a frame in the aggregator,
+// taking each cell's index from its signal name. Invalid cells are left at 0
+// and listed in Nulls; out-of-range ones are listed in OutOfRange.
+func addCellValues(agg *types.Cell_Data, frame *candecoder.DecodedFrame) {
+	for _, sv := range frame.Signals {
+		if !strings.HasPrefix(sv.Name, "Cell") {
+			continue
+		}
+		idx, err := utils.AtoiSafe(strings.TrimPrefix(sv.Name, "Cell"))
+		if err != nil || idx < 1 || idx > 128 {
+			continue
+		}
+		if sv.OutOfRange {
+			agg.OutOfRange = append(agg.OutOfRange, sv.Name)
+		}
+		if !sv.Valid {
+			agg.Nulls = append(agg.Nulls, sv.Name)
+			continue
+		}
+		setCellValue(agg, idx, sv.Float)
+	}
+}
+
+func processGPSBestPosData(frame *candecoder.DecodedFrame) {
 	gps := types.GPSBestPos_Data{
-		Timestamp:    time.Now(),
-		Latitude:     utils.ParseFloatSignal(decoded, "Latitude"),
-		Longitude:    utils.ParseFloatSignal(decoded, "Longitude"),
-		Altitude:     utils.ParseFloatSignal(decoded, "Altitude"),
-		StdLatitude:  utils.ParseFloatSignal(decoded, "stdLatitude"),
-		StdLongitude: utils.ParseFloatSignal(decoded, "stdLongitude"),
-		StdAltitude:  utils.ParseFloatSignal(decoded, "stdAltitude"),
-		GPSStatus:    utils.ParseIntSignal(decoded, "gpsStatus"),
-	}
-	if err := db.New(db.DB).InsertGPSBestPosData(context.Background(), gps); err != nil {
+		Timestamp:      frame.Time(),
+		Latitude:       frame.Float("Latitude"),
+		Longitude:      frame.Float("Longitude"),
+		Altitude:       frame.Float("Altitude"),
+		StdLatitude:    frame.Float("stdLatitude"),
+		StdLongitude:   frame.Float("stdLongitude"),
+		StdAltitude:    frame.Float("stdAltitude"),
+		GPSStatus:      frame.Int("gpsStatus"),
+		GPSStatusLabel: frame.Label("gpsStatus"),
+	}
+	if err := db.New(db.DB).InsertGPSBestPosData(context.Background(), gps, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
 		"type": "gps_best_pos",
 		"payload": map[string]interface{}{
-			"timestamp":     gps.Timestamp.Unix(),
-			"latitude":      gps.Latitude,
-			"longitude":     gps.Longitude,
-			"altitude":      gps.Altitude,
-			"std_latitude":  gps.StdLatitude,
-			"std_longitude": gps.StdLongitude,
-			"std_altitude":  gps.StdAltitude,
-			"gps_status":    gps.GPSStatus,
+			"timestamp":        gps.Timestamp.Unix(),
+			"latitude":         gps.Latitude,
+			"longitude":        gps.Longitude,
+			"altitude":         gps.Altitude,
+			"std_latitude":     gps.StdLatitude,
+			"std_longitude":    gps.StdLongitude,
+			"std_altitude":     gps.StdAltitude,
+			"gps_status":       gps.GPSStatus,
+			"gps_status_label": gps.GPSStatusLabel,
 		},
 		"time": gps.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processThermData(decoded map[string]string, thermID int) {
+func processThermData(frame *candecoder.DecodedFrame, thermID int) {
 	t := types.Therm_Data{
-		Timestamp:    time.Now(),
+		Timestamp:    frame.Time(),
 		ThermistorID: thermID,
-		Therm1:       utils.ParseFloatSignal(decoded, "Therm1"),
-		Therm2:       utils.ParseFloatSignal(decoded, "Therm2"),
-		Therm3:       utils.ParseFloatSignal(decoded, "Therm3"),
-		Therm4:       utils.ParseFloatSignal(decoded, "Therm4"),
-		Therm5:       utils.ParseFloatSignal(decoded, "Therm5"),
-		Therm6:       utils.ParseFloatSignal(decoded, "Therm6"),
-		Therm7:       utils.ParseFloatSignal(decoded, "Therm7"),
-		Therm8:       utils.ParseFloatSignal(decoded, "Therm8"),
-		Therm9:       utils.ParseFloatSignal(decoded, "Therm9"),
-		Therm10:      utils.ParseFloatSignal(decoded, "Therm10"),
-		Therm11:      utils.ParseFloatSignal(decoded, "Therm11"),
-		Therm12:      utils.ParseFloatSignal(decoded, "Therm12"),
-		Therm13:      utils.ParseFloatSignal(decoded, "Therm13"),
-		Therm14:      utils.ParseFloatSignal(decoded, "Therm14"),
-		Therm15:      utils.ParseFloatSignal(decoded, "Therm15"),
-		Therm16:      utils.ParseFloatSignal(decoded, "Therm16"),
-	}
-	if err := db.New(db.DB).InsertThermData(context.Background(), t); err != nil {
+		Therm1:       frame.Float("Therm1"),
+		Therm2:       frame.Float("Therm2"),
+		Therm3:       frame.Float("Therm3"),
+		Therm4:       frame.Float("Therm4"),
+		Therm5:       frame.Float("Therm5"),
+		Therm6:       frame.Float("Therm6"),
+		Therm7:       frame.Float("Therm7"),
+		Therm8:       frame.Float("Therm8"),
+		Therm9:       frame.Float("Therm9"),
+		Therm10:      frame.Float("Therm10"),
+		Therm11:      frame.Float("Therm11"),
+		Therm12:      frame.Float("Therm12"),
+		Therm13:      frame.Float("Therm13"),
+		Therm14:      frame.Float("Therm14"),
+		Therm15:      frame.Float("Therm15"),
+		Therm16:      frame.Float("Therm16"),
+	}
+	if err := db.New(db.DB).InsertThermData(context.Background(), t, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -494,15 +592,15 @@ func processThermData(decoded map[string]string, thermID int) {
 		},
 		"time": t.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processACULV2Data(decoded map[string]string) {
+func processACULV2Data(frame *candecoder.DecodedFrame) {
 	aculv2 := types.ACULV2_Data{
-		Timestamp:     time.Now(),
-		ChargeRequest: utils.ParseIntSignal(decoded, "ChargeRequest"),
+		Timestamp:     frame.Time(),
+		ChargeRequest: frame.Int("ChargeRequest"),
 	}
-	if err := db.New(db.DB).InsertACULV2Data(context.Background(), aculv2); err != nil {
+	if err := db.New(db.DB).InsertACULV2Data(context.Background(), aculv2, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -513,18 +611,18 @@ func processACULV2Data(decoded map[string]string) {
 		},
 		"time": aculv2.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processTCUData(decoded map[string]string) {
+func processTCUData(frame *candecoder.DecodedFrame) {
 	t := types.TCU_Data{
-		Timestamp: time.Now(),
-		APPS1:     utils.ParseFloatSignal(decoded, "APPS1"),
-		APPS2:     utils.ParseFloatSignal(decoded, "APPS2"),
-		BSE:       utils.ParseFloatSignal(decoded, "BSE"),
-		Status:    utils.ParseIntSignal(decoded, "Status"),
+		Timestamp: frame.Time(),
+		APPS1:     frame.Float("APPS1"),
+		APPS2:     frame.Float("APPS2"),
+		BSE:       frame.Float("BSE"),
+		Status:    frame.Int("Status"),
 	}
-	db.New(db.DB).InsertTCUData(context.Background(), t)
+	db.New(db.DB).InsertTCUData(context.Background(), t, nullColumns(frame)...)
 	payload := map[string]interface{}{
 		"type": "tcu",
 		"payload": map[string]interface{}{
@@ -536,16 +634,16 @@ func processTCUData(decoded map[string]string) {
 		},
 		"time": t.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processACULVFD2Data(decoded map[string]string) {
+func processACULVFD2Data(frame *candecoder.DecodedFrame) {
 	aculv2 := types.ACULV_FD_2_Data{
-		Timestamp:   time.Now(),
-		FanSetPoint: utils.ParseFloatSignal(decoded, "FanSetPoint"),
-		RPM:         utils.ParseFloatSignal(decoded, "RPM"),
+		Timestamp:   frame.Time(),
+		FanSetPoint: frame.Float("FanSetPoint"),
+		RPM:         frame.Float("RPM"),
 	}
-	if err := db.New(db.DB).InsertACULV_FD_2_Data(context.Background(), aculv2); err != nil {
+	if err := db.New(db.DB).InsertACULV_FD_2_Data(context.Background(), aculv2, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -557,16 +655,16 @@ func processACULVFD2Data(decoded map[string]string) {
 		},
 		"time": aculv2.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processACULV1Data(decoded map[string]string) {
+func processACULV1Data(frame *candecoder.DecodedFrame) {
 	aculv1 := types.ACULV1_Data{
-		Timestamp:     time.Now(),
-		ChargeStatus1: utils.ParseFloatSignal(decoded, "ChargeStatus1"),
-		ChargeStatus2: utils.ParseFloatSignal(decoded, "ChargeStatus2"),
+		Timestamp:     frame.Time(),
+		ChargeStatus1: frame.Float("ChargeStatus1"),
+		ChargeStatus2: frame.Float("ChargeStatus2"),
 	}
-	if err := db.New(db.DB).InsertACULV1Data(context.Background(), aculv1); err != nil {
+	if err := db.New(db.DB).InsertACULV1Data(context.Background(), aculv1, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -578,22 +676,23 @@ func processACULV1Data(decoded map[string]string) {
 		},
 		"time": aculv1.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processACULVFD1Data(decoded map[string]string) {
+func processACULVFD1Data(frame *candecoder.DecodedFrame) {
 	aculv := types.ACULV_FD_1_Data{
-		Timestamp:            time.Now(),
-		AMSStatus:            utils.ParseIntSignal(decoded, "AMSStatus"),
-		FLD:                  utils.ParseIntSignal(decoded, "FLD"),
-		StateOfCharge:        utils.ParseFloatSignal(decoded, "StateOfCharge"),
-		AccumulatorVoltage:   utils.ParseFloatSignal(decoded, "AccumulatorVoltage"),
-		TractiveVoltage:      utils.ParseFloatSignal(decoded, "TractiveVoltage"),
-		CellCurrent:          utils.ParseFloatSignal(decoded, "CellCurrent"),
-		IsolationMonitoring:  utils.ParseIntSignal(decoded, "IsolationMonitoring"),
-		IsolationMonitoring1: utils.ParseFloatSignal(decoded, "IsolationMonitoring1"),
-	}
-	if err := db.New(db.DB).InsertACULV_FD_1_Data(context.Background(), aculv); err != nil {
+		Timestamp:            frame.Time(),
+		AMSStatus:            frame.Int("AMSStatus"),
+		AMSStatusLabel:       frame.Label("AMSStatus"),
+		FLD:                  frame.Int("FLD"),
+		StateOfCharge:        frame.Float("StateOfCharge"),
+		AccumulatorVoltage:   frame.Float("AccumulatorVoltage"),
+		TractiveVoltage:      frame.Float("TractiveVoltage"),
+		CellCurrent:          frame.Float("CellCurrent"),
+		IsolationMonitoring:  frame.Int("IsolationMonitoring"),
+		IsolationMonitoring1: frame.Float("IsolationMonitoring1"),
+	}
+	if err := db.New(db.DB).InsertACULV_FD_1_Data(context.Background(), aculv, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -601,6 +700,7 @@ func processACULVFD1Data(decoded map[string]string) {
 		"payload": map[string]interface{}{
 			"timestamp":             aculv.Timestamp.Unix(),
 			"ams_status":            aculv.AMSStatus,
+			"ams_status_label":      aculv.AMSStatusLabel,
 			"fld":                   aculv.FLD,
 			"state_of_charge":       aculv.StateOfCharge,
 			"accumulator_voltage":   aculv.AccumulatorVoltage,
@@ -611,15 +711,15 @@ func processACULVFD1Data(decoded map[string]string) {
 		},
 		"time": aculv.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processPackCurrentData(decoded map[string]string) {
+func processPackCurrentData(frame *candecoder.DecodedFrame) {
 	d := types.PackCurrent_Data{
-		Timestamp: time.Now(),
-		Current:   utils.ParseFloatSignal(decoded, "PackCurrent"),
+		Timestamp: frame.Time(),
+		Current:   frame.Float("PackCurrent"),
 	}
-	if err := db.InsertPackCurrentData(context.Background(), d); err != nil {
+	if err := db.InsertPackCurrentData(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -630,15 +730,15 @@ func processPackCurrentData(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processPackVoltageData(decoded map[string]string) {
+func processPackVoltageData(frame *candecoder.DecodedFrame) {
 	d := types.PackVoltage_Data{
-		Timestamp: time.Now(),
-		Voltage:   utils.ParseFloatSignal(decoded, "PackVoltage"),
+		Timestamp: frame.Time(),
+		Voltage:   frame.Float("PackVoltage"),
 	}
-	if err := db.InsertPackVoltageData(context.Background(), d); err != nil {
+	if err := db.InsertPackVoltageData(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -649,17 +749,17 @@ func processPackVoltageData(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processBamocarData(decoded map[string]string) {
+func processBamocarData(frame *candecoder.DecodedFrame) {
 	b := types.TCU2_data{
-		Timestamp:  time.Now(),
-		BamocarFRG: utils.ParseIntSignal(decoded, "BamocarFRG"),
-		BamocarRFE: utils.ParseIntSignal(decoded, "BamocarRFE"),
-		BrakeLight: utils.ParseIntSignal(decoded, "BrakeLight"),
+		Timestamp:  frame.Time(),
+		BamocarFRG: frame.Int("BamocarFRG"),
+		BamocarRFE: frame.Int("BamocarRFE"),
+		BrakeLight: frame.Int("BrakeLight"),
 	}
-	if err := db.InsertBamocarData(context.Background(), b); err != nil {
+	if err := db.InsertBamocarData(context.Background(), b, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -672,19 +772,19 @@ func processBamocarData(decoded map[string]string) {
 		},
 		"time": b.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processINS_GPS_Data(decoded map[string]string) {
+func processINS_GPS_Data(frame *candecoder.DecodedFrame) {
 	data := types.INS_GPS_Data{
-		Timestamp:   time.Now(),
-		GNSSWeek:    utils.ParseIntSignal(decoded, "gnss_week"),
-		GNSSSeconds: utils.ParseFloatSignal(decoded, "gnss_seconds"),
-		GNSSLat:     utils.ParseFloatSignal(decoded, "gnss_lat"),
-		GNSSLong:    utils.ParseFloatSignal(decoded, "gnss_long"),
-		GNSSHeight:  utils.ParseFloatSignal(decoded, "gnss_height"),
-	}
-	if err := db.InsertINS_GPS_Data(context.Background(), data); err != nil {
+		Timestamp:   frame.Time(),
+		GNSSWeek:    frame.Int("gnss_week"),
+		GNSSSeconds: frame.Float("gnss_seconds"),
+		GNSSLat:     frame.Float("gnss_lat"),
+		GNSSLong:    frame.Float("gnss_long"),
+		GNSSHeight:  frame.Float("gnss_height"),
+	}
+	if err := db.InsertINS_GPS_Data(context.Background(), data, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -699,21 +799,21 @@ func processINS_GPS_Data(decoded map[string]string) {
 		},
 		"time": data.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processINS_IMUData(decoded map[string]string) {
+func processINS_IMUData(frame *candecoder.DecodedFrame) {
 	data := types.INS_IMU_Data{
-		Timestamp: time.Now(),
-		NorthVel:  utils.ParseFloatSignal(decoded, "north_vel"),
-		EastVel:   utils.ParseFloatSignal(decoded, "east_vel"),
-		UpVel:     utils.ParseFloatSignal(decoded, "up_vel"),
-		Roll:      utils.ParseFloatSignal(decoded, "roll"),
-		Pitch:     utils.ParseFloatSignal(decoded, "pitch"),
-		Azimuth:   utils.ParseFloatSignal(decoded, "azimuth"),
-		Status:    utils.ParseIntSignal(decoded, "status"),
-	}
-	if err := db.InsertINS_IMUData(context.Background(), data); err != nil {
+		Timestamp: frame.Time(),
+		NorthVel:  frame.Float("north_vel"),
+		EastVel:   frame.Float("east_vel"),
+		UpVel:     frame.Float("up_vel"),
+		Roll:      frame.Float("roll"),
+		Pitch:     frame.Float("pitch"),
+		Azimuth:   frame.Float("azimuth"),
+		Status:    frame.Int("status"),
+	}
+	if err := db.InsertINS_IMUData(context.Background(), data, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -730,18 +830,18 @@ func processINS_IMUData(decoded map[string]string) {
 		},
 		"time": data.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processFrontFrequencyData(decoded map[string]string) {
+func processFrontFrequencyData(frame *candecoder.DecodedFrame) {
 	d := types.FrontFrequency_Data{
-		Timestamp:  time.Now(),
-		RearRight:  utils.ParseFloatSignal(decoded, "RearRight"),
-		FrontRight: utils.ParseFloatSignal(decoded, "FrontRight"),
-		RearLeft:   utils.ParseFloatSignal(decoded, "RearLeft"),
-		FrontLeft:  utils.ParseFloatSignal(decoded, "FrontLeft"),
+		Timestamp:  frame.Time(),
+		RearRight:  frame.Float("RearRight"),
+		FrontRight: frame.Float("FrontRight"),
+		RearLeft:   frame.Float("RearLeft"),
+		FrontLeft:  frame.Float("FrontLeft"),
 	}
-	if err := db.InsertFrontFrequencyData(context.Background(), d); err != nil {
+	if err := db.InsertFrontFrequencyData(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -755,22 +855,22 @@ func processFrontFrequencyData(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processFrontAnalogData(decoded map[string]string) {
+func processFrontAnalogData(frame *candecoder.DecodedFrame) {
 	d := types.FrontAnalog_Data{
-		Timestamp:     time.Now(),
-		LeftRad:       utils.ParseIntSignal(decoded, "LeftRad"),
-		RightRad:      utils.ParseIntSignal(decoded, "RightRad"),
-		FrontRightPot: utils.ParseFloatSignal(decoded, "FrontRightPot"),
-		FrontLeftPot:  utils.ParseFloatSignal(decoded, "FrontLeftPot"),
-		RearRightPot:  utils.ParseFloatSignal(decoded, "RearRightPot"),
-		RearLeftPot:   utils.ParseFloatSignal(decoded, "RearLeftPot"),
-		SteeringAngle: utils.ParseFloatSignal(decoded, "SteeringAngle"),
-		Analog8:       utils.ParseIntSignal(decoded, "Analog8"),
-	}
-	if err := db.InsertFrontAnalogData(context.Background(), d); err != nil {
+		Timestamp:     frame.Time(),
+		LeftRad:       frame.Int("LeftRad"),
+		RightRad:      frame.Int("RightRad"),
+		FrontRightPot: frame.Float("FrontRightPot"),
+		FrontLeftPot:  frame.Float("FrontLeftPot"),
+		RearRightPot:  frame.Float("RearRightPot"),
+		RearLeftPot:   frame.Float("RearLeftPot"),
+		SteeringAngle: frame.Float("SteeringAngle"),
+		Analog8:       frame.Int("Analog8"),
+	}
+	if err := db.InsertFrontAnalogData(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -788,16 +888,16 @@ func processFrontAnalogData(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processBamocarTxData(decoded map[string]string) {
+func processBamocarTxData(frame *candecoder.DecodedFrame) {
 	d := types.BamocarTxData_Data{
-		Timestamp: time.Now(),
-		REGID:     utils.ParseIntSignal(decoded, "REGID"),
-		Data:      utils.ParseIntSignal(decoded, "Data"),
+		Timestamp: frame.Time(),
+		REGID:     frame.Int("REGID"),
+		Data:      frame.Int("Data"),
 	}
-	if err := db.InsertBamocarTxData(context.Background(), d); err != nil {
+	if err := db.InsertBamocarTxData(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -809,16 +909,16 @@ func processBamocarTxData(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processBamoCarReTransmitData(decoded map[string]string) {
+func processBamoCarReTransmitData(frame *candecoder.DecodedFrame) {
 	d := types.BamoCarReTransmit_Data{
-		Timestamp:      time.Now(),
-		MotorTemp:      utils.ParseIntSignal(decoded, "MotorTemp"),
-		ControllerTemp: utils.ParseIntSignal(decoded, "ControllerTemp"),
+		Timestamp:      frame.Time(),
+		MotorTemp:      frame.Int("MotorTemp"),
+		ControllerTemp: frame.Int("ControllerTemp"),
 	}
-	if err := db.InsertBamoCarReTransmitData(context.Background(), d); err != nil {
+	if err := db.InsertBamoCarReTransmitData(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -830,18 +930,18 @@ func processBamoCarReTransmitData(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processEncoderData(decoded map[string]string) {
+func processEncoderData(frame *candecoder.DecodedFrame) {
 	d := types.Encoder_Data{
-		Timestamp: time.Now(),
-		Encoder1:  utils.ParseIntSignal(decoded, "Encoder1"),
-		Encoder2:  utils.ParseIntSignal(decoded, "Encoder2"),
-		Encoder3:  utils.ParseIntSignal(decoded, "Encoder3"),
-		Encoder4:  utils.ParseIntSignal(decoded, "Encoder4"),
+		Timestamp: frame.Time(),
+		Encoder1:  frame.Int("Encoder1"),
+		Encoder2:  frame.Int("Encoder2"),
+		Encoder3:  frame.Int("Encoder3"),
+		Encoder4:  frame.Int("Encoder4"),
 	}
-	if err := db.InsertEncoderData(context.Background(), d); err != nil {
+	if err := db.InsertEncoderData(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -855,22 +955,22 @@ func processEncoderData(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processPDMCurrentData(decoded map[string]string) {
+func processPDMCurrentData(frame *candecoder.DecodedFrame) {
 	d := types.PDMCurrent_Data{
-		Timestamp:            time.Now(),
-		AccumulatorCurrent:   utils.ParseIntSignal(decoded, "AccumulatorCurrent"),
-		TCUCurrent:           utils.ParseIntSignal(decoded, "TCUCurrent"),
-		BamocarCurrent:       utils.ParseIntSignal(decoded, "BamocarCurrent"),
-		PumpsCurrent:         utils.ParseIntSignal(decoded, "PumpsCurrent"),
-		TSALCurrent:          utils.ParseIntSignal(decoded, "TSALCurrent"),
-		DAQCurrent:           utils.ParseIntSignal(decoded, "DAQCurrent"),
-		DisplayKvaserCurrent: utils.ParseIntSignal(decoded, "DisplayKvaserCurrent"),
-		ShutdownResetCurrent: utils.ParseIntSignal(decoded, "ShutdownResetCurrent"),
-	}
-	if err := db.InsertPDMCurrentData(context.Background(), d); err != nil {
+		Timestamp:            frame.Time(),
+		AccumulatorCurrent:   frame.Int("AccumulatorCurrent"),
+		TCUCurrent:           frame.Int("TCUCurrent"),
+		BamocarCurrent:       frame.Int("BamocarCurrent"),
+		PumpsCurrent:         frame.Int("PumpsCurrent"),
+		TSALCurrent:          frame.Int("TSALCurrent"),
+		DAQCurrent:           frame.Int("DAQCurrent"),
+		DisplayKvaserCurrent: frame.Int("DisplayKvaserCurrent"),
+		ShutdownResetCurrent: frame.Int("ShutdownResetCurrent"),
+	}
+	if err := db.InsertPDMCurrentData(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -888,20 +988,21 @@ func processPDMCurrentData(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processPDMReTransmitData(decoded map[string]string) {
+func processPDMReTransmitData(frame *candecoder.DecodedFrame) {
 	d := types.PDMReTransmit_Data{
-		Timestamp:           time.Now(),
-		PDMIntTemperature:   utils.ParseIntSignal(decoded, "PDMIntTemperature"),
-		PDMBattVoltage:      utils.ParseFloatSignal(decoded, "PDMBattVoltage"),
-		GlobalErrorFlag:     utils.ParseIntSignal(decoded, "GlobalErrorFlag"),
-		TotalCurrent:        utils.ParseIntSignal(decoded, "TotalCurrent"),
-		InternalRailVoltage: utils.ParseFloatSignal(decoded, "InternalRailVoltage"),
-		ResetSource:         utils.ParseIntSignal(decoded, "ResetSource"),
-	}
-	if err := db.InsertPDMReTransmitData(context.Background(), d); err != nil {
+		Timestamp:           frame.Time(),
+		PDMIntTemperature:   frame.Int("PDMIntTemperature"),
+		PDMBattVoltage:      frame.Float("PDMBattVoltage"),
+		GlobalErrorFlag:     frame.Int("GlobalErrorFlag"),
+		TotalCurrent:        frame.Int("TotalCurrent"),
+		InternalRailVoltage: frame.Float("InternalRailVoltage"),
+		ResetSource:         frame.Int("ResetSource"),
+		ResetSourceLabel:    frame.Label("ResetSource"),
+	}
+	if err := db.InsertPDMReTransmitData(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -914,23 +1015,24 @@ func processPDMReTransmitData(decoded map[string]string) {
 			"total_current":         d.TotalCurrent,
 			"internal_rail_voltage": d.InternalRailVoltage,
 			"reset_source":          d.ResetSource,
+			"reset_source_label":    d.ResetSourceLabel,
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processFrontStrainGauges1Data(decoded map[string]string) {
+func processFrontStrainGauges1Data(frame *candecoder.DecodedFrame) {
 	d := types.FrontStrainGauges1_Data{
-		Timestamp: time.Now(),
-		Gauge1:    utils.ParseIntSignal(decoded, "Gauge1"),
-		Gauge2:    utils.ParseIntSignal(decoded, "Gauge2"),
-		Gauge3:    utils.ParseIntSignal(decoded, "Gauge3"),
-		Gauge4:    utils.ParseIntSignal(decoded, "Gauge4"),
-		Gauge5:    utils.ParseIntSignal(decoded, "Gauge5"),
-		Gauge6:    utils.ParseIntSignal(decoded, "Gauge6"),
-	}
-	if err := db.InsertFrontStrainGauges1Data(context.Background(), d); err != nil {
+		Timestamp: frame.Time(),
+		Gauge1:    frame.Int("Gauge1"),
+		Gauge2:    frame.Int("Gauge2"),
+		Gauge3:    frame.Int("Gauge3"),
+		Gauge4:    frame.Int("Gauge4"),
+		Gauge5:    frame.Int("Gauge5"),
+		Gauge6:    frame.Int("Gauge6"),
+	}
+	if err := db.InsertFrontStrainGauges1Data(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -946,20 +1048,20 @@ func processFrontStrainGauges1Data(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
-func processFrontStrainGauges2Data(decoded map[string]string) {
+func processFrontStrainGauges2Data(frame *candecoder.DecodedFrame) {
 	d := types.FrontStrainGauges2_Data{
-		Timestamp: time.Now(),
-		Gauge1:    utils.ParseIntSignal(decoded, "Gauge1"),
-		Gauge2:    utils.ParseIntSignal(decoded, "Gauge2"),
-		Gauge3:    utils.ParseIntSignal(decoded, "Gauge3"),
-		Gauge4:    utils.ParseIntSignal(decoded, "Gauge4"),
-		Gauge5:    utils.ParseIntSignal(decoded, "Gauge5"),
-		Gauge6:    utils.ParseIntSignal(decoded, "Gauge6"),
-	}
-	if err := db.InsertFrontStrainGauges2Data(context.Background(), d); err != nil {
+		Timestamp: frame.Time(),
+		Gauge1:    frame.Int("Gauge1"),
+		Gauge2:    frame.Int("Gauge2"),
+		Gauge3:    frame.Int("Gauge3"),
+		Gauge4:    frame.Int("Gauge4"),
+		Gauge5:    frame.Int("Gauge5"),
+		Gauge6:    frame.Int("Gauge6"),
+	}
+	if err := db.InsertFrontStrainGauges2Data(context.Background(), d, nullColumns(frame)...); err != nil {
 		return
 	}
 	payload := map[string]interface{}{
@@ -975,7 +1077,7 @@ func processFrontStrainGauges2Data(decoded map[string]string) {
 		},
 		"time": d.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
-	broadcastTelemetry(payload)
+	broadcastFrame(frame, payload)
 }
 
 // Helper functions for cell data.
@@ -1247,10 +1349,20 @@ func broadcastCells(agg *types.Cell_Data) {
 		key := "cell" + strconv.Itoa(i)
 		signals[key] = fmt.Sprintf("%.3f", getCellValue(agg, i))
 	}
+	for _, name := range agg.Nulls {
+		signals[strings.ToLower(name)] = nil
+	}
+	if len(agg.OutOfRange) > 0 {
+		outOfRange := make([]interface{}, len(agg.OutOfRange))
+		for i, name := range agg.OutOfRange {
+			outOfRange[i] = strings.ToLower(name)
+		}
+		signals["out_of_range"] = outOfRange
+	}
 	wrapper := map[string]interface{}{
 		"type":    "cell",
 		"payload": signals,
-		"time":    utils.CurrentTimestampString(),
+		"time":    agg.Timestamp.Format("2006-01-02 15:04:05.000"),
 	}
 	broadcastTelemetry(wrapper)
 }
@@ -1519,8 +1631,13 @@ func getCellValue(agg *types.Cell_Data, idx int) float64 {
 }
 
 func HandleRemainingCellData(cellDataBuffers map[float64]*types.Cell_Data) {
+	cellMu.Lock()
+	defer cellMu.Unlock()
 	if agg, ok := cellDataBuffers[0]; ok && agg != nil {
-		if err := db.InsertCellData(context.Background(), *agg); err == nil {
+		if agg.Timestamp.IsZero() {
+			agg.Timestamp = time.Now()
+		}
+		if err := insertCellData(context.Background(), *agg, agg.Nulls...); err == nil {
 			broadcastCells(agg)
 		}
 	}
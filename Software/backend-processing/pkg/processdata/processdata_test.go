@@ -0,0 +1,216 @@
+package processdata
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"telem-system/pkg/candecoder"
+	"telem-system/pkg/db"
+	"telem-system/pkg/types"
+
+	"telem-system/proto"
+
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TestAddCellValuesByName checks that cells are placed by their signal names,
+// whatever order the definition lists them in.
+func TestAddCellValuesByName(t *testing.T) {
+	frame := &candecoder.DecodedFrame{
+		ID: 51,
+		Signals: []candecoder.SignalValue{
+			{Name: "Cell20", Float: 3.20, Valid: true},
+			{Name: "Cell17", Float: 3.17, Valid: true},
+			{Name: "Balancing", Float: 1, Valid: true},
+			{Name: "Cell18", Float: 9.99, Valid: false},
+			{Name: "Cell129", Float: 1.29, Valid: true},
+		},
+	}
+	var agg types.Cell_Data
+	addCellValues(&agg, frame)
+	if agg.Cell17 != 3.17 || agg.Cell20 != 3.20 {
+		t.Errorf("Cell17, Cell20 = %v, %v, want 3.17, 3.2", agg.Cell17, agg.Cell20)
+	}
+	if agg.Cell18 != 0 || agg.Cell1 != 0 || agg.Cell2 != 0 || agg.Cell3 != 0 {
+		t.Errorf("invalid or non-cell signals stored: %+v", agg)
+	}
+}
+
+// capturedPayloads records the payloads broadcast while it is installed.
+func capturedPayloads(t *testing.T) *[]*structpb.Struct {
+	var got []*structpb.Struct
+	BroadcastFunc = func(b []byte) {
+		var msg proto.TelemetryMessage
+		if err := protobuf.Unmarshal(b, &msg); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, msg.Payload)
+	}
+	t.Cleanup(func() { BroadcastFunc = nil })
+	return &got
+}
+
+// outOfRange returns the out_of_range list of a broadcast payload.
+func outOfRange(p *structpb.Struct) []string {
+	var names []string
+	for _, v := range p.Fields["out_of_range"].GetListValue().GetValues() {
+		names = append(names, v.GetStringValue())
+	}
+	return names
+}
+
+// TestCellDataRangePolicy checks the cell_data row inserted and broadcast for
+// an out-of-range cell voltage under each range policy.
+func TestCellDataRangePolicy(t *testing.T) {
+	minV, maxV := 0.0, 5.0
+	msg := types.Message{
+		FrameID: 50,
+		Name:    "CellVoltage1",
+		Length:  2,
+		Signals: []types.Signal{
+			{Name: "Cell1", Start: 0, Length: 16, ByteOrder: "little_endian", Factor: 0.001,
+				Minimum: &minV, Maximum: &maxV},
+		},
+	}
+	data := []byte{0x70, 0x17} // 6000 raw -> 6.0 V
+
+	var inserted []types.Cell_Data
+	var nulls [][]string
+	insertCellData = func(_ context.Context, d types.Cell_Data, null ...string) error {
+		inserted = append(inserted, d)
+		nulls = append(nulls, null)
+		return nil
+	}
+	defer func() { insertCellData = db.InsertCellData }()
+	defer candecoder.SetRangePolicy(candecoder.RangeFlag)
+	broadcasts := capturedPayloads(t)
+
+	for _, tc := range []struct {
+		policy    candecoder.RangePolicy
+		want      float64
+		nulls     []string
+		broadcast *structpb.Value
+	}{
+		{candecoder.RangeFlag, 6, nil, structpb.NewStringValue("6.000")},
+		{candecoder.RangeClamp, 5, nil, structpb.NewStringValue("5.000")},
+		{candecoder.RangeNull, 0, []string{"Cell1"}, structpb.NewNullValue()},
+	} {
+		candecoder.SetRangePolicy(tc.policy)
+		frame, err := candecoder.DecodeFrame(data, msg)
+		if err != nil {
+			t.Fatalf("DecodeFrame: %v", err)
+		}
+		inserted, nulls, *broadcasts = nil, nil, nil
+		buffers := make(map[float64]*types.Cell_Data)
+		processCellData(50, frame, buffers)
+		processCellData(57, &candecoder.DecodedFrame{ID: 57}, buffers)
+		if len(inserted) != 1 || len(*broadcasts) != 1 {
+			t.Fatalf("%v: inserted %d rows and broadcast %d, want 1", tc.policy, len(inserted), len(*broadcasts))
+		}
+		if inserted[0].Cell1 != tc.want || !reflect.DeepEqual(nulls[0], tc.nulls) {
+			t.Errorf("%v: inserted Cell1 = %v with NULL %v, want %v with NULL %v", tc.policy, inserted[0].Cell1, nulls[0], tc.want, tc.nulls)
+		}
+		p := (*broadcasts)[0]
+		if got := p.Fields["cell1"]; !protobuf.Equal(got, tc.broadcast) {
+			t.Errorf("%v: broadcast cell1 = %v, want %v", tc.policy, got, tc.broadcast)
+		}
+		if got := outOfRange(p); !reflect.DeepEqual(got, []string{"cell1"}) {
+			t.Errorf("%v: out_of_range = %v, want [cell1]", tc.policy, got)
+		}
+	}
+}
+
+// TestBroadcastFrameMarksSignals checks that a flagged value reaches the
+// broadcast listed as out of range and an invalid one is sent as null, for
+// a table whose column is not named after its signal.
+func TestBroadcastFrameMarksSignals(t *testing.T) {
+	broadcasts := capturedPayloads(t)
+	frame := &candecoder.DecodedFrame{ID: 5, Signals: []candecoder.SignalValue{
+		{Name: "PackVoltage", Float: 6000, Valid: true, OutOfRange: true},
+	}}
+	payload := func() map[string]interface{} {
+		return map[string]interface{}{"type": "pack_voltage", "payload": map[string]interface{}{"timestamp": 1, "voltage": 6000.0}}
+	}
+	broadcastFrame(frame, payload())
+	frame.Signals[0].Valid = false
+	broadcastFrame(frame, payload())
+	if len(*broadcasts) != 2 {
+		t.Fatalf("broadcast %d payloads, want 2", len(*broadcasts))
+	}
+	flagged, null := (*broadcasts)[0], (*broadcasts)[1]
+	if v := flagged.Fields["voltage"].GetNumberValue(); v != 6000 || !reflect.DeepEqual(outOfRange(flagged), []string{"voltage"}) {
+		t.Errorf("flagged payload = %v", flagged)
+	}
+	if _, isNull := null.Fields["voltage"].GetKind().(*structpb.Value_NullValue); !isNull {
+		t.Errorf("invalid voltage broadcast as %v", null.Fields["voltage"])
+	}
+	if got := nullColumns(frame); !reflect.DeepEqual(got, []string{"voltage"}) {
+		t.Errorf("nullColumns = %v, want [voltage]", got)
+	}
+}
+
+// TestCellDataConcurrentStreams feeds two streams from separate goroutines,
+// one aggregator each, and checks that no row mixes cells of both.
+func TestCellDataConcurrentStreams(t *testing.T) {
+	var mu sync.Mutex
+	rows := make(map[float64]int)
+	insertCellData = func(_ context.Context, d types.Cell_Data, _ ...string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if d.Cell1 != d.Cell128 {
+			t.Errorf("row mixes streams: Cell1 = %v, Cell128 = %v", d.Cell1, d.Cell128)
+		}
+		rows[d.Cell1]++
+		return nil
+	}
+	defer func() { insertCellData = db.InsertCellData }()
+
+	const n = 200
+	var wg sync.WaitGroup
+	for _, v := range []float64{3.1, 3.9} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buffers := make(map[float64]*types.Cell_Data)
+			first := &candecoder.DecodedFrame{Signals: []candecoder.SignalValue{{Name: "Cell1", Float: v, Valid: true}}}
+			last := &candecoder.DecodedFrame{Signals: []candecoder.SignalValue{{Name: "Cell128", Float: v, Valid: true}}}
+			for i := 0; i < n; i++ {
+				processCellData(50, first, buffers)
+				processCellData(57, last, buffers)
+			}
+		}()
+	}
+	wg.Wait()
+	if rows[3.1] != n || rows[3.9] != n {
+		t.Errorf("rows = %v, want %d per stream", rows, n)
+	}
+}
+
+// TestHandledFrameIDs checks that every routed frame ID is defined for the
+// car, as dbc-lint does.
+func TestHandledFrameIDs(t *testing.T) {
+	_, messageMap, err := candecoder.LoadDefinitions("../../configs/UCR-01.dbc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := HandledFrameIDs()
+	if !sort.SliceIsSorted(ids, func(i, j int) bool { return ids[i] < ids[j] }) {
+		t.Errorf("HandledFrameIDs not sorted: %v", ids)
+	}
+	found := make(map[types.CANID]bool, len(ids))
+	for _, id := range ids {
+		found[id] = true
+		if _, ok := messageMap[id]; !ok {
+			t.Errorf("frame ID %v is handled but not defined", id)
+		}
+	}
+	for _, id := range []types.CANID{4, 50, 57, 513, 1680} {
+		if !found[id] {
+			t.Errorf("frame ID %v missing from %v", id, ids)
+		}
+	}
+}
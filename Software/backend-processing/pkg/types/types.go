@@ -1,7 +1,9 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +24,101 @@ type Signal struct {
 	Maximum   *float64          `json:"maximum"`
 	Unit      string            `json:"unit"`
 	Choices   map[string]string `json:"choices"`
+	Comment   string            `json:"comment,omitempty"`
+
+	// Multiplexing. IsMultiplexer marks a selector (switch) signal. A signal
+	// with MultiplexerIDs is only present when MultiplexerSignal holds one of
+	// those raw values; with extended multiplexing the selector may itself be
+	// multiplexed.
+	IsMultiplexer     bool      `json:"is_multiplexer,omitempty"`
+	MultiplexerIDs    MuxRanges `json:"multiplexer_ids,omitempty"`
+	MultiplexerSignal string    `json:"multiplexer_signal,omitempty"`
+}
+
+// MuxRange is an inclusive range of multiplexer selector values.
+type MuxRange struct {
+	Lo, Hi uint64
+}
+
+// MuxRanges is a set of selector values kept as sorted, disjoint ranges, so
+// a DBC range such as 0-4294967295 costs one entry. In JSON a single value is
+// a number and a range a [lo, hi] pair; cantools writes plain value lists.
+type MuxRanges []MuxRange
+
+// NewMuxRanges returns the ranges sorted with overlapping and adjacent ones
+// merged.
+func NewMuxRanges(ranges ...MuxRange) MuxRanges {
+	out := append(MuxRanges(nil), ranges...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Lo < out[j].Lo })
+	merged := out[:0]
+	for _, r := range out {
+		if n := len(merged); n > 0 && (r.Lo <= merged[n-1].Hi || r.Lo-1 == merged[n-1].Hi) {
+			if r.Hi > merged[n-1].Hi {
+				merged[n-1].Hi = r.Hi
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Contains reports whether v is one of the selector values.
+func (m MuxRanges) Contains(v uint64) bool {
+	for _, r := range m {
+		if v >= r.Lo && v <= r.Hi {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlaps reports whether a selector value is in both m and o.
+func (m MuxRanges) Overlaps(o MuxRanges) bool {
+	for _, a := range m {
+		for _, b := range o {
+			if a.Lo <= b.Hi && b.Lo <= a.Hi {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MarshalJSON writes single values as numbers and ranges as [lo, hi] pairs.
+func (m MuxRanges) MarshalJSON() ([]byte, error) {
+	items := make([]interface{}, len(m))
+	for i, r := range m {
+		if r.Lo == r.Hi {
+			items[i] = r.Lo
+		} else {
+			items[i] = [2]uint64{r.Lo, r.Hi}
+		}
+	}
+	return json.Marshal(items)
+}
+
+// UnmarshalJSON reads the form written by MarshalJSON.
+func (m *MuxRanges) UnmarshalJSON(b []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+	ranges := make([]MuxRange, len(items))
+	for i, item := range items {
+		var v uint64
+		if err := json.Unmarshal(item, &v); err == nil {
+			ranges[i] = MuxRange{v, v}
+			continue
+		}
+		var pair [2]uint64
+		if err := json.Unmarshal(item, &pair); err != nil || pair[0] > pair[1] {
+			return fmt.Errorf("invalid multiplexer value %s", item)
+		}
+		ranges[i] = MuxRange{pair[0], pair[1]}
+	}
+	*m = NewMuxRanges(ranges...)
+	return nil
 }
 
 type RearStrainGauges2_Data struct {
@@ -44,15 +141,94 @@ type RearStrainGauges1_Data struct {
 	Gauge6    int       `json:"gauge6"`
 }
 
+// CANID identifies a CAN frame by its identifier and IDE flag. It follows the
+// DBC convention: bit 31 is set for 29-bit extended frames, so an 11-bit and a
+// 29-bit ID with the same numeric value are distinct keys.
+type CANID uint32
+
+// ExtendedIDFlag marks an extended (29-bit) identifier in a CANID.
+const ExtendedIDFlag CANID = 0x80000000
+
+// NewCANID builds the CANID of a frame.
+func NewCANID(id uint32, extended bool) CANID {
+	c := CANID(id) &^ ExtendedIDFlag
+	if extended {
+		c |= ExtendedIDFlag
+	}
+	return c
+}
+
+// ID returns the numeric identifier without the IDE flag.
+func (c CANID) ID() uint32 { return uint32(c &^ ExtendedIDFlag) }
+
+// Extended reports whether the frame uses a 29-bit identifier.
+func (c CANID) Extended() bool { return c&ExtendedIDFlag != 0 }
+
+// String formats the ID in hex, with an "x" suffix for extended frames.
+func (c CANID) String() string {
+	if c.Extended() {
+		return fmt.Sprintf("0x%08Xx", c.ID())
+	}
+	return fmt.Sprintf("0x%03X", c.ID())
+}
+
 // Message represents a CAN message.
 type Message struct {
 	FrameID         uint32   `json:"frame_id"`
 	Name            string   `json:"name"`
 	IsExtendedFrame bool     `json:"is_extended_frame"`
 	Length          int      `json:"length"`
+	IsFD            bool     `json:"is_fd,omitempty"`
+	CycleTime       int      `json:"cycle_time,omitempty"` // in milliseconds
+	Comment         string   `json:"comment,omitempty"`
 	Signals         []Signal `json:"signals"`
 }
 
+// Key returns the CANID under which the message is looked up.
+func (m Message) Key() CANID {
+	return NewCANID(m.FrameID, m.IsExtendedFrame)
+}
+
+// DefinitionVersion is one stored revision of a vehicle's CAN definitions,
+// identified by the SHA-256 of its file content and valid from EffectiveFrom
+// until EffectiveTo.
+type DefinitionVersion struct {
+	ID            int64      `json:"id"`
+	Vehicle       string     `json:"vehicle"`
+	Hash          string     `json:"hash"`
+	Format        string     `json:"format"` // "dbc" or "json"
+	Content       []byte     `json:"-"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"` // nil while in use
+}
+
+// Session is one telemetry connection and the definitions it was decoded with.
+type Session struct {
+	ID              int64      `json:"id"`
+	Vehicle         string     `json:"vehicle"`
+	Source          string     `json:"source"` // "csv" or "live"
+	DefinitionsHash string     `json:"definitions_hash"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	SessionMetadata
+}
+
+// SessionMetadata describes a session for exports; it is entered after the
+// session has been recorded.
+type SessionMetadata struct {
+	Driver  string `json:"driver"`
+	Venue   string `json:"venue"`
+	Event   string `json:"event"`
+	Comment string `json:"comment"`
+}
+
+// MessageRows holds the stored signals of one CAN message over a time range.
+type MessageRows struct {
+	Columns []string
+	Times   []time.Time
+	Values  [][]float64 // one row per time; NaN where a column is NULL
+}
+
 // TCU_Data represents the TCU telemetry data.
 type TCU_Data struct {
 	Timestamp time.Time `json:"timestamp"`
@@ -121,6 +297,7 @@ type PDM1_Data struct {
 	TotalCurrent        int       `json:"total_current"`
 	InternalRailVoltage float64   `json:"internal_rail_voltage"`
 	ResetSource         int       `json:"reset_source"`
+	ResetSourceLabel    string    `json:"reset_source_label"`
 }
 
 type RearFrequency_Data struct {
@@ -138,14 +315,15 @@ type ACULV_FD_2_Data struct {
 }
 
 type GPSBestPos_Data struct {
-	Timestamp    time.Time `json:"timestamp"`
-	Latitude     float64   `json:"latitude"`
-	Longitude    float64   `json:"longitude"`
-	Altitude     float64   `json:"altitude"`
-	StdLatitude  float64   `json:"std_latitude"`
-	StdLongitude float64   `json:"std_longitude"`
-	StdAltitude  float64   `json:"std_altitude"`
-	GPSStatus    int       `json:"gps_status"`
+	Timestamp      time.Time `json:"timestamp"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	Altitude       float64   `json:"altitude"`
+	StdLatitude    float64   `json:"std_latitude"`
+	StdLongitude   float64   `json:"std_longitude"`
+	StdAltitude    float64   `json:"std_altitude"`
+	GPSStatus      int       `json:"gps_status"`
+	GPSStatusLabel string    `json:"gps_status_label"`
 }
 
 type Therm_Data struct {
@@ -171,134 +349,138 @@ type Therm_Data struct {
 
 type Cell_Data struct {
 	Timestamp time.Time `json:"timestamp"`
-	Cell1     float64   `json:"cell1"`
-	Cell2     float64   `json:"cell2"`
-	Cell3     float64   `json:"cell3"`
-	Cell4     float64   `json:"cell4"`
-	Cell5     float64   `json:"cell5"`
-	Cell6     float64   `json:"cell6"`
-	Cell7     float64   `json:"cell7"`
-	Cell8     float64   `json:"cell8"`
-	Cell9     float64   `json:"cell9"`
-	Cell10    float64   `json:"cell10"`
-	Cell11    float64   `json:"cell11"`
-	Cell12    float64   `json:"cell12"`
-	Cell13    float64   `json:"cell13"`
-	Cell14    float64   `json:"cell14"`
-	Cell15    float64   `json:"cell15"`
-	Cell16    float64   `json:"cell16"`
-	Cell17    float64   `json:"cell17"`
-	Cell18    float64   `json:"cell18"`
-	Cell19    float64   `json:"cell19"`
-	Cell20    float64   `json:"cell20"`
-	Cell21    float64   `json:"cell21"`
-	Cell22    float64   `json:"cell22"`
-	Cell23    float64   `json:"cell23"`
-	Cell24    float64   `json:"cell24"`
-	Cell25    float64   `json:"cell25"`
-	Cell26    float64   `json:"cell26"`
-	Cell27    float64   `json:"cell27"`
-	Cell28    float64   `json:"cell28"`
-	Cell29    float64   `json:"cell29"`
-	Cell30    float64   `json:"cell30"`
-	Cell31    float64   `json:"cell31"`
-	Cell32    float64   `json:"cell32"`
-	Cell33    float64   `json:"cell33"`
-	Cell34    float64   `json:"cell34"`
-	Cell35    float64   `json:"cell35"`
-	Cell36    float64   `json:"cell36"`
-	Cell37    float64   `json:"cell37"`
-	Cell38    float64   `json:"cell38"`
-	Cell39    float64   `json:"cell39"`
-	Cell40    float64   `json:"cell40"`
-	Cell41    float64   `json:"cell41"`
-	Cell42    float64   `json:"cell42"`
-	Cell43    float64   `json:"cell43"`
-	Cell44    float64   `json:"cell44"`
-	Cell45    float64   `json:"cell45"`
-	Cell46    float64   `json:"cell46"`
-	Cell47    float64   `json:"cell47"`
-	Cell48    float64   `json:"cell48"`
-	Cell49    float64   `json:"cell49"`
-	Cell50    float64   `json:"cell50"`
-	Cell51    float64   `json:"cell51"`
-	Cell52    float64   `json:"cell52"`
-	Cell53    float64   `json:"cell53"`
-	Cell54    float64   `json:"cell54"`
-	Cell55    float64   `json:"cell55"`
-	Cell56    float64   `json:"cell56"`
-	Cell57    float64   `json:"cell57"`
-	Cell58    float64   `json:"cell58"`
-	Cell59    float64   `json:"cell59"`
-	Cell60    float64   `json:"cell60"`
-	Cell61    float64   `json:"cell61"`
-	Cell62    float64   `json:"cell62"`
-	Cell63    float64   `json:"cell63"`
-	Cell64    float64   `json:"cell64"`
-	Cell65    float64   `json:"cell65"`
-	Cell66    float64   `json:"cell66"`
-	Cell67    float64   `json:"cell67"`
-	Cell68    float64   `json:"cell68"`
-	Cell69    float64   `json:"cell69"`
-	Cell70    float64   `json:"cell70"`
-	Cell71    float64   `json:"cell71"`
-	Cell72    float64   `json:"cell72"`
-	Cell73    float64   `json:"cell73"`
-	Cell74    float64   `json:"cell74"`
-	Cell75    float64   `json:"cell75"`
-	Cell76    float64   `json:"cell76"`
-	Cell77    float64   `json:"cell77"`
-	Cell78    float64   `json:"cell78"`
-	Cell79    float64   `json:"cell79"`
-	Cell80    float64   `json:"cell80"`
-	Cell81    float64   `json:"cell81"`
-	Cell82    float64   `json:"cell82"`
-	Cell83    float64   `json:"cell83"`
-	Cell84    float64   `json:"cell84"`
-	Cell85    float64   `json:"cell85"`
-	Cell86    float64   `json:"cell86"`
-	Cell87    float64   `json:"cell87"`
-	Cell88    float64   `json:"cell88"`
-	Cell89    float64   `json:"cell89"`
-	Cell90    float64   `json:"cell90"`
-	Cell91    float64   `json:"cell91"`
-	Cell92    float64   `json:"cell92"`
-	Cell93    float64   `json:"cell93"`
-	Cell94    float64   `json:"cell94"`
-	Cell95    float64   `json:"cell95"`
-	Cell96    float64   `json:"cell96"`
-	Cell97    float64   `json:"cell97"`
-	Cell98    float64   `json:"cell98"`
-	Cell99    float64   `json:"cell99"`
-	Cell100   float64   `json:"cell100"`
-	Cell101   float64   `json:"cell101"`
-	Cell102   float64   `json:"cell102"`
-	Cell103   float64   `json:"cell103"`
-	Cell104   float64   `json:"cell104"`
-	Cell105   float64   `json:"cell105"`
-	Cell106   float64   `json:"cell106"`
-	Cell107   float64   `json:"cell107"`
-	Cell108   float64   `json:"cell108"`
-	Cell109   float64   `json:"cell109"`
-	Cell110   float64   `json:"cell110"`
-	Cell111   float64   `json:"cell111"`
-	Cell112   float64   `json:"cell112"`
-	Cell113   float64   `json:"cell113"`
-	Cell114   float64   `json:"cell114"`
-	Cell115   float64   `json:"cell115"`
-	Cell116   float64   `json:"cell116"`
-	Cell117   float64   `json:"cell117"`
-	Cell118   float64   `json:"cell118"`
-	Cell119   float64   `json:"cell119"`
-	Cell120   float64   `json:"cell120"`
-	Cell121   float64   `json:"cell121"`
-	Cell122   float64   `json:"cell122"`
-	Cell123   float64   `json:"cell123"`
-	Cell124   float64   `json:"cell124"`
-	Cell125   float64   `json:"cell125"`
-	Cell126   float64   `json:"cell126"`
-	Cell127   float64   `json:"cell127"`
-	Cell128   float64   `json:"cell128"`
+	// Nulls and OutOfRange name the cells that were invalid or outside
+	// their limits in the scan; invalid cells are stored as NULL.
+	Nulls      []string `json:"-"`
+	OutOfRange []string `json:"-"`
+	Cell1      float64  `json:"cell1"`
+	Cell2      float64  `json:"cell2"`
+	Cell3      float64  `json:"cell3"`
+	Cell4      float64  `json:"cell4"`
+	Cell5      float64  `json:"cell5"`
+	Cell6      float64  `json:"cell6"`
+	Cell7      float64  `json:"cell7"`
+	Cell8      float64  `json:"cell8"`
+	Cell9      float64  `json:"cell9"`
+	Cell10     float64  `json:"cell10"`
+	Cell11     float64  `json:"cell11"`
+	Cell12     float64  `json:"cell12"`
+	Cell13     float64  `json:"cell13"`
+	Cell14     float64  `json:"cell14"`
+	Cell15     float64  `json:"cell15"`
+	Cell16     float64  `json:"cell16"`
+	Cell17     float64  `json:"cell17"`
+	Cell18     float64  `json:"cell18"`
+	Cell19     float64  `json:"cell19"`
+	Cell20     float64  `json:"cell20"`
+	Cell21     float64  `json:"cell21"`
+	Cell22     float64  `json:"cell22"`
+	Cell23     float64  `json:"cell23"`
+	Cell24     float64  `json:"cell24"`
+	Cell25     float64  `json:"cell25"`
+	Cell26     float64  `json:"cell26"`
+	Cell27     float64  `json:"cell27"`
+	Cell28     float64  `json:"cell28"`
+	Cell29     float64  `json:"cell29"`
+	Cell30     float64  `json:"cell30"`
+	Cell31     float64  `json:"cell31"`
+	Cell32     float64  `json:"cell32"`
+	Cell33     float64  `json:"cell33"`
+	Cell34     float64  `json:"cell34"`
+	Cell35     float64  `json:"cell35"`
+	Cell36     float64  `json:"cell36"`
+	Cell37     float64  `json:"cell37"`
+	Cell38     float64  `json:"cell38"`
+	Cell39     float64  `json:"cell39"`
+	Cell40     float64  `json:"cell40"`
+	Cell41     float64  `json:"cell41"`
+	Cell42     float64  `json:"cell42"`
+	Cell43     float64  `json:"cell43"`
+	Cell44     float64  `json:"cell44"`
+	Cell45     float64  `json:"cell45"`
+	Cell46     float64  `json:"cell46"`
+	Cell47     float64  `json:"cell47"`
+	Cell48     float64  `json:"cell48"`
+	Cell49     float64  `json:"cell49"`
+	Cell50     float64  `json:"cell50"`
+	Cell51     float64  `json:"cell51"`
+	Cell52     float64  `json:"cell52"`
+	Cell53     float64  `json:"cell53"`
+	Cell54     float64  `json:"cell54"`
+	Cell55     float64  `json:"cell55"`
+	Cell56     float64  `json:"cell56"`
+	Cell57     float64  `json:"cell57"`
+	Cell58     float64  `json:"cell58"`
+	Cell59     float64  `json:"cell59"`
+	Cell60     float64  `json:"cell60"`
+	Cell61     float64  `json:"cell61"`
+	Cell62     float64  `json:"cell62"`
+	Cell63     float64  `json:"cell63"`
+	Cell64     float64  `json:"cell64"`
+	Cell65     float64  `json:"cell65"`
+	Cell66     float64  `json:"cell66"`
+	Cell67     float64  `json:"cell67"`
+	Cell68     float64  `json:"cell68"`
+	Cell69     float64  `json:"cell69"`
+	Cell70     float64  `json:"cell70"`
+	Cell71     float64  `json:"cell71"`
+	Cell72     float64  `json:"cell72"`
+	Cell73     float64  `json:"cell73"`
+	Cell74     float64  `json:"cell74"`
+	Cell75     float64  `json:"cell75"`
+	Cell76     float64  `json:"cell76"`
+	Cell77     float64  `json:"cell77"`
+	Cell78     float64  `json:"cell78"`
+	Cell79     float64  `json:"cell79"`
+	Cell80     float64  `json:"cell80"`
+	Cell81     float64  `json:"cell81"`
+	Cell82     float64  `json:"cell82"`
+	Cell83     float64  `json:"cell83"`
+	Cell84     float64  `json:"cell84"`
+	Cell85     float64  `json:"cell85"`
+	Cell86     float64  `json:"cell86"`
+	Cell87     float64  `json:"cell87"`
+	Cell88     float64  `json:"cell88"`
+	Cell89     float64  `json:"cell89"`
+	Cell90     float64  `json:"cell90"`
+	Cell91     float64  `json:"cell91"`
+	Cell92     float64  `json:"cell92"`
+	Cell93     float64  `json:"cell93"`
+	Cell94     float64  `json:"cell94"`
+	Cell95     float64  `json:"cell95"`
+	Cell96     float64  `json:"cell96"`
+	Cell97     float64  `json:"cell97"`
+	Cell98     float64  `json:"cell98"`
+	Cell99     float64  `json:"cell99"`
+	Cell100    float64  `json:"cell100"`
+	Cell101    float64  `json:"cell101"`
+	Cell102    float64  `json:"cell102"`
+	Cell103    float64  `json:"cell103"`
+	Cell104    float64  `json:"cell104"`
+	Cell105    float64  `json:"cell105"`
+	Cell106    float64  `json:"cell106"`
+	Cell107    float64  `json:"cell107"`
+	Cell108    float64  `json:"cell108"`
+	Cell109    float64  `json:"cell109"`
+	Cell110    float64  `json:"cell110"`
+	Cell111    float64  `json:"cell111"`
+	Cell112    float64  `json:"cell112"`
+	Cell113    float64  `json:"cell113"`
+	Cell114    float64  `json:"cell114"`
+	Cell115    float64  `json:"cell115"`
+	Cell116    float64  `json:"cell116"`
+	Cell117    float64  `json:"cell117"`
+	Cell118    float64  `json:"cell118"`
+	Cell119    float64  `json:"cell119"`
+	Cell120    float64  `json:"cell120"`
+	Cell121    float64  `json:"cell121"`
+	Cell122    float64  `json:"cell122"`
+	Cell123    float64  `json:"cell123"`
+	Cell124    float64  `json:"cell124"`
+	Cell125    float64  `json:"cell125"`
+	Cell126    float64  `json:"cell126"`
+	Cell127    float64  `json:"cell127"`
+	Cell128    float64  `json:"cell128"`
 }
 
 type BamocarTxData_Data struct {
@@ -351,6 +533,7 @@ type PDMReTransmit_Data struct {
 	TotalCurrent        int       `json:"total_current"`
 	InternalRailVoltage float64   `json:"internal_rail_voltage"`
 	ResetSource         int       `json:"reset_source"`
+	ResetSourceLabel    string    `json:"reset_source_label"`
 }
 
 type INS_GPS_Data struct {
@@ -421,6 +604,7 @@ type ACULV2_Data struct {
 type ACULV_FD_1_Data struct {
 	Timestamp            time.Time `json:"timestamp"`
 	AMSStatus            int       `json:"ams_status"`
+	AMSStatusLabel       string    `json:"ams_status_label"`
 	FLD                  int       `json:"fld"`
 	StateOfCharge        float64   `json:"state_of_charge"`
 	AccumulatorVoltage   float64   `json:"accumulator_voltage"`
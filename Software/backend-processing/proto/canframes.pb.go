@@ -0,0 +1,322 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        v3.21.12
+// source: proto/canframes.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CANFrameFlag values are OR-ed into CANFrame.flags.
+type CANFrameFlag int32
+
+const (
+	CANFrameFlag_CAN_FRAME_FLAG_NONE CANFrameFlag = 0
+	// 29-bit identifier.
+	CANFrameFlag_CAN_FRAME_FLAG_EXTENDED CANFrameFlag = 1
+	// CAN FD frame; dlc codes 9-15 mean 12 to 64 data bytes.
+	CANFrameFlag_CAN_FRAME_FLAG_FD CANFrameFlag = 2
+	// CAN FD frame sent with bit rate switch.
+	CANFrameFlag_CAN_FRAME_FLAG_BRS CANFrameFlag = 4
+	// Remote request without data.
+	CANFrameFlag_CAN_FRAME_FLAG_REMOTE CANFrameFlag = 8
+	// Bus error frame.
+	CANFrameFlag_CAN_FRAME_FLAG_ERROR CANFrameFlag = 16
+)
+
+// Enum value maps for CANFrameFlag.
+var (
+	CANFrameFlag_name = map[int32]string{
+		0:  "CAN_FRAME_FLAG_NONE",
+		1:  "CAN_FRAME_FLAG_EXTENDED",
+		2:  "CAN_FRAME_FLAG_FD",
+		4:  "CAN_FRAME_FLAG_BRS",
+		8:  "CAN_FRAME_FLAG_REMOTE",
+		16: "CAN_FRAME_FLAG_ERROR",
+	}
+	CANFrameFlag_value = map[string]int32{
+		"CAN_FRAME_FLAG_NONE":     0,
+		"CAN_FRAME_FLAG_EXTENDED": 1,
+		"CAN_FRAME_FLAG_FD":       2,
+		"CAN_FRAME_FLAG_BRS":      4,
+		"CAN_FRAME_FLAG_REMOTE":   8,
+		"CAN_FRAME_FLAG_ERROR":    16,
+	}
+)
+
+func (x CANFrameFlag) Enum() *CANFrameFlag {
+	p := new(CANFrameFlag)
+	*p = x
+	return p
+}
+
+func (x CANFrameFlag) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CANFrameFlag) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_canframes_proto_enumTypes[0].Descriptor()
+}
+
+func (CANFrameFlag) Type() protoreflect.EnumType {
+	return &file_proto_canframes_proto_enumTypes[0]
+}
+
+func (x CANFrameFlag) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CANFrameFlag.Descriptor instead.
+func (CANFrameFlag) EnumDescriptor() ([]byte, []int) {
+	return file_proto_canframes_proto_rawDescGZIP(), []int{0}
+}
+
+// CANFrameBatch carries many raw CAN frames in one WebSocket message of the
+// "telem.canframes.v1" subprotocol on /telemetry.
+type CANFrameBatch struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Capture time of the batch on the sender's clock, in microseconds since
+	// the Unix epoch. Senders without a synchronised clock leave it 0 and the
+	// frames are timed on arrival.
+	SenderTimeUs int64 `protobuf:"varint,1,opt,name=sender_time_us,json=senderTimeUs,proto3" json:"sender_time_us,omitempty"`
+	// Counts batches per connection, so the receiver can report gaps.
+	Sequence      uint64      `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Frames        []*CANFrame `protobuf:"bytes,3,rep,name=frames,proto3" json:"frames,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CANFrameBatch) Reset() {
+	*x = CANFrameBatch{}
+	mi := &file_proto_canframes_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CANFrameBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CANFrameBatch) ProtoMessage() {}
+
+func (x *CANFrameBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_canframes_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CANFrameBatch.ProtoReflect.Descriptor instead.
+func (*CANFrameBatch) Descriptor() ([]byte, []int) {
+	return file_proto_canframes_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CANFrameBatch) GetSenderTimeUs() int64 {
+	if x != nil {
+		return x.SenderTimeUs
+	}
+	return 0
+}
+
+func (x *CANFrameBatch) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *CANFrameBatch) GetFrames() []*CANFrame {
+	if x != nil {
+		return x.Frames
+	}
+	return nil
+}
+
+// CANFrame is one frame of a batch.
+type CANFrame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 11-bit or, with CAN_FRAME_FLAG_EXTENDED, 29-bit identifier.
+	Id uint32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Bitwise OR of CANFrameFlag values.
+	Flags uint32 `protobuf:"varint,2,opt,name=flags,proto3" json:"flags,omitempty"`
+	// DLC code as sent on the bus; data holds the bytes it announces.
+	Dlc  uint32 `protobuf:"varint,3,opt,name=dlc,proto3" json:"dlc,omitempty"`
+	Data []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	// Capture time in microseconds after the batch's sender_time_us.
+	TimeOffsetUs  uint32 `protobuf:"varint,5,opt,name=time_offset_us,json=timeOffsetUs,proto3" json:"time_offset_us,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CANFrame) Reset() {
+	*x = CANFrame{}
+	mi := &file_proto_canframes_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CANFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CANFrame) ProtoMessage() {}
+
+func (x *CANFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_canframes_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CANFrame.ProtoReflect.Descriptor instead.
+func (*CANFrame) Descriptor() ([]byte, []int) {
+	return file_proto_canframes_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CANFrame) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *CANFrame) GetFlags() uint32 {
+	if x != nil {
+		return x.Flags
+	}
+	return 0
+}
+
+func (x *CANFrame) GetDlc() uint32 {
+	if x != nil {
+		return x.Dlc
+	}
+	return 0
+}
+
+func (x *CANFrame) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CANFrame) GetTimeOffsetUs() uint32 {
+	if x != nil {
+		return x.TimeOffsetUs
+	}
+	return 0
+}
+
+var File_proto_canframes_proto protoreflect.FileDescriptor
+
+var file_proto_canframes_proto_rawDesc = string([]byte{
+	0x0a, 0x15, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x61, 0x6e, 0x66, 0x72, 0x61, 0x6d, 0x65,
+	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74,
+	0x72, 0x79, 0x22, 0x7e, 0x0a, 0x0d, 0x43, 0x41, 0x4e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x12, 0x24, 0x0a, 0x0e, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x5f, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x73, 0x65, 0x6e,
+	0x64, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x55, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71,
+	0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71,
+	0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x2b, 0x0a, 0x06, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72,
+	0x79, 0x2e, 0x43, 0x41, 0x4e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x52, 0x06, 0x66, 0x72, 0x61, 0x6d,
+	0x65, 0x73, 0x22, 0x7c, 0x0a, 0x08, 0x43, 0x41, 0x4e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x66,
+	0x6c, 0x61, 0x67, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x6c, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x03, 0x64, 0x6c, 0x63, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x24, 0x0a, 0x0e, 0x74, 0x69,
+	0x6d, 0x65, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x5f, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x55, 0x73,
+	0x2a, 0xa8, 0x01, 0x0a, 0x0c, 0x43, 0x41, 0x4e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x46, 0x6c, 0x61,
+	0x67, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x41, 0x4e, 0x5f, 0x46, 0x52, 0x41, 0x4d, 0x45, 0x5f, 0x46,
+	0x4c, 0x41, 0x47, 0x5f, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x1b, 0x0a, 0x17, 0x43, 0x41,
+	0x4e, 0x5f, 0x46, 0x52, 0x41, 0x4d, 0x45, 0x5f, 0x46, 0x4c, 0x41, 0x47, 0x5f, 0x45, 0x58, 0x54,
+	0x45, 0x4e, 0x44, 0x45, 0x44, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x41, 0x4e, 0x5f, 0x46,
+	0x52, 0x41, 0x4d, 0x45, 0x5f, 0x46, 0x4c, 0x41, 0x47, 0x5f, 0x46, 0x44, 0x10, 0x02, 0x12, 0x16,
+	0x0a, 0x12, 0x43, 0x41, 0x4e, 0x5f, 0x46, 0x52, 0x41, 0x4d, 0x45, 0x5f, 0x46, 0x4c, 0x41, 0x47,
+	0x5f, 0x42, 0x52, 0x53, 0x10, 0x04, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x41, 0x4e, 0x5f, 0x46, 0x52,
+	0x41, 0x4d, 0x45, 0x5f, 0x46, 0x4c, 0x41, 0x47, 0x5f, 0x52, 0x45, 0x4d, 0x4f, 0x54, 0x45, 0x10,
+	0x08, 0x12, 0x18, 0x0a, 0x14, 0x43, 0x41, 0x4e, 0x5f, 0x46, 0x52, 0x41, 0x4d, 0x45, 0x5f, 0x46,
+	0x4c, 0x41, 0x47, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x10, 0x42, 0x14, 0x5a, 0x12, 0x74,
+	0x65, 0x6c, 0x65, 0x6d, 0x2d, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_proto_canframes_proto_rawDescOnce sync.Once
+	file_proto_canframes_proto_rawDescData []byte
+)
+
+func file_proto_canframes_proto_rawDescGZIP() []byte {
+	file_proto_canframes_proto_rawDescOnce.Do(func() {
+		file_proto_canframes_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_canframes_proto_rawDesc), len(file_proto_canframes_proto_rawDesc)))
+	})
+	return file_proto_canframes_proto_rawDescData
+}
+
+var file_proto_canframes_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_canframes_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_canframes_proto_goTypes = []any{
+	(CANFrameFlag)(0),     // 0: telemetry.CANFrameFlag
+	(*CANFrameBatch)(nil), // 1: telemetry.CANFrameBatch
+	(*CANFrame)(nil),      // 2: telemetry.CANFrame
+}
+var file_proto_canframes_proto_depIdxs = []int32{
+	2, // 0: telemetry.CANFrameBatch.frames:type_name -> telemetry.CANFrame
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proto_canframes_proto_init() }
+func file_proto_canframes_proto_init() {
+	if File_proto_canframes_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_canframes_proto_rawDesc), len(file_proto_canframes_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_canframes_proto_goTypes,
+		DependencyIndexes: file_proto_canframes_proto_depIdxs,
+		EnumInfos:         file_proto_canframes_proto_enumTypes,
+		MessageInfos:      file_proto_canframes_proto_msgTypes,
+	}.Build()
+	File_proto_canframes_proto = out.File
+	file_proto_canframes_proto_goTypes = nil
+	file_proto_canframes_proto_depIdxs = nil
+}
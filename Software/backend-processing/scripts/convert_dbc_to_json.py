@@ -23,6 +23,7 @@ def main():
             'frame_id': message.frame_id,
             'is_extended_frame': message.is_extended_frame,
             'length': message.length,
+            'is_fd': message.is_fd,
             'signals': []
         }
         for signal in message.signals:
@@ -40,6 +41,11 @@ def main():
                 'unit': signal.unit if signal.unit else "",
                 'choices': {}
             }
+            if signal.is_multiplexer:
+                signal_dict['is_multiplexer'] = True
+            if signal.multiplexer_ids:
+                signal_dict['multiplexer_ids'] = list(signal.multiplexer_ids)
+                signal_dict['multiplexer_signal'] = signal.multiplexer_signal
             if signal.choices:
                 # Convert choices to {name: value}
                 for val, choice_name in signal.choices.items():